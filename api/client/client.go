@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package client provides a supported Go client for the Teleport auth API.
+//
+// It is a thin facade over the auth server client intended for external
+// automation: resource CRUD, event watchers and access request operations
+// are available without depending on the rest of the teleport libraries
+// directly. Credentials are loaded from identity files generated with
+// "tctl auth sign" or "tsh login -o", or from an existing tsh profile:
+//
+//	creds, err := client.LoadIdentityFile("identity.pem")
+//	if err != nil { ... }
+//	clt, err := client.New(client.Config{
+//		Addrs:       []string{"auth.example.com:3025"},
+//		Credentials: creds,
+//	})
+package client
+
+import (
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// Config holds configuration for connecting to the Teleport auth API.
+type Config struct {
+	// Addrs is a list of auth server addresses to connect to.
+	Addrs []string
+	// Credentials authenticate the client to the auth server, see
+	// LoadIdentityFile and LoadProfile.
+	Credentials Credentials
+}
+
+// CheckAndSetDefaults checks and sets default config values.
+func (c *Config) CheckAndSetDefaults() error {
+	if len(c.Addrs) == 0 {
+		return trace.BadParameter("missing parameter Addrs")
+	}
+	if c.Credentials == nil {
+		return trace.BadParameter("missing parameter Credentials")
+	}
+	return nil
+}
+
+// Client is an authenticated client of the Teleport auth API.
+//
+// It embeds the auth server client and therefore exposes the full set of
+// resource CRUD methods, NewWatcher for event subscriptions and the access
+// request operations (CreateAccessRequest, SetAccessRequestState and
+// friends).
+type Client struct {
+	*auth.Client
+}
+
+// New returns a new auth API client and verifies connectivity by fetching
+// the name of the connected cluster.
+func New(cfg Config) (*Client, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	tlsConfig, err := cfg.Credentials.TLSConfig()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	addrs, err := utils.ParseAddrs(cfg.Addrs)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	clt, err := auth.NewTLSClient(auth.ClientConfig{Addrs: addrs, TLS: tlsConfig})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// check connectivity by calling a read-only endpoint
+	if _, err := clt.GetClusterName(); err != nil {
+		return nil, trace.Wrap(err, "failed to connect to auth server %v", cfg.Addrs)
+	}
+	return &Client{Client: clt}, nil
+}
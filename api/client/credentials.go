@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/tls"
+	"os"
+
+	"github.com/gravitational/teleport/lib/auth"
+	libclient "github.com/gravitational/teleport/lib/client"
+	"github.com/gravitational/teleport/lib/client/identityfile"
+
+	"github.com/gravitational/trace"
+)
+
+// Credentials authenticate an API client to the auth server.
+type Credentials interface {
+	// TLSConfig returns client TLS configuration used to authenticate
+	// against the auth API.
+	TLSConfig() (*tls.Config, error)
+}
+
+// keyCredentials are credentials backed by a client key loaded from disk.
+type keyCredentials struct {
+	key *libclient.Key
+}
+
+// TLSConfig returns client TLS configuration built from the key.
+func (c *keyCredentials) TLSConfig() (*tls.Config, error) {
+	tlsConfig, err := c.key.ClientTLSConfig(nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return tlsConfig, nil
+}
+
+// LoadIdentityFile loads credentials from an identity file generated with
+// "tctl auth sign --format=file" or "tsh login -o".
+func LoadIdentityFile(path string) (Credentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	ident, err := identityfile.Decode(f)
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to parse identity file %v", path)
+	}
+	if len(ident.Certs.TLS) == 0 {
+		return nil, trace.BadParameter("identity file %v is missing TLS certificate", path)
+	}
+	if _, err := tls.X509KeyPair(ident.Certs.TLS, ident.PrivateKey); err != nil {
+		return nil, trace.Wrap(err, "invalid identity: %v", path)
+	}
+	var trustedCA []auth.TrustedCerts
+	if len(ident.CACerts.TLS) > 0 {
+		trustedCA = []auth.TrustedCerts{{TLSCertificates: ident.CACerts.TLS}}
+	}
+	return &keyCredentials{key: &libclient.Key{
+		Priv:      ident.PrivateKey,
+		Cert:      ident.Certs.SSH,
+		TLSCert:   ident.Certs.TLS,
+		TrustedCA: trustedCA,
+	}}, nil
+}
+
+// LoadProfile loads credentials from a tsh profile. An empty dir defaults
+// to "~/.tsh" and an empty name selects the currently active profile.
+func LoadProfile(dir string, name string) (Credentials, error) {
+	dir = libclient.FullProfilePath(dir)
+	profile, err := libclient.ProfileFromDir(dir, name)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	keystore, err := libclient.NewFSLocalKeyStore(dir)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	key, err := keystore.GetKey(profile.Name(), profile.Username)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &keyCredentials{key: key}, nil
+}
@@ -300,6 +300,14 @@ const (
 	// Names is for formatting node names in plain text
 	Names = "names"
 
+	// SSHSessionJoinModePeer joins an SSH session as a full participant
+	// whose input is forwarded to the session.
+	SSHSessionJoinModePeer = "peer"
+
+	// SSHSessionJoinModeObserver joins an SSH session as a read-only
+	// observer whose input is discarded.
+	SSHSessionJoinModeObserver = "observer"
+
 	// LinuxAdminGID is the ID of the standard adm group on linux
 	LinuxAdminGID = 4
 
@@ -352,6 +360,20 @@ const (
 	// DisableServerSideEncryption is an optional switch to opt out of SSE in case the provider does not support it
 	DisableServerSideEncryption = "disablesse"
 
+	// ArchiveAfterDays is an optional parameter that moves session recordings
+	// to a cheaper archive storage tier after the given number of days
+	ArchiveAfterDays = "archive_after_days"
+
+	// SSEKMSKey is an optional switch to use an KMS CMK key for S3 SSE.
+	SSEKMSKey = "sse_kms_key"
+
+	// S3ACL is an optional canned ACL to apply to uploaded recordings
+	S3ACL = "acl"
+
+	// UploadPartSize is an optional upload part size in bytes for multipart
+	// uploads of very long session recordings
+	UploadPartSize = "upload_part_size"
+
 	// SchemeFile is a local disk file storage
 	SchemeFile = "file"
 
@@ -424,6 +446,10 @@ const (
 	// CertExtensionTeleportActiveRequests is used to track which privilege
 	// escalation requests were used to construct the certificate.
 	CertExtensionTeleportActiveRequests = "teleport-active-requests"
+	// CertCriticalOptionSourceAddress is a critical option that restricts
+	// the certificate to clients connecting from the listed CIDR blocks,
+	// enforced by the SSH server during authentication.
+	CertCriticalOptionSourceAddress = "source-address"
 )
 
 const (
@@ -520,12 +546,35 @@ const AdminRoleName = "admin"
 // objects.
 const DefaultImplicitRole = "default-implicit-role"
 
+const (
+	// PresetAccessRoleName is the name of the preset role that grants access
+	// to cluster resources like nodes.
+	PresetAccessRoleName = "access"
+
+	// PresetEditorRoleName is the name of the preset role that allows editing
+	// cluster configuration resources.
+	PresetEditorRoleName = "editor"
+
+	// PresetAuditorRoleName is the name of the preset role that allows
+	// reviewing the audit log and session recordings.
+	PresetAuditorRoleName = "auditor"
+)
+
 // APIDomain is a default domain name for Auth server API
 const APIDomain = "teleport.cluster.local"
 
 // MinClientVersion is the minimum client version required by the server.
 const MinClientVersion = "3.0.0"
 
+const (
+	// VersionCheckModeEnforce rejects connections from clients and agents
+	// older than the configured minimum supported version.
+	VersionCheckModeEnforce = "enforce"
+	// VersionCheckModeWarn logs a warning about clients and agents older
+	// than the configured minimum supported version, but lets them connect.
+	VersionCheckModeWarn = "warn"
+)
+
 const (
 	// RemoteClusterStatusOffline indicates that cluster is considered as
 	// offline, since it has missed a series of heartbeats
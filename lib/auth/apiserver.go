@@ -113,6 +113,10 @@ func NewAPIServer(config *APIConfig) http.Handler {
 	srv.POST("/:version/users", srv.withAuth(srv.upsertUser))
 	srv.PUT("/:version/users/:user/web/password", srv.withAuth(srv.changePassword))
 	srv.POST("/:version/users/:user/web/password", srv.withAuth(srv.upsertPassword))
+	srv.POST("/:version/users/:user/recovery/codes", srv.withAuth(srv.generateRecoveryCodes))
+	srv.POST("/:version/users/:user/recovery/codes/use", srv.withRate(srv.withAuth(srv.useRecoveryCode)))
+	srv.GET("/:version/users/:user/web/preferences", srv.withAuth(srv.getUserPreferences))
+	srv.PUT("/:version/users/:user/web/preferences", srv.withAuth(srv.upsertUserPreferences))
 	srv.POST("/:version/users/:user/web/password/check", srv.withRate(srv.withAuth(srv.checkPassword)))
 	srv.POST("/:version/users/:user/web/sessions", srv.withAuth(srv.createWebSession))
 	srv.POST("/:version/users/:user/web/authenticate", srv.withAuth(srv.authenticateWebUser))
@@ -126,6 +130,7 @@ func NewAPIServer(config *APIConfig) http.Handler {
 	srv.POST("/:version/namespaces/:namespace/nodes/keepalive", srv.withAuth(srv.keepAliveNode))
 	srv.PUT("/:version/namespaces/:namespace/nodes", srv.withAuth(srv.upsertNodes))
 	srv.GET("/:version/namespaces/:namespace/nodes", srv.withAuth(srv.getNodes))
+	srv.GET("/:version/namespaces/:namespace/nodes/list", srv.withAuth(srv.listNodes))
 	srv.DELETE("/:version/namespaces/:namespace/nodes", srv.withAuth(srv.deleteAllNodes))
 	srv.DELETE("/:version/namespaces/:namespace/nodes/:name", srv.withAuth(srv.deleteNode))
 	srv.POST("/:version/authservers", srv.withAuth(srv.upsertAuthServer))
@@ -175,6 +180,9 @@ func NewAPIServer(config *APIConfig) http.Handler {
 	srv.GET("/:version/namespaces/:namespace/sessions/:id", srv.withAuth(srv.getSession))
 	srv.POST("/:version/namespaces/:namespace/sessions/:id/slice", srv.withAuth(srv.postSessionSlice))
 	srv.POST("/:version/namespaces/:namespace/sessions/:id/recording", srv.withAuth(srv.uploadSessionRecording))
+	srv.GET("/:version/namespaces/:namespace/sessions/:id/recording", srv.withAuth(srv.downloadSessionRecording))
+	srv.DELETE("/:version/namespaces/:namespace/sessions/:id/recording", srv.withAuth(srv.deleteSessionRecording))
+	srv.GET("/:version/namespaces/:namespace/sessions/:id/export", srv.withAuth(srv.exportSession))
 	srv.GET("/:version/namespaces/:namespace/sessions/:id/stream", srv.withAuth(srv.getSessionChunk))
 	srv.GET("/:version/namespaces/:namespace/sessions/:id/events", srv.withAuth(srv.getSessionEvents))
 
@@ -186,6 +194,7 @@ func NewAPIServer(config *APIConfig) http.Handler {
 
 	// Roles
 	srv.POST("/:version/roles", srv.withAuth(srv.upsertRole))
+	srv.POST("/:version/roles/compare-and-swap", srv.withAuth(srv.compareAndSwapRole))
 	srv.GET("/:version/roles", srv.withAuth(srv.getRoles))
 	srv.GET("/:version/roles/:role", srv.withAuth(srv.getRole))
 	srv.DELETE("/:version/roles/:role", srv.withAuth(srv.deleteRole))
@@ -195,6 +204,15 @@ func NewAPIServer(config *APIConfig) http.Handler {
 	srv.POST("/:version/configuration", srv.withAuth(srv.setClusterConfig))
 	srv.GET("/:version/configuration/name", srv.withAuth(srv.getClusterName))
 	srv.POST("/:version/configuration/name", srv.withAuth(srv.setClusterName))
+	srv.GET("/:version/configuration/bpf_override", srv.withAuth(srv.getBPFOverride))
+
+	// Network restrictions
+	srv.GET("/:version/restrictions/network", srv.withAuth(srv.getNetworkRestrictions))
+	srv.POST("/:version/restrictions/network", srv.withAuth(srv.setNetworkRestrictions))
+	srv.DELETE("/:version/restrictions/network", srv.withAuth(srv.deleteNetworkRestrictions))
+
+	// Unified resource listing
+	srv.GET("/:version/unifiedresources", srv.withAuth(srv.listUnifiedResources))
 	srv.GET("/:version/configuration/static_tokens", srv.withAuth(srv.getStaticTokens))
 	srv.DELETE("/:version/configuration/static_tokens", srv.withAuth(srv.deleteStaticTokens))
 	srv.POST("/:version/configuration/static_tokens", srv.withAuth(srv.setStaticTokens))
@@ -437,6 +455,39 @@ func (s *APIServer) getNodes(auth ClientI, w http.ResponseWriter, r *http.Reques
 	return marshalServers(servers, version)
 }
 
+// listNodesRawResponse is a page of marshaled nodes along with the name
+// to start the next page at.
+type listNodesRawResponse struct {
+	Servers []json.RawMessage `json:"servers"`
+	NextKey string            `json:"next_key"`
+}
+
+// listNodes returns a page of registered nodes
+func (s *APIServer) listNodes(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	namespace := p.ByName("namespace")
+	if !services.IsValidNamespace(namespace) {
+		return nil, trace.BadParameter("invalid namespace %q", namespace)
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil {
+		return nil, trace.BadParameter("invalid limit value")
+	}
+	startKey := r.URL.Query().Get("start_key")
+
+	servers, nextKey, err := auth.ListNodes(namespace, limit, startKey)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	items, err := marshalServers(servers, version)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return listNodesRawResponse{
+		Servers: items.([]json.RawMessage),
+		NextKey: nextKey,
+	}, nil
+}
+
 // deleteAllNodes deletes all nodes
 func (s *APIServer) deleteAllNodes(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
 	namespace := p.ByName("namespace")
@@ -841,6 +892,58 @@ func (s *APIServer) upsertPassword(auth ClientI, w http.ResponseWriter, r *http.
 	return message(fmt.Sprintf("password for for user %q upserted", user)), nil
 }
 
+// generateRecoveryCodes generates a new set of recovery codes for a user
+func (s *APIServer) generateRecoveryCodes(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	codes, err := auth.GenerateRecoveryCodes(r.Context(), p.ByName("user"))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return codes, nil
+}
+
+type useRecoveryCodeReq struct {
+	Code string `json:"code"`
+}
+
+// useRecoveryCode spends a recovery code and returns a reset password token
+func (s *APIServer) useRecoveryCode(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	var req *useRecoveryCodeReq
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	token, err := auth.UseRecoveryCode(r.Context(), p.ByName("user"), req.Code)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return rawMessage(services.GetResetPasswordTokenMarshaler().Marshal(token, services.WithVersion(version)))
+}
+
+func (s *APIServer) getUserPreferences(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	prefs, err := auth.GetUserPreferences(p.ByName("user"))
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return prefs, nil
+}
+
+type upsertUserPreferencesReq struct {
+	Preferences services.UserPreferences `json:"preferences"`
+}
+
+func (s *APIServer) upsertUserPreferences(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	var req *upsertUserPreferencesReq
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	user := p.ByName("user")
+	if err := auth.UpsertUserPreferences(user, req.Preferences); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return message(fmt.Sprintf("preferences for user %q upserted", user)), nil
+}
+
 type upsertUserRawReq struct {
 	User json.RawMessage `json:"user"`
 }
@@ -1990,6 +2093,59 @@ func (s *APIServer) uploadSessionRecording(auth ClientI, w http.ResponseWriter,
 	return message("ok"), nil
 }
 
+// HTTP GET /:version/namespaces/:namespace/sessions/:id/recording
+func (s *APIServer) downloadSessionRecording(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	sid, err := session.ParseID(p.ByName("id"))
+	if err != nil {
+		return nil, trace.BadParameter("missing parameter id")
+	}
+	namespace := p.ByName("namespace")
+	if !services.IsValidNamespace(namespace) {
+		return nil, trace.BadParameter("invalid namespace %q", namespace)
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := auth.DownloadSessionRecording(namespace, *sid, w); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return nil, nil
+}
+
+// HTTP DELETE /:version/namespaces/:namespace/sessions/:id/recording
+func (s *APIServer) deleteSessionRecording(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	sid, err := session.ParseID(p.ByName("id"))
+	if err != nil {
+		return nil, trace.BadParameter("missing parameter id")
+	}
+	namespace := p.ByName("namespace")
+	if !services.IsValidNamespace(namespace) {
+		return nil, trace.BadParameter("invalid namespace %q", namespace)
+	}
+	if err := auth.DeleteSessionRecording(namespace, *sid); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return message("session recording deleted"), nil
+}
+
+// HTTP GET /:version/namespaces/:namespace/sessions/:id/export
+//
+// Streams the session recording as newline-delimited JSON events with
+// print payloads inlined, for consumption by external analysis tools.
+func (s *APIServer) exportSession(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	sid, err := session.ParseID(p.ByName("id"))
+	if err != nil {
+		return nil, trace.BadParameter("missing parameter id")
+	}
+	namespace := p.ByName("namespace")
+	if !services.IsValidNamespace(namespace) {
+		return nil, trace.BadParameter("invalid namespace %q", namespace)
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if err := events.ExportSession(auth, w, namespace, *sid); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return nil, nil
+}
+
 // HTTP GET /:version/sessions/:id/stream?offset=x&bytes=y
 // Query parameters:
 //   "offset"   : bytes from the beginning
@@ -2121,6 +2277,31 @@ func (s *APIServer) upsertRole(auth ClientI, w http.ResponseWriter, r *http.Requ
 	return message(fmt.Sprintf("'%v' role upserted", role.GetName())), nil
 }
 
+type compareAndSwapRoleRawReq struct {
+	New      json.RawMessage `json:"new"`
+	Existing json.RawMessage `json:"existing"`
+}
+
+func (s *APIServer) compareAndSwapRole(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	var req *compareAndSwapRoleRawReq
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	new, err := services.GetRoleMarshaler().UnmarshalRole(req.New)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	existing, err := services.GetRoleMarshaler().UnmarshalRole(req.Existing)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = auth.CompareAndSwapRole(r.Context(), new, existing)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return message(fmt.Sprintf("'%v' role updated", new.GetName())), nil
+}
+
 func (s *APIServer) getRole(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
 	role, err := auth.GetRole(p.ByName("role"))
 	if err != nil {
@@ -2162,6 +2343,38 @@ func (s *APIServer) getClusterConfig(auth ClientI, w http.ResponseWriter, r *htt
 	return rawMessage(services.GetClusterConfigMarshaler().Marshal(cc, services.WithVersion(version), services.PreserveResourceID()))
 }
 
+// getBPFOverride returns the merged enhanced recording override for a node,
+// its labels are passed as query parameters.
+func (s *APIServer) getBPFOverride(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	labels := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		if len(values) != 0 {
+			labels[key] = values[0]
+		}
+	}
+	override, err := auth.GetBPFOverride(labels)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return override, nil
+}
+
+func (s *APIServer) listUnifiedResources(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, trace.BadParameter("invalid limit %q", v)
+		}
+	}
+	page, err := auth.ListUnifiedResources(r.URL.Query().Get("start_key"), limit)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return page, nil
+}
+
 type setClusterConfigReq struct {
 	ClusterConfig json.RawMessage `json:"cluster_config"`
 }
@@ -2187,6 +2400,51 @@ func (s *APIServer) setClusterConfig(auth ClientI, w http.ResponseWriter, r *htt
 	return message("cluster config set"), nil
 }
 
+// getNetworkRestrictions returns the cluster-wide network restrictions.
+func (s *APIServer) getNetworkRestrictions(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	nr, err := auth.GetNetworkRestrictions()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return rawMessage(services.GetNetworkRestrictionsMarshaler().Marshal(nr, services.WithVersion(version), services.PreserveResourceID()))
+}
+
+type setNetworkRestrictionsReq struct {
+	NetworkRestrictions json.RawMessage `json:"network_restrictions"`
+}
+
+// setNetworkRestrictions upserts the cluster-wide network restrictions.
+func (s *APIServer) setNetworkRestrictions(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	var req setNetworkRestrictionsReq
+
+	err := httplib.ReadJSON(r, &req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	nr, err := services.GetNetworkRestrictionsMarshaler().Unmarshal(req.NetworkRestrictions)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	err = auth.SetNetworkRestrictions(nr)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return message("network restrictions set"), nil
+}
+
+// deleteNetworkRestrictions deletes the cluster-wide network restrictions.
+func (s *APIServer) deleteNetworkRestrictions(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
+	err := auth.DeleteNetworkRestrictions()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return message("network restrictions deleted"), nil
+}
+
 func (s *APIServer) getClusterName(auth ClientI, w http.ResponseWriter, r *http.Request, p httprouter.Params, version string) (interface{}, error) {
 	cn, err := auth.GetClusterName()
 	if err != nil {
@@ -36,6 +36,7 @@ import (
 
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib/backend"
+	"github.com/gravitational/teleport/lib/bpf"
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/limiter"
@@ -50,6 +51,7 @@ import (
 
 	"github.com/coreos/go-oidc/oauth2"
 	"github.com/coreos/go-oidc/oidc"
+	"github.com/coreos/go-semver/semver"
 	"github.com/gravitational/trace"
 	"github.com/jonboulle/clockwork"
 	"github.com/prometheus/client_golang/prometheus"
@@ -84,6 +86,9 @@ func NewServer(cfg *InitConfig, opts ...ServerOption) (*Server, error) {
 	if cfg.ClusterConfiguration == nil {
 		cfg.ClusterConfiguration = local.NewClusterConfigurationService(cfg.Backend)
 	}
+	if cfg.Restrictions == nil {
+		cfg.Restrictions = local.NewRestrictionsService(cfg.Backend)
+	}
 	if cfg.Events == nil {
 		cfg.Events = local.NewEventsService(cfg.Backend)
 	}
@@ -118,6 +123,10 @@ func NewServer(cfg *InitConfig, opts ...ServerOption) (*Server, error) {
 		closeCtx:        closeCtx,
 		emitter:         cfg.Emitter,
 		streamer:        cfg.Streamer,
+		minClientVersion: cfg.MinClientVersion,
+		versionCheckMode: cfg.VersionCheckMode,
+		disallowRootLogins: cfg.DisallowRootLogins,
+		bpfOverrides:     cfg.BPFOverrides,
 		Services: Services{
 			Trust:                cfg.Trust,
 			Presence:             cfg.Presence,
@@ -126,6 +135,7 @@ func NewServer(cfg *InitConfig, opts ...ServerOption) (*Server, error) {
 			Access:               cfg.Access,
 			DynamicAccess:        cfg.DynamicAccess,
 			ClusterConfiguration: cfg.ClusterConfiguration,
+			Restrictions:         cfg.Restrictions,
 			IAuditLog:            cfg.AuditLog,
 			Events:               cfg.Events,
 		},
@@ -148,6 +158,7 @@ type Services struct {
 	services.Access
 	services.DynamicAccess
 	services.ClusterConfiguration
+	services.Restrictions
 	services.Events
 	events.IAuditLog
 }
@@ -180,6 +191,12 @@ var (
 			Buckets: prometheus.ExponentialBuckets(0.001, 2, 16),
 		},
 	)
+	registeredNodesCount = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: teleport.MetricRegisteredNodes,
+			Help: "Number of nodes currently registered in the cluster",
+		},
+	)
 )
 
 // Server keeps the cluster together. It acts as a certificate authority (CA) for
@@ -233,6 +250,22 @@ type Server struct {
 	// streamer is events sessionstreamer, used to create continuous
 	// session related streams
 	streamer events.Streamer
+
+	// disallowRootLogins strips root and administrator principals from
+	// issued certificates regardless of role configuration.
+	disallowRootLogins bool
+
+	// bpfOverrides is a list of enhanced recording overrides served to
+	// nodes whose static labels match the selector.
+	bpfOverrides []bpf.ConfigOverride
+
+	// minClientVersion is the minimum teleport version agents must run to
+	// connect to this cluster, empty disables the check.
+	minClientVersion string
+
+	// versionCheckMode controls what happens to agents older than
+	// minClientVersion, one of "enforce" (default) or "warn".
+	versionCheckMode string
 }
 
 // SetCache sets cache used by auth server
@@ -252,6 +285,107 @@ func (a *Server) GetCache() Cache {
 	return a.cache
 }
 
+// stripRootLogins removes root and administrator principals from the list
+// of allowed logins, used when the cluster is configured to never issue
+// certificates for privileged accounts.
+func stripRootLogins(logins []string) ([]string, error) {
+	out := make([]string, 0, len(logins))
+	for _, login := range logins {
+		if strings.EqualFold(login, teleport.Root) || strings.EqualFold(login, "administrator") {
+			continue
+		}
+		out = append(out, login)
+	}
+	if len(out) == 0 {
+		return nil, trace.AccessDenied("this cluster does not allow certificates for root or administrator logins")
+	}
+	return out, nil
+}
+
+// checkAgentVersion makes sure the heartbeating agent runs at least the
+// minimum teleport version configured for this cluster. In "warn" mode a
+// warning is logged instead of rejecting the heartbeat. Agents reporting
+// unparseable versions are let through with a warning, rejecting them
+// would take otherwise healthy agents out of the inventory.
+func (a *Server) checkAgentVersion(agentType string, server services.Server) error {
+	if a.minClientVersion == "" {
+		return nil
+	}
+	min, err := semver.NewVersion(a.minClientVersion)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	version, err := semver.NewVersion(server.GetTeleportVersion())
+	if err != nil {
+		log.Warnf("%v %v reports unparseable version %q.", agentType, server.GetHostname(), server.GetTeleportVersion())
+		return nil
+	}
+	if !version.LessThan(*min) {
+		return nil
+	}
+	if a.versionCheckMode == teleport.VersionCheckModeWarn {
+		log.Warnf("%v %v is running teleport %v which is older than the minimum supported version %v, upgrade it.",
+			agentType, server.GetHostname(), server.GetTeleportVersion(), a.minClientVersion)
+		return nil
+	}
+	return trace.AccessDenied(
+		"%v %v is running teleport %v which is older than the minimum supported version %v required by this cluster",
+		agentType, server.GetHostname(), server.GetTeleportVersion(), a.minClientVersion)
+}
+
+// GetBPFOverride evaluates the configured enhanced recording overrides
+// against the node labels and returns the merged result, nodes apply it
+// on top of their file configuration at startup.
+func (a *Server) GetBPFOverride(labels map[string]string) (*bpf.ConfigOverride, error) {
+	merged := bpf.MergeOverrides(a.bpfOverrides, labels)
+	return &merged, nil
+}
+
+// ListUnifiedResources returns one page of a flattened listing of the
+// resources registered with this cluster: nodes plus the kubernetes
+// clusters advertised by proxies.
+func (a *Server) ListUnifiedResources(startKey string, limit int) (*services.UnifiedResourcePage, error) {
+	nodes, err := a.GetNodes(defaults.Namespace)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	proxies, err := a.GetProxies()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	page := services.BuildUnifiedResourcePage(nodes, proxies, startKey, limit)
+	return &page, nil
+}
+
+// UpsertNode registers the node heartbeat after checking the node runs a
+// supported teleport version.
+func (a *Server) UpsertNode(server services.Server) (*services.KeepAlive, error) {
+	if err := a.checkAgentVersion("node", server); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return a.Services.UpsertNode(server)
+}
+
+// UpsertNodes bulk registers node heartbeats after checking the nodes run
+// a supported teleport version.
+func (a *Server) UpsertNodes(namespace string, servers []services.Server) error {
+	for _, server := range servers {
+		if err := a.checkAgentVersion("node", server); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return a.Services.UpsertNodes(namespace, servers)
+}
+
+// UpsertProxy registers the proxy heartbeat after checking the proxy runs
+// a supported teleport version.
+func (a *Server) UpsertProxy(server services.Server) error {
+	if err := a.checkAgentVersion("proxy", server); err != nil {
+		return trace.Wrap(err)
+	}
+	return a.Services.UpsertProxy(server)
+}
+
 // runPeriodicOperations runs some periodic bookkeeping operations
 // performed by auth server
 func (a *Server) runPeriodicOperations() {
@@ -277,10 +411,22 @@ func (a *Server) runPeriodicOperations() {
 					log.Errorf("Failed to perform cert rotation check: %v.", err)
 				}
 			}
+			a.updateNodeMetrics()
 		}
 	}
 }
 
+// updateNodeMetrics refreshes the gauge tracking the number of nodes
+// registered in the cluster
+func (a *Server) updateNodeMetrics() {
+	nodes, err := a.GetNodes(defaults.Namespace)
+	if err != nil {
+		log.Errorf("Failed to update node count metric: %v.", err)
+		return
+	}
+	registeredNodesCount.Set(float64(len(nodes)))
+}
+
 func (a *Server) Close() error {
 	a.cancelFunc()
 	if a.bk != nil {
@@ -515,6 +661,13 @@ func (a *Server) generateUserCert(req certRequest) (*certs, error) {
 		}
 	}
 
+	if a.disallowRootLogins {
+		allowedLogins, err = stripRootLogins(allowedLogins)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+
 	clusterName, err := a.GetDomainName()
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -545,6 +698,7 @@ func (a *Server) generateUserCert(req certRequest) (*certs, error) {
 		RouteToCluster:        req.routeToCluster,
 		Traits:                req.traits,
 		ActiveRequests:        req.activeRequests,
+		SourceAddresses:       req.checker.CertSourceAddresses(),
 	})
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -1861,4 +2015,5 @@ func init() {
 	prometheus.MustRegister(generateThrottledRequestsCount)
 	prometheus.MustRegister(generateRequestsCurrent)
 	prometheus.MustRegister(generateRequestsLatencies)
+	prometheus.MustRegister(registeredNodesCount)
 }
@@ -18,12 +18,14 @@ package auth
 
 import (
 	"context"
+	"io"
 	"net/url"
 	"time"
 
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib/auth/proto"
 	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/bpf"
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/session"
@@ -570,6 +572,41 @@ func (a *ServerWithRoles) GetNodes(namespace string, opts ...services.MarshalOpt
 	return filteredNodes, nil
 }
 
+// ListNodes returns a page of nodes the caller has access to. Because
+// access filtering can thin out a backend page, pages are fetched until
+// the requested page is full or the nodes run out.
+func (a *ServerWithRoles) ListNodes(namespace string, limit int, startKey string, opts ...services.MarshalOption) ([]services.Server, string, error) {
+	if err := a.action(namespace, services.KindNode, services.VerbList); err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+	if limit <= 0 {
+		return nil, "", trace.BadParameter("missing positive limit value")
+	}
+
+	page := make([]services.Server, 0, limit)
+	for {
+		nodes, nextKey, err := a.authServer.ListNodes(namespace, limit, startKey, opts...)
+		if err != nil {
+			return nil, "", trace.Wrap(err)
+		}
+		filtered, err := a.filterNodes(nodes)
+		if err != nil {
+			return nil, "", trace.Wrap(err)
+		}
+		for _, node := range filtered {
+			if len(page) == limit {
+				// The page is full, the next page starts at this node.
+				return page, node.GetName(), nil
+			}
+			page = append(page, node)
+		}
+		startKey = nextKey
+		if nextKey == "" || len(page) == limit {
+			return page, nextKey, nil
+		}
+	}
+}
+
 func (a *ServerWithRoles) UpsertAuthServer(s services.Server) error {
 	if err := a.action(defaults.Namespace, services.KindAuthServer, services.VerbCreate); err != nil {
 		return trace.Wrap(err)
@@ -717,6 +754,38 @@ func (a *ServerWithRoles) UpsertPassword(user string, password []byte) error {
 	return a.authServer.UpsertPassword(user, password)
 }
 
+// GenerateRecoveryCodes generates a new set of one-time recovery codes
+// for a user.
+func (a *ServerWithRoles) GenerateRecoveryCodes(ctx context.Context, user string) ([]string, error) {
+	if err := a.currentUserAction(user); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return a.authServer.GenerateRecoveryCodes(ctx, user)
+}
+
+// UseRecoveryCode spends a recovery code and returns a reset password token.
+func (a *ServerWithRoles) UseRecoveryCode(ctx context.Context, user string, code string) (services.ResetPasswordToken, error) {
+	// The recovery code is its own authentication, no need to double check.
+	// Attempts are rate limited and subject to user lockout.
+	return a.authServer.UseRecoveryCode(ctx, user, code)
+}
+
+// GetUserPreferences returns the web UI preferences for a user.
+func (a *ServerWithRoles) GetUserPreferences(user string) (*services.UserPreferences, error) {
+	if err := a.currentUserAction(user); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return a.authServer.GetUserPreferences(user)
+}
+
+// UpsertUserPreferences updates the web UI preferences for a user.
+func (a *ServerWithRoles) UpsertUserPreferences(user string, prefs services.UserPreferences) error {
+	if err := a.currentUserAction(user); err != nil {
+		return trace.Wrap(err)
+	}
+	return a.authServer.UpsertUserPreferences(user, prefs)
+}
+
 // ChangePassword updates users password based on the old password.
 func (a *ServerWithRoles) ChangePassword(req services.ChangePasswordReq) error {
 	if err := a.currentUserAction(req.User); err != nil {
@@ -1507,6 +1576,36 @@ func (a *ServerWithRoles) UploadSessionRecording(r events.SessionRecording) erro
 	return a.alog.UploadSessionRecording(r)
 }
 
+// DownloadSessionRecording downloads the stored session recording tarball
+// from the session storage handler and writes it to writer.
+func (a *ServerWithRoles) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	if err := a.action(namespace, services.KindSession, services.VerbRead); err != nil {
+		return trace.Wrap(err)
+	}
+
+	return a.alog.DownloadSessionRecording(namespace, sid, writer)
+}
+
+// DeleteSessionRecording deletes the stored session recording from the
+// session storage handler, audit events of the session are not affected.
+func (a *ServerWithRoles) DeleteSessionRecording(namespace string, sid session.ID) error {
+	if err := a.action(namespace, services.KindSession, services.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+
+	if err := a.alog.DeleteSessionRecording(namespace, sid); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := a.alog.EmitAuditEventLegacy(events.SessionRecordingDeleteE, events.EventFields{
+		events.SessionEventID: string(sid),
+		events.EventNamespace: namespace,
+		events.EventUser:      a.context.User.GetName(),
+	}); err != nil {
+		log.WithError(err).Warn("Failed to emit session recording delete event.")
+	}
+	return nil
+}
+
 func (a *ServerWithRoles) GetSessionChunk(namespace string, sid session.ID, offsetBytes, maxBytes int) ([]byte, error) {
 	if err := a.action(namespace, services.KindSession, services.VerbRead); err != nil {
 		return nil, trace.Wrap(err)
@@ -1605,6 +1704,15 @@ func (a *ServerWithRoles) UpsertRole(ctx context.Context, role services.Role) er
 	return a.authServer.upsertRole(ctx, role)
 }
 
+// CompareAndSwapRole updates the role value if the existing value matches
+// the value stored in the backend.
+func (a *ServerWithRoles) CompareAndSwapRole(ctx context.Context, new services.Role, existing services.Role) error {
+	if err := a.action(defaults.Namespace, services.KindRole, services.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	return a.authServer.CompareAndSwapRole(ctx, new, existing)
+}
+
 // GetRole returns role by name
 func (a *ServerWithRoles) GetRole(name string) (services.Role, error) {
 	// Current-user exception: we always allow users to read roles
@@ -1634,6 +1742,87 @@ func (a *ServerWithRoles) GetClusterConfig(opts ...services.MarshalOption) (serv
 	return a.authServer.GetClusterConfig(opts...)
 }
 
+// GetBPFOverride returns the merged enhanced recording override for a node
+// with the given labels.
+func (a *ServerWithRoles) GetBPFOverride(labels map[string]string) (*bpf.ConfigOverride, error) {
+	if err := a.action(defaults.Namespace, services.KindClusterConfig, services.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return a.authServer.GetBPFOverride(labels)
+}
+
+// ListUnifiedResources returns one page of the flattened resource listing,
+// trimmed down to the resources the calling identity can see.
+func (a *ServerWithRoles) ListUnifiedResources(startKey string, limit int) (*services.UnifiedResourcePage, error) {
+	if err := a.action(defaults.Namespace, services.KindNode, services.VerbList); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	nodes, err := a.authServer.GetNodes(defaults.Namespace)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	nodes, err = a.filterNodes(nodes)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	// Only the kubernetes cluster names from proxy heartbeats are exposed
+	// here, not the proxies themselves, so listing them does not require
+	// read access to proxy resources.
+	proxies, err := a.authServer.GetProxies()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	page := services.BuildUnifiedResourcePage(nodes, proxies, startKey, limit)
+	return &page, nil
+}
+
+// GetNetworkRestrictions returns the cluster-wide network restrictions.
+func (a *ServerWithRoles) GetNetworkRestrictions(opts ...services.MarshalOption) (services.NetworkRestrictions, error) {
+	if err := a.action(defaults.Namespace, services.KindNetworkRestrictions, services.VerbRead); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return a.authServer.GetNetworkRestrictions(opts...)
+}
+
+// SetNetworkRestrictions upserts the cluster-wide network restrictions.
+func (a *ServerWithRoles) SetNetworkRestrictions(nr services.NetworkRestrictions) error {
+	if err := a.action(defaults.Namespace, services.KindNetworkRestrictions, services.VerbCreate); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := a.action(defaults.Namespace, services.KindNetworkRestrictions, services.VerbUpdate); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := a.authServer.SetNetworkRestrictions(nr); err != nil {
+		return trace.Wrap(err)
+	}
+	// changing the restrictions affects what every recorded session on
+	// every node can reach, leave a trail in the audit log
+	if err := a.alog.EmitAuditEventLegacy(events.NetworkRestrictionsChangeE, events.EventFields{
+		events.EventUser:                a.context.User.GetName(),
+		events.NetworkRestrictionsAllow: nr.GetAllow(),
+		events.NetworkRestrictionsDeny:  nr.GetDeny(),
+	}); err != nil {
+		log.WithError(err).Warn("Failed to emit network restrictions change event.")
+	}
+	return nil
+}
+
+// DeleteNetworkRestrictions deletes the cluster-wide network restrictions.
+func (a *ServerWithRoles) DeleteNetworkRestrictions() error {
+	if err := a.action(defaults.Namespace, services.KindNetworkRestrictions, services.VerbDelete); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := a.authServer.DeleteNetworkRestrictions(); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := a.alog.EmitAuditEventLegacy(events.NetworkRestrictionsChangeE, events.EventFields{
+		events.EventUser: a.context.User.GetName(),
+	}); err != nil {
+		log.WithError(err).Warn("Failed to emit network restrictions change event.")
+	}
+	return nil
+}
+
 // DeleteClusterConfig deletes cluster config
 func (a *ServerWithRoles) DeleteClusterConfig() error {
 	if err := a.action(defaults.Namespace, services.KindClusterConfig, services.VerbDelete); err != nil {
@@ -1666,7 +1855,24 @@ func (a *ServerWithRoles) SetClusterConfig(c services.ClusterConfig) error {
 	if err := a.action(defaults.Namespace, services.KindClusterConfig, services.VerbUpdate); err != nil {
 		return trace.Wrap(err)
 	}
-	return a.authServer.SetClusterConfig(c)
+	var oldRecording string
+	if old, err := a.authServer.GetClusterConfig(); err == nil {
+		oldRecording = old.GetSessionRecording()
+	}
+	if err := a.authServer.SetClusterConfig(c); err != nil {
+		return trace.Wrap(err)
+	}
+	// switching the recording mode changes where (and whether) sessions
+	// are recorded, leave a trail in the audit log
+	if oldRecording != c.GetSessionRecording() {
+		if err := a.alog.EmitAuditEventLegacy(events.SessionRecordingChangeE, events.EventFields{
+			events.EventUser:            a.context.User.GetName(),
+			events.SessionRecordingMode: c.GetSessionRecording(),
+		}); err != nil {
+			log.WithError(err).Warn("Failed to emit session recording change event.")
+		}
+	}
+	return nil
 }
 
 // GetClusterName gets the name of the cluster.
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -40,12 +41,14 @@ import (
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib/auth/proto"
 	"github.com/gravitational/teleport/lib/backend"
+	"github.com/gravitational/teleport/lib/bpf"
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/httplib"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/session"
 	"github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/teleport/lib/utils/proxy"
 
 	empty "github.com/golang/protobuf/ptypes/empty"
 	"github.com/gravitational/roundtrip"
@@ -144,25 +147,99 @@ func DecodeClusterName(serverName string) (string, error) {
 	return string(decoded), nil
 }
 
-// NewAddrDialer returns new dialer from a list of addresses
+// NewAddrDialer returns a dialer that spreads connections across all the
+// configured auth server addresses and fails over between them based on
+// recent dial health: the address that most recently worked is tried first
+// and addresses that failed are put on a short cooldown, only retried once
+// the healthy ones are exhausted. The initial order is randomized so a
+// fleet of agents does not pin to the first server listed.
 func NewAddrDialer(addrs []utils.NetAddr, keepAliveInterval time.Duration) ContextDialer {
-	dialer := net.Dialer{
-		Timeout:   defaults.DefaultDialTimeout,
-		KeepAlive: keepAliveInterval,
-	}
-	return ContextDialerFunc(func(in context.Context, network, _ string) (net.Conn, error) {
-		var err error
-		var conn net.Conn
-		for _, addr := range addrs {
-			conn, err = dialer.DialContext(in, network, addr.Addr)
-			if err == nil {
-				return conn, nil
-			}
-			log.Errorf("Failed to dial auth server %v: %v.", addr.Addr, err)
-		}
-		// not wrapping on purpose to preserve the original error
-		return nil, err
+	d := &addrDialer{
+		addrs:    make([]utils.NetAddr, len(addrs)),
+		cooldown: make(map[string]time.Time),
+		dialer: net.Dialer{
+			Timeout:   defaults.DefaultDialTimeout,
+			KeepAlive: keepAliveInterval,
+		},
+	}
+	copy(d.addrs, addrs)
+	rand.Shuffle(len(d.addrs), func(i, j int) {
+		d.addrs[i], d.addrs[j] = d.addrs[j], d.addrs[i]
 	})
+	return d
+}
+
+// addrDialer dials the auth servers in health-aware order, see NewAddrDialer
+type addrDialer struct {
+	sync.Mutex
+	addrs    []utils.NetAddr
+	cooldown map[string]time.Time
+	dialer   net.Dialer
+}
+
+// DialContext dials the first auth server that accepts a connection,
+// preferring the ones that are not on cooldown
+func (d *addrDialer) DialContext(in context.Context, network, _ string) (net.Conn, error) {
+	var err error
+	var conn net.Conn
+	for _, addr := range d.order() {
+		// If an outbound HTTP or SOCKS5 proxy matching this address is
+		// set in the environment, dial the auth server through it so
+		// nodes behind restrictive egress filtering can still join.
+		if proxy.EnvironmentProxySet(addr.Addr) {
+			conn, err = proxy.DialerFromEnvironment(addr.Addr).DialTimeout(network, addr.Addr, defaults.DefaultDialTimeout)
+		} else {
+			conn, err = d.dialer.DialContext(in, network, addr.Addr)
+		}
+		if err == nil {
+			d.markHealthy(addr)
+			return conn, nil
+		}
+		d.markUnhealthy(addr)
+		log.Errorf("Failed to dial auth server %v: %v.", addr.Addr, err)
+	}
+	// not wrapping on purpose to preserve the original error
+	return nil, err
+}
+
+// order returns all addresses, the healthy ones (most recently successful
+// first) before the ones still on cooldown
+func (d *addrDialer) order() []utils.NetAddr {
+	d.Lock()
+	defer d.Unlock()
+	now := time.Now()
+	healthy := make([]utils.NetAddr, 0, len(d.addrs))
+	var cooling []utils.NetAddr
+	for _, addr := range d.addrs {
+		if until, ok := d.cooldown[addr.Addr]; ok && now.Before(until) {
+			cooling = append(cooling, addr)
+			continue
+		}
+		healthy = append(healthy, addr)
+	}
+	return append(healthy, cooling...)
+}
+
+// markHealthy moves addr to the front of the rotation and clears its cooldown
+func (d *addrDialer) markHealthy(addr utils.NetAddr) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.cooldown, addr.Addr)
+	for i := range d.addrs {
+		if d.addrs[i].Addr == addr.Addr && i != 0 {
+			copy(d.addrs[1:i+1], d.addrs[:i])
+			d.addrs[0] = addr
+			break
+		}
+	}
+}
+
+// markUnhealthy puts addr on cooldown so the other servers are preferred
+// until it recovers
+func (d *addrDialer) markUnhealthy(addr utils.NetAddr) {
+	d.Lock()
+	defer d.Unlock()
+	d.cooldown[addr.Addr] = time.Now().Add(defaults.NetworkBackoffDuration)
 }
 
 // ClientTimeout sets idle and dial timeouts of the HTTP transport
@@ -977,6 +1054,40 @@ func (c *Client) GetNodes(namespace string, opts ...services.MarshalOption) ([]s
 	return re, nil
 }
 
+// ListNodes returns a page of servers registered in the cluster, sorted
+// by name, along with the name to start the next page at.
+func (c *Client) ListNodes(namespace string, limit int, startKey string, opts ...services.MarshalOption) ([]services.Server, string, error) {
+	if namespace == "" {
+		return nil, "", trace.BadParameter(MissingNamespaceError)
+	}
+
+	out, err := c.Get(c.Endpoint("namespaces", namespace, "nodes", "list"), url.Values{
+		"limit":     []string{fmt.Sprintf("%v", limit)},
+		"start_key": []string{startKey},
+	})
+	if err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+
+	var rawPage listNodesRawResponse
+	if err := json.Unmarshal(out.Bytes(), &rawPage); err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+	re := make([]services.Server, len(rawPage.Servers))
+	for i, raw := range rawPage.Servers {
+		s, err := services.GetServerMarshaler().UnmarshalServer(
+			raw,
+			services.KindNode,
+			services.AddOptions(opts, services.SkipValidation())...)
+		if err != nil {
+			return nil, "", trace.Wrap(err)
+		}
+		re[i] = s
+	}
+
+	return re, rawPage.NextKey, nil
+}
+
 // UpsertReverseTunnel is used by admins to create a new reverse tunnel
 // to the remote proxy to bypass firewall restrictions
 func (c *Client) UpsertReverseTunnel(tunnel services.ReverseTunnel) error {
@@ -1323,6 +1434,56 @@ func (c *Client) UpsertPassword(user string, password []byte) error {
 	return nil
 }
 
+// GenerateRecoveryCodes generates a new set of one-time recovery codes
+// for a user, invalidating any previously issued set.
+func (c *Client) GenerateRecoveryCodes(ctx context.Context, user string) ([]string, error) {
+	out, err := c.PostJSON(c.Endpoint("users", user, "recovery", "codes"), nil)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var codes []string
+	if err := json.Unmarshal(out.Bytes(), &codes); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode spends a recovery code and returns a reset password
+// token so the user can set a new password and second factor.
+func (c *Client) UseRecoveryCode(ctx context.Context, user string, code string) (services.ResetPasswordToken, error) {
+	out, err := c.PostJSON(c.Endpoint("users", user, "recovery", "codes", "use"),
+		useRecoveryCodeReq{
+			Code: code,
+		})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return services.GetResetPasswordTokenMarshaler().Unmarshal(out.Bytes())
+}
+
+// GetUserPreferences returns the web UI preferences for a user.
+func (c *Client) GetUserPreferences(user string) (*services.UserPreferences, error) {
+	out, err := c.Get(c.Endpoint("users", user, "web", "preferences"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var prefs services.UserPreferences
+	if err := json.Unmarshal(out.Bytes(), &prefs); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &prefs, nil
+}
+
+// UpsertUserPreferences updates the web UI preferences for a user.
+func (c *Client) UpsertUserPreferences(user string, prefs services.UserPreferences) error {
+	_, err := c.PutJSON(
+		c.Endpoint("users", user, "web", "preferences"),
+		upsertUserPreferencesReq{
+			Preferences: prefs,
+		})
+	return trace.Wrap(err)
+}
+
 // CreateUser inserts a new user entry in a backend.
 func (c *Client) CreateUser(ctx context.Context, user services.User) error {
 	clt, err := c.grpc()
@@ -2273,6 +2434,49 @@ func (c *Client) UploadSessionRecording(r events.SessionRecording) error {
 	return nil
 }
 
+// DownloadSessionRecording downloads the stored session recording tarball
+// from the audit server and writes it to writer
+func (c *Client) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	if namespace == "" {
+		return trace.BadParameter(MissingNamespaceError)
+	}
+	response, err := c.Get(c.Endpoint("namespaces", namespace, "sessions", string(sid), "recording"), url.Values{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := writer.Write(response.Bytes()); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// ExportSession downloads the recording of a session from the audit
+// server as a stream of newline-delimited JSON events with the print
+// payloads inlined and writes it to writer
+func (c *Client) ExportSession(namespace string, sid session.ID, writer io.Writer) error {
+	if namespace == "" {
+		return trace.BadParameter(MissingNamespaceError)
+	}
+	response, err := c.Get(c.Endpoint("namespaces", namespace, "sessions", string(sid), "export"), url.Values{})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := writer.Write(response.Bytes()); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// DeleteSessionRecording deletes the stored session recording from the
+// audit server, audit events of the session are not affected
+func (c *Client) DeleteSessionRecording(namespace string, sid session.ID) error {
+	if namespace == "" {
+		return trace.BadParameter(MissingNamespaceError)
+	}
+	_, err := c.Delete(c.Endpoint("namespaces", namespace, "sessions", string(sid), "recording"))
+	return trace.Wrap(err)
+}
+
 // Returns events that happen during a session sorted by time
 // (oldest first).
 //
@@ -2417,6 +2621,25 @@ func (c *Client) UpsertRole(ctx context.Context, role services.Role) error {
 	return trace.Wrap(err)
 }
 
+// CompareAndSwapRole updates the role value if the existing value matches
+// the value stored in the backend, returns trace.CompareFailed otherwise
+// so callers can re-read the role and retry.
+func (c *Client) CompareAndSwapRole(ctx context.Context, new services.Role, existing services.Role) error {
+	newData, err := services.GetRoleMarshaler().MarshalRole(new)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	existingData, err := services.GetRoleMarshaler().MarshalRole(existing)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = c.PostJSON(c.Endpoint("roles", "compare-and-swap"), &compareAndSwapRoleRawReq{
+		New:      newData,
+		Existing: existingData,
+	})
+	return trace.Wrap(err)
+}
+
 // GetRole returns role by name
 func (c *Client) GetRole(name string) (services.Role, error) {
 	if name == "" {
@@ -2454,6 +2677,45 @@ func (c *Client) GetClusterConfig(opts ...services.MarshalOption) (services.Clus
 	return cc, err
 }
 
+// GetBPFOverride returns the merged enhanced recording override for a node
+// with the given labels.
+func (c *Client) GetBPFOverride(labels map[string]string) (*bpf.ConfigOverride, error) {
+	params := url.Values{}
+	for key, value := range labels {
+		params.Set(key, value)
+	}
+	out, err := c.Get(c.Endpoint("configuration", "bpf_override"), params)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var override bpf.ConfigOverride
+	if err := json.Unmarshal(out.Bytes(), &override); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &override, nil
+}
+
+// ListUnifiedResources returns one page of the flattened listing of
+// resources the calling identity can access.
+func (c *Client) ListUnifiedResources(startKey string, limit int) (*services.UnifiedResourcePage, error) {
+	params := url.Values{}
+	if startKey != "" {
+		params.Set("start_key", startKey)
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	out, err := c.Get(c.Endpoint("unifiedresources"), params)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var page services.UnifiedResourcePage
+	if err := json.Unmarshal(out.Bytes(), &page); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &page, nil
+}
+
 // SetClusterConfig sets cluster level configuration information.
 func (c *Client) SetClusterConfig(cc services.ClusterConfig) error {
 	data, err := services.GetClusterConfigMarshaler().Marshal(cc)
@@ -2469,6 +2731,46 @@ func (c *Client) SetClusterConfig(cc services.ClusterConfig) error {
 	return nil
 }
 
+// GetNetworkRestrictions returns the cluster-wide network restrictions.
+func (c *Client) GetNetworkRestrictions(opts ...services.MarshalOption) (services.NetworkRestrictions, error) {
+	out, err := c.Get(c.Endpoint("restrictions", "network"), url.Values{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	nr, err := services.GetNetworkRestrictionsMarshaler().Unmarshal(out.Bytes(), services.SkipValidation())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return nr, err
+}
+
+// SetNetworkRestrictions upserts the cluster-wide network restrictions.
+func (c *Client) SetNetworkRestrictions(nr services.NetworkRestrictions) error {
+	data, err := services.GetNetworkRestrictionsMarshaler().Marshal(nr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	_, err = c.PostJSON(c.Endpoint("restrictions", "network"), &setNetworkRestrictionsReq{NetworkRestrictions: data})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
+// DeleteNetworkRestrictions deletes the cluster-wide network restrictions.
+func (c *Client) DeleteNetworkRestrictions() error {
+	_, err := c.Delete(c.Endpoint("restrictions", "network"))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
 // GetClusterName returns a cluster name
 func (c *Client) GetClusterName(opts ...services.MarshalOption) (services.ClusterName, error) {
 	out, err := c.Get(c.Endpoint("configuration", "name"), url.Values{})
@@ -2939,6 +3241,28 @@ type IdentityService interface {
 	// UpsertPassword updates web access password for the user
 	UpsertPassword(user string, password []byte) error
 
+	// GenerateRecoveryCodes generates a new set of one-time recovery codes
+	// for a user, invalidating any previously issued set
+	GenerateRecoveryCodes(ctx context.Context, user string) ([]string, error)
+
+	// UseRecoveryCode spends a recovery code and returns a reset password
+	// token so the user can set a new password and second factor
+	UseRecoveryCode(ctx context.Context, user string, code string) (services.ResetPasswordToken, error)
+
+	// GetBPFOverride returns the merged enhanced recording override for a
+	// node with the given labels
+	GetBPFOverride(labels map[string]string) (*bpf.ConfigOverride, error)
+
+	// ListUnifiedResources returns one page of the flattened listing of
+	// resources the calling identity can access
+	ListUnifiedResources(startKey string, limit int) (*services.UnifiedResourcePage, error)
+
+	// GetUserPreferences returns the web UI preferences for a user
+	GetUserPreferences(user string) (*services.UserPreferences, error)
+
+	// UpsertUserPreferences updates the web UI preferences for a user
+	UpsertUserPreferences(user string, prefs services.UserPreferences) error
+
 	// UpsertOIDCConnector updates or creates OIDC connector
 	UpsertOIDCConnector(ctx context.Context, connector services.OIDCConnector) error
 
@@ -3105,6 +3429,7 @@ type ClientI interface {
 	WebService
 	session.Service
 	services.ClusterConfiguration
+	services.Restrictions
 	services.Events
 
 	// NewKeepAliver returns a new instance of keep aliver
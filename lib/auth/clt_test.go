@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddrDialerFailover makes sure the dialer fails over to a healthy
+// auth server when another one stops accepting connections, and that the
+// unreachable server is put on cooldown so the healthy one is tried first
+// on subsequent dials.
+func TestAddrDialerFailover(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	// grab an address nothing is listening on
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	deadAddr := dead.Addr().String()
+	require.NoError(t, dead.Close())
+
+	dialer, ok := NewAddrDialer([]utils.NetAddr{
+		{AddrNetwork: "tcp", Addr: deadAddr},
+		{AddrNetwork: "tcp", Addr: listener.Addr().String()},
+	}, 0).(*addrDialer)
+	require.True(t, ok)
+
+	// regardless of the shuffled order the dial succeeds by failing over
+	conn, err := dialer.DialContext(context.Background(), "tcp", "")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	// the healthy server is now first in the rotation and any dead server
+	// that was dialed is on cooldown
+	require.Equal(t, listener.Addr().String(), dialer.order()[0].Addr)
+
+	conn, err = dialer.DialContext(context.Background(), "tcp", "")
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
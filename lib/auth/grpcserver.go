@@ -34,6 +34,7 @@ import (
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/gravitational/trace"
 	"github.com/gravitational/trace/trail"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
@@ -43,6 +44,69 @@ import (
 	_ "google.golang.org/grpc/encoding/gzip"
 )
 
+var (
+	grpcServerRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: teleport.MetricGRPCServerRequests,
+			Help: "Number of requests served by the auth gRPC API",
+		},
+		[]string{teleport.TagMethod},
+	)
+	grpcServerRequestLatencies = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: teleport.MetricGRPCServerRequestLatency,
+			Help: "Latency of the auth gRPC API requests",
+			// lowest bucket start of upper bound 0.001 sec (1 ms) with factor 2
+			// highest bucket start of 0.001 sec * 2^15 == 32.768 sec
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 16),
+		},
+		[]string{teleport.TagMethod},
+	)
+	grpcServerStreamDurations = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: teleport.MetricGRPCServerStreamDuration,
+			Help: "Duration of the auth gRPC API streams",
+			// streams like watchers stay open for a long time, so the
+			// buckets span 1 sec .. 1 sec * 2^15 == ~9 hours
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		},
+		[]string{teleport.TagMethod},
+	)
+)
+
+func init() {
+	// Metrics have to be registered to be exposed:
+	prometheus.MustRegister(grpcServerRequests)
+	prometheus.MustRegister(grpcServerRequestLatencies)
+	prometheus.MustRegister(grpcServerStreamDurations)
+}
+
+// instrumentUnaryInterceptor wraps a unary interceptor with per-method
+// request counters and latency histograms
+func instrumentUnaryInterceptor(next grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := next(ctx, req, info, handler)
+		grpcServerRequests.WithLabelValues(info.FullMethod).Inc()
+		grpcServerRequestLatencies.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		return resp, err
+	}
+}
+
+// instrumentStreamInterceptor wraps a stream interceptor with per-method
+// request counters and stream duration histograms
+func instrumentStreamInterceptor(next grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := next(srv, ss, info, handler)
+		grpcServerRequests.WithLabelValues(info.FullMethod).Inc()
+		// streams (like watchers) stay open for hours, recording them
+		// into the request latency histogram would skew its percentiles
+		grpcServerStreamDurations.WithLabelValues(info.FullMethod).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
 // GRPCServer is GPRC Auth Server API
 type GRPCServer struct {
 	*logrus.Entry
@@ -710,8 +774,8 @@ func NewGRPCServer(cfg GRPCServerConfig) (*GRPCServer, error) {
 		grpc.Creds(&httplib.TLSCreds{
 			Config: cfg.TLS,
 		}),
-		grpc.UnaryInterceptor(cfg.UnaryInterceptor),
-		grpc.StreamInterceptor(cfg.StreamInterceptor),
+		grpc.UnaryInterceptor(instrumentUnaryInterceptor(cfg.UnaryInterceptor)),
+		grpc.StreamInterceptor(instrumentStreamInterceptor(cfg.StreamInterceptor)),
 		grpc.KeepaliveParams(
 			keepalive.ServerParameters{
 				Time:    cfg.KeepAlivePeriod,
@@ -30,6 +30,7 @@ import (
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib"
 	"github.com/gravitational/teleport/lib/backend"
+	"github.com/gravitational/teleport/lib/bpf"
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/services"
@@ -114,6 +115,9 @@ type InitConfig struct {
 	// ClusterConfiguration is a services that holds cluster wide configuration.
 	ClusterConfiguration services.ClusterConfiguration
 
+	// Restrictions is a service to access network restrictions, etc
+	Restrictions services.Restrictions
+
 	// Roles is a set of roles to create
 	Roles []services.Role
 
@@ -150,6 +154,23 @@ type InitConfig struct {
 	// Streamer is events sessionstreamer, used to create continuous
 	// session related streams
 	Streamer events.Streamer
+
+	// DisallowRootLogins strips root and administrator principals from
+	// issued certificates regardless of role configuration.
+	DisallowRootLogins bool
+
+	// BPFOverrides is a list of enhanced recording overrides served to
+	// nodes whose static labels match the selector.
+	BPFOverrides []bpf.ConfigOverride
+
+	// MinClientVersion is the minimum teleport version clients and agents
+	// must run to connect to this cluster, empty means no check beyond the
+	// compiled-in minimum supported version.
+	MinClientVersion string
+
+	// VersionCheckMode controls what happens to agents older than
+	// MinClientVersion, one of "enforce" (default) or "warn".
+	VersionCheckMode string
 }
 
 // Init instantiates and configures an instance of AuthServer
@@ -307,6 +328,18 @@ func Init(cfg InitConfig, opts ...ServerOption) (*Server, error) {
 		log.Infof("Created default admin role: %q.", defaultRole.GetName())
 	}
 
+	// always create the preset roles, they are only created if missing so
+	// modifications made by cluster administrators are kept
+	for _, role := range services.PresetRoles() {
+		err = asrv.CreateRole(role)
+		if err != nil && !trace.IsAlreadyExists(err) {
+			return nil, trace.Wrap(err)
+		}
+		if !trace.IsAlreadyExists(err) {
+			log.Infof("Created preset role: %q.", role.GetName())
+		}
+	}
+
 	// generate a user certificate authority if it doesn't exist
 	userCA, err := asrv.GetCertAuthority(services.CertAuthID{DomainName: cfg.ClusterName.GetClusterName(), Type: services.UserCA}, true)
 	if err != nil {
@@ -17,6 +17,7 @@ limitations under the License.
 package auth
 
 import (
+	"crypto/x509"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -24,6 +25,7 @@ import (
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/tlsca"
 	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/gravitational/trace"
@@ -42,6 +44,9 @@ type AuthenticateUserRequest struct {
 	OTP *OTPCreds `json:"otp,omitempty"`
 	// Session is a web session credential used to authenticate web sessions
 	Session *SessionCreds `json:"session,omitempty"`
+	// ClientCert is a client TLS certificate credential used to authenticate
+	// API calls made through a proxy that terminates mutual TLS
+	ClientCert *ClientCertCreds `json:"client_cert,omitempty"`
 }
 
 // CheckAndSetDefaults checks and sets defaults
@@ -49,7 +54,7 @@ func (a *AuthenticateUserRequest) CheckAndSetDefaults() error {
 	if a.Username == "" {
 		return trace.BadParameter("missing parameter 'username'")
 	}
-	if a.Pass == nil && a.U2F == nil && a.OTP == nil && a.Session == nil {
+	if a.Pass == nil && a.U2F == nil && a.OTP == nil && a.Session == nil && a.ClientCert == nil {
 		return trace.BadParameter("at least one authentication method is required")
 	}
 	return nil
@@ -81,6 +86,13 @@ type SessionCreds struct {
 	ID string `json:"id"`
 }
 
+// ClientCertCreds is a client TLS certificate credential issued by this
+// cluster's user certificate authority
+type ClientCertCreds struct {
+	// Cert is an x509 client certificate in ASN.1 DER form
+	Cert []byte `json:"cert"`
+}
+
 // AuthenticateUser authenticates user based on the request type
 func (s *Server) AuthenticateUser(req AuthenticateUserRequest) error {
 	err := s.authenticateUser(req)
@@ -160,11 +172,65 @@ func (s *Server) authenticateUser(req AuthenticateUserRequest) error {
 			return trace.AccessDenied("invalid username, password or second factor")
 		}
 		return nil
+	case req.ClientCert != nil:
+		// authenticate using a client TLS certificate - the certificate has
+		// to chain to this cluster's user certificate authority and carry
+		// the identity of the user logging in. Proof of possession of the
+		// private key was established by the TLS handshake at the proxy.
+		err := s.WithUserLock(req.Username, func() error {
+			return s.CheckClientCert(req.Username, req.ClientCert.Cert)
+		})
+		if err != nil {
+			// provide obscure message on purpose, while logging the real
+			// error server side
+			log.Debugf("Failed to authenticate: %v.", err)
+			return trace.AccessDenied("invalid client certificate")
+		}
+		return nil
 	default:
 		return trace.AccessDenied("unsupported authentication method")
 	}
 }
 
+// CheckClientCert verifies that der is a valid x509 certificate issued by
+// this cluster's user certificate authority and that it identifies user
+func (s *Server) CheckClientCert(user string, der []byte) error {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	clusterName, err := s.GetClusterName()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	ca, err := s.GetCertAuthority(services.CertAuthID{
+		Type:       services.UserCA,
+		DomainName: clusterName.GetClusterName(),
+	}, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	pool, err := services.CertPool(ca)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	opts := x509.VerifyOptions{
+		Roots:     pool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if _, err := cert.Verify(opts); err != nil {
+		return trace.Wrap(err)
+	}
+	identity, err := tlsca.FromSubject(cert.Subject, cert.NotAfter)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if identity.Username != user {
+		return trace.AccessDenied("certificate identity %q does not match username %q", identity.Username, user)
+	}
+	return nil
+}
+
 // AuthenticateWebUser authenticates web user, creates and  returns web session
 // in case if authentication is successful. In case if existing session id
 // is used to authenticate, returns session associated with the existing session id
@@ -23,6 +23,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -261,6 +262,11 @@ func (k *Keygen) GenerateUserCert(c services.UserCertParams) ([]byte, error) {
 		teleport.CertExtensionPermitPTY:            "",
 		teleport.CertExtensionPermitPortForwarding: "",
 	}
+	if len(c.SourceAddresses) != 0 {
+		cert.Permissions.CriticalOptions = map[string]string{
+			teleport.CertCriticalOptionSourceAddress: strings.Join(c.SourceAddresses, ","),
+		}
+	}
 	if c.PermitX11Forwarding {
 		cert.Permissions.Extensions[teleport.CertExtensionPermitX11Forwarding] = ""
 	}
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// numRecoveryCodes is how many one-time codes are issued in a set.
+const numRecoveryCodes = 3
+
+// GenerateRecoveryCodes generates a new set of one-time recovery codes for
+// a local user, invalidating any previously issued set. Only bcrypt hashes
+// are stored; the returned plaintext codes are shown to the user once.
+func (s *Server) GenerateRecoveryCodes(ctx context.Context, username string) ([]string, error) {
+	if username == "" {
+		return nil, trace.BadParameter("missing username")
+	}
+	if _, err := s.Identity.GetUser(username, false); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	codes := make([]string, numRecoveryCodes)
+	hashed := make([]services.RecoveryCode, numRecoveryCodes)
+	for i := range codes {
+		code, err := utils.CryptoRandomHex(TokenLenBytes)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		codes[i] = code
+		hashed[i] = services.RecoveryCode{HashedCode: hash}
+	}
+
+	err := s.Identity.UpsertRecoveryCodes(username, services.RecoveryCodes{
+		Codes:   hashed,
+		Created: s.clock.Now().UTC(),
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if err := s.EmitAuditEventLegacy(events.RecoveryCodesGenerateE, events.EventFields{
+		events.EventUser: username,
+	}); err != nil {
+		log.WithError(err).Warnf("Failed to emit recovery codes generate event.")
+	}
+
+	return codes, nil
+}
+
+// UseRecoveryCode checks a recovery code for a user, marks it as spent and
+// returns a reset password token so the user can set a new password and
+// second factor. Attempts are subject to the same lockout rules as logins.
+func (s *Server) UseRecoveryCode(ctx context.Context, username string, code string) (services.ResetPasswordToken, error) {
+	if username == "" {
+		return nil, trace.BadParameter("missing username")
+	}
+
+	err := s.WithUserLock(username, func() error {
+		return s.verifyRecoveryCode(username, code)
+	})
+	if err != nil {
+		if err := s.EmitAuditEventLegacy(events.RecoveryCodeUseFailureE, events.EventFields{
+			events.EventUser: username,
+		}); err != nil {
+			log.WithError(err).Warnf("Failed to emit recovery code use failure event.")
+		}
+		return nil, trace.AccessDenied("invalid username or recovery code")
+	}
+
+	if err := s.EmitAuditEventLegacy(events.RecoveryCodeUseE, events.EventFields{
+		events.EventUser: username,
+	}); err != nil {
+		log.WithError(err).Warnf("Failed to emit recovery code use event.")
+	}
+
+	token, err := s.CreateResetPasswordToken(ctx, CreateResetPasswordTokenRequest{
+		Name: username,
+		Type: ResetPasswordTokenTypePassword,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return token, nil
+}
+
+// verifyRecoveryCode checks the code against the user's unspent codes and
+// marks the matching one as spent.
+func (s *Server) verifyRecoveryCode(username string, code string) error {
+	codes, err := s.Identity.GetRecoveryCodes(username)
+	if err != nil {
+		if trace.IsNotFound(err) {
+			// Run a dummy comparison so users without recovery codes are
+			// not distinguishable by response time.
+			bcrypt.CompareHashAndPassword(fakeRecoveryCodeHash, []byte(code))
+			return trace.AccessDenied("invalid recovery code")
+		}
+		return trace.Wrap(err)
+	}
+
+	for i, rc := range codes.Codes {
+		if rc.IsUsed {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword(rc.HashedCode, []byte(code)) == nil {
+			codes.Codes[i].IsUsed = true
+			return trace.Wrap(s.Identity.UpsertRecoveryCodes(username, *codes))
+		}
+	}
+	return trace.AccessDenied("invalid recovery code")
+}
+
+// fakeRecoveryCodeHash is a bcrypt hash used to keep timing uniform for
+// users without recovery codes.
+var fakeRecoveryCodeHash = []byte(`$2a$10$c2.h4pF9AA25lbrWo6U0D.ZmnYpFDaNzN3weNNYNC3jAkYEX9kpzu`)
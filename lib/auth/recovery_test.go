@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+
+	authority "github.com/gravitational/teleport/lib/auth/testauthority"
+	"github.com/gravitational/teleport/lib/backend"
+	"github.com/gravitational/teleport/lib/backend/lite"
+	"github.com/gravitational/teleport/lib/fixtures"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+
+	"gopkg.in/check.v1"
+)
+
+type RecoveryTest struct {
+	bk backend.Backend
+	a  *Server
+}
+
+var _ = check.Suite(&RecoveryTest{})
+
+func (s *RecoveryTest) SetUpSuite(c *check.C) {
+	utils.InitLoggerForTests()
+}
+
+func (s *RecoveryTest) SetUpTest(c *check.C) {
+	var err error
+	s.bk, err = lite.New(context.TODO(), backend.Params{"path": c.MkDir()})
+	c.Assert(err, check.IsNil)
+
+	clusterName, err := services.NewClusterName(services.ClusterNameSpecV2{
+		ClusterName: "me.localhost",
+	})
+	c.Assert(err, check.IsNil)
+	authConfig := &InitConfig{
+		ClusterName:            clusterName,
+		Backend:                s.bk,
+		Authority:              authority.New(),
+		SkipPeriodicOperations: true,
+	}
+	s.a, err = NewServer(authConfig)
+	c.Assert(err, check.IsNil)
+
+	err = s.a.SetClusterName(clusterName)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *RecoveryTest) TearDownTest(c *check.C) {
+	if s.bk != nil {
+		c.Assert(s.bk.Close(), check.IsNil)
+	}
+}
+
+func (s *RecoveryTest) TestRecoveryCodes(c *check.C) {
+	username := "joe@example.com"
+	_, _, err := CreateUserAndRole(s.a, username, []string{username})
+	c.Assert(err, check.IsNil)
+
+	codes, err := s.a.GenerateRecoveryCodes(context.TODO(), username)
+	c.Assert(err, check.IsNil)
+	c.Assert(codes, check.HasLen, numRecoveryCodes)
+
+	// only hashes are stored in the backend
+	stored, err := s.a.Identity.GetRecoveryCodes(username)
+	c.Assert(err, check.IsNil)
+	c.Assert(stored.Codes, check.HasLen, numRecoveryCodes)
+	for i, rc := range stored.Codes {
+		c.Assert(string(rc.HashedCode), check.Not(check.Equals), codes[i])
+		c.Assert(rc.IsUsed, check.Equals, false)
+	}
+
+	// a wrong code is rejected
+	_, err = s.a.UseRecoveryCode(context.TODO(), username, "not-a-code")
+	fixtures.ExpectAccessDenied(c, err)
+
+	// a valid code yields a reset token and is spent
+	token, err := s.a.UseRecoveryCode(context.TODO(), username, codes[0])
+	c.Assert(err, check.IsNil)
+	c.Assert(token.GetUser(), check.Equals, username)
+
+	_, err = s.a.UseRecoveryCode(context.TODO(), username, codes[0])
+	fixtures.ExpectAccessDenied(c, err)
+
+	// generating a new set invalidates the remaining old codes
+	_, err = s.a.GenerateRecoveryCodes(context.TODO(), username)
+	c.Assert(err, check.IsNil)
+	_, err = s.a.UseRecoveryCode(context.TODO(), username, codes[1])
+	fixtures.ExpectAccessDenied(c, err)
+}
+
+func (s *RecoveryTest) TestRecoveryCodesUnknownUser(c *check.C) {
+	_, err := s.a.GenerateRecoveryCodes(context.TODO(), "missing@example.com")
+	c.Assert(trace.IsNotFound(err), check.Equals, true, check.Commentf("%#v", err))
+
+	// no username oracle: unknown users get the same error as bad codes
+	_, err = s.a.UseRecoveryCode(context.TODO(), "missing@example.com", "not-a-code")
+	fixtures.ExpectAccessDenied(c, err)
+}
@@ -84,8 +84,9 @@ type RegisterParams struct {
 	PublicSSHKey []byte
 	// CipherSuites is a list of cipher suites to use for TLS client connection
 	CipherSuites []uint16
-	// CAPin is the SKPI hash of the CA used to verify the Auth Server.
-	CAPin string
+	// CAPins are the SKPI hashes of the CAs used to verify the Auth Server.
+	// Multiple pins can be listed so a fleet keeps joining during CA rotation.
+	CAPins []string
 	// CAPath is the path to the CA file.
 	CAPath string
 	// GetHostCredentials is a client that can fetch host credentials.
@@ -176,7 +177,7 @@ func registerThroughAuth(token string, params RegisterParams) (*Identity, error)
 	// Auth Server is validated. Otherwise attempt to use the CA file on disk
 	// but if it's not available connect without validating the Auth Server CA.
 	switch {
-	case params.CAPin != "":
+	case len(params.CAPins) != 0:
 		client, err = pinRegisterClient(params)
 	default:
 		client, err = insecureRegisterClient(params)
@@ -278,26 +279,36 @@ func pinRegisterClient(params RegisterParams) (*Client, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	tlsCA, err := tlsca.ParseCertificatePEM(localCA.TLSCA)
+	// During CA rotation the Auth Server serves a bundle with both the old
+	// and the new CA, so parse out all certificates.
+	certs, err := utils.ReadCertificateChain(localCA.TLSCA)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 
-	// Check that the SPKI pin matches the CA we fetched over a insecure
-	// connection. This makes sure the CA fetched over a insecure connection is
-	// in-fact the expected CA.
-	err = utils.CheckSPKI(params.CAPin, tlsCA)
-	if err != nil {
-		return nil, trace.Wrap(err)
+	// Check that the SPKI pin matches a CA we fetched over a insecure
+	// connection. This makes sure a CA fetched over a insecure connection is
+	// in-fact an expected CA. Only pinned certificates are trusted: a node
+	// with the old pin keeps joining mid-rotation, but never trusts a CA
+	// that matches none of its pins.
+	certPool := x509.NewCertPool()
+	var pinned []*x509.Certificate
+	for _, cert := range certs {
+		if err := utils.CheckSPKI(params.CAPins, cert); err != nil {
+			continue
+		}
+		certPool.AddCert(cert)
+		pinned = append(pinned, cert)
+	}
+	if len(pinned) == 0 {
+		return nil, trace.Wrap(utils.CheckSPKI(params.CAPins, certs[0]))
 	}
 
-	log.Infof("Joining remote cluster %v with CA pin.", tlsCA.Subject.CommonName)
+	log.Infof("Joining remote cluster %v with CA pin.", pinned[0].Subject.CommonName)
 
 	// Create another client, but this time with the CA provided to validate
 	// that the Auth Server was issued a certificate by the same CA.
 	tlsConfig = utils.TLSConfig(params.CipherSuites)
-	certPool := x509.NewCertPool()
-	certPool.AddCert(tlsCA)
 	tlsConfig.RootCAs = certPool
 
 	client, err = NewTLSClient(ClientConfig{Addrs: params.Servers, TLS: tlsConfig})
@@ -39,7 +39,7 @@ func (a *Server) UpsertSAMLConnector(ctx context.Context, connector services.SAM
 	if err := a.Identity.UpsertSAMLConnector(connector); err != nil {
 		return trace.Wrap(err)
 	}
-	if err := a.emitter.EmitAuditEvent(ctx, &events.OIDCConnectorCreate{
+	if err := a.emitter.EmitAuditEvent(ctx, &events.SAMLConnectorCreate{
 		Metadata: events.Metadata{
 			Type: events.SAMLConnectorCreatedEvent,
 			Code: events.SAMLConnectorCreatedCode,
@@ -62,7 +62,7 @@ func (a *Server) DeleteSAMLConnector(ctx context.Context, connectorName string)
 	if err := a.Identity.DeleteSAMLConnector(connectorName); err != nil {
 		return trace.Wrap(err)
 	}
-	if err := a.emitter.EmitAuditEvent(ctx, &events.OIDCConnectorDelete{
+	if err := a.emitter.EmitAuditEvent(ctx, &events.SAMLConnectorDelete{
 		Metadata: events.Metadata{
 			Type: events.SAMLConnectorDeletedEvent,
 			Code: events.SAMLConnectorDeletedCode,
@@ -2181,7 +2181,25 @@ func (s *TLSSuite) TestRegisterCAPin(c *check.C) {
 		PrivateKey:           priv,
 		PublicSSHKey:         pub,
 		PublicTLSKey:         pubTLS,
-		CAPin:                caPin,
+		CAPins:               []string{caPin},
+	})
+	c.Assert(err, check.IsNil)
+
+	// Attempt to register with multiple CA pins where the auth server only
+	// matches one, should work.
+	_, err = Register(RegisterParams{
+		Servers: []utils.NetAddr{utils.FromAddr(s.server.Addr())},
+		Token:   token,
+		ID: IdentityID{
+			HostUUID: "once",
+			NodeName: "node-name",
+			Role:     teleport.RoleProxy,
+		},
+		AdditionalPrincipals: []string{"example.com"},
+		PrivateKey:           priv,
+		PublicSSHKey:         pub,
+		PublicTLSKey:         pubTLS,
+		CAPins:               []string{"sha256:123", caPin},
 	})
 	c.Assert(err, check.IsNil)
 
@@ -2198,7 +2216,7 @@ func (s *TLSSuite) TestRegisterCAPin(c *check.C) {
 		PrivateKey:           priv,
 		PublicSSHKey:         pub,
 		PublicTLSKey:         pubTLS,
-		CAPin:                "sha256:123",
+		CAPins:               []string{"sha256:123"},
 	})
 	c.Assert(err, check.NotNil)
 }
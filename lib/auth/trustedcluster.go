@@ -415,6 +415,10 @@ func (a *Server) updateRemoteClusterStatus(remoteCluster services.RemoteCluster)
 		remoteCluster.SetLastHeartbeat(lastConn.GetLastHeartbeat().UTC())
 	}
 	if prevConnectionStatus != remoteCluster.GetConnectionStatus() || !prevLastHeartbeat.Equal(remoteCluster.GetLastHeartbeat()) {
+		if prevConnectionStatus != remoteCluster.GetConnectionStatus() {
+			log.Infof("Remote cluster %v changed connection status: %v -> %v.",
+				remoteCluster.GetName(), prevConnectionStatus, remoteCluster.GetConnectionStatus())
+		}
 		if err := a.UpdateRemoteCluster(ctx, remoteCluster); err != nil {
 			return trace.Wrap(err)
 		}
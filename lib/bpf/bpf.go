@@ -1,4 +1,4 @@
-// +build bpf,!386
+// +build bpf,!386,!arm
 
 /*
 Copyright 2019 Gravitational, Inc.
@@ -19,6 +19,7 @@ limitations under the License.
 package bpf
 
 // #cgo LDFLAGS: -ldl
+// #include <dlfcn.h>
 // #include <stdlib.h>
 import "C"
 
@@ -27,8 +28,12 @@ import (
 	"context"
 	"encoding/binary"
 	"net"
+	"runtime"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 	"unsafe"
 
@@ -36,13 +41,49 @@ import (
 	controlgroup "github.com/gravitational/teleport/lib/cgroup"
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/gravitational/trace"
 	"github.com/gravitational/ttlmap"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/iovisor/gobpf/bcc"
 )
 
+// IsHostCompatible checks that BPF programs can run on this host.
+func IsHostCompatible() error {
+	// The BPF programs are compiled for 64-bit kernels, 32-bit builds get
+	// the NOP implementation at compile time, but a 32-bit userland can
+	// also run on a 64-bit kernel so check the architecture here as well.
+	switch runtime.GOARCH {
+	case "amd64", "arm64":
+	default:
+		return trace.BadParameter("enhanced session recording is not supported on %v", runtime.GOARCH)
+	}
+
+	// To find the cgroup ID of a program, bpf_get_current_cgroup_id is needed
+	// which was introduced in 4.18.
+	// https://github.com/torvalds/linux/commit/bf6fa2c893c5237b48569a13fa3c673041430b6c
+	minKernel := semver.New(teleport.EnhancedRecordingMinKernel)
+	version, err := utils.KernelVersion()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if version.LessThan(*minKernel) {
+		return trace.BadParameter("incompatible kernel found, minimum supported kernel is %v", minKernel)
+	}
+
+	// Check that libbcc is on the system.
+	libraryName := C.CString("libbcc.so.0")
+	defer C.free(unsafe.Pointer(libraryName))
+	handle := C.dlopen(libraryName, C.RTLD_NOW)
+	if handle == nil {
+		return trace.BadParameter("libbcc.so not found")
+	}
+
+	return nil
+}
+
 // Service manages BPF and control groups orchestration.
 type Service struct {
 	*Config
@@ -72,6 +113,15 @@ type Service struct {
 
 	// conn is a BPF programs that hooks connect.
 	conn *conn
+
+	// library is a BPF program that hooks dlopen.
+	library *dlopen
+
+	// dropDisk and dropNetwork are set (atomically) by the overhead
+	// watchdog when the configured CPU/memory guardrails are exceeded,
+	// causing the corresponding events to be discarded.
+	dropDisk    int32
+	dropNetwork int32
 }
 
 // New creates a BPF service.
@@ -137,6 +187,10 @@ func New(config *Config) (BPF, error) {
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	s.library, err = startDlopen(closeContext, *config.CommandBufferSize)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
 
 	log.Debugf("Started enhanced session recording with buffer sizes (command=%v, "+
 		"disk=%v, network=%v) and cgroup mount path: %v. Took %v.",
@@ -147,6 +201,12 @@ func New(config *Config) (BPF, error) {
 	// Audit Log.
 	go s.loop()
 
+	// If overhead limits have been configured, watch the CPU and memory
+	// consumed by event processing and degrade recording when exceeded.
+	if config.CPULimit > 0 || config.MemoryLimit > 0 {
+		go s.watchOverhead()
+	}
+
 	return s, nil
 }
 
@@ -158,6 +218,7 @@ func (s *Service) Close() error {
 	s.exec.close()
 	s.open.close()
 	s.conn.close()
+	s.library.close()
 
 	// Close cgroup service.
 	s.cgroup.Close()
@@ -176,6 +237,16 @@ func (s *Service) OpenSession(ctx *SessionContext) (uint64, error) {
 		return 0, trace.Wrap(err)
 	}
 
+	// Apply any session resource limits from the roles to the session
+	// cgroup. Best-effort: on hosts where the memory and cpu controllers
+	// are not available the session proceeds without limits.
+	if ctx.MemoryLimit != 0 || ctx.CPULimit != 0 {
+		err = s.cgroup.SetLimits(ctx.SessionID, ctx.MemoryLimit, ctx.CPULimit)
+		if err != nil {
+			log.Warningf("Failed to apply session resource limits: %v.", err)
+		}
+	}
+
 	cgroupID, err := s.cgroup.ID(ctx.SessionID)
 	if err != nil {
 		return 0, trace.Wrap(err)
@@ -204,6 +275,26 @@ func (s *Service) CloseSession(ctx *SessionContext) error {
 	// Stop watching for events from this PID.
 	s.removeWatch(cgroupID)
 
+	// Surface any OOM kills that occurred within the session to the audit
+	// log before the cgroup is removed.
+	if ctx.MemoryLimit != 0 {
+		count, err := s.cgroup.OOMKills(ctx.SessionID)
+		switch {
+		case err != nil:
+			log.Debugf("Failed to read OOM kill count for session: %v.", err)
+		case count > 0:
+			if err := s.AuditLog.EmitAuditEventLegacy(events.SessionOOME, events.EventFields{
+				events.SessionEventID: ctx.SessionID,
+				events.EventNamespace: ctx.Namespace,
+				events.EventUser:      ctx.User,
+				events.EventLogin:     ctx.Login,
+				events.OOMKills:       count,
+			}); err != nil {
+				log.WithError(err).Warn("Failed to emit session OOM event.")
+			}
+		}
+	}
+
 	// Move all PIDs to the root cgroup and remove the cgroup created for this
 	// session.
 	err = s.cgroup.Remove(ctx.SessionID)
@@ -231,6 +322,12 @@ func (s *Service) loop() {
 		// Network access (IPv4).
 		case event := <-s.conn.v6Events():
 			s.emit6NetworkEvent(event)
+		// Unix domain socket connections.
+		case event := <-s.conn.unixEvents():
+			s.emitUnixEvent(event)
+		// Shared library loads.
+		case event := <-s.library.events():
+			s.emitLibraryEvent(event)
 		case <-s.closeContext.Done():
 			return
 		}
@@ -315,6 +412,67 @@ func (s *Service) emitCommandEvent(eventBytes []byte) {
 		if err := ctx.Emitter.EmitAuditEvent(ctx.Context, sessionCommandEvent); err != nil {
 			log.WithError(err).Warn("Failed to emit command event.")
 		}
+		if ctx.Summary != nil {
+			ctx.Summary.AddProgram(argv[0])
+		}
+
+		// Optionally capture the environment the program was executed
+		// with. Best-effort: the environment is read from procfs, a
+		// process that exits quickly may be gone before the event is
+		// processed.
+		if s.CaptureEnv {
+			env := CaptureEnvironment(event.PID, s.EnvAllowlist, s.EnvDenylist)
+			if len(env) != 0 {
+				if err := s.AuditLog.EmitAuditEventLegacy(events.SessionCommandEnvE, events.EventFields{
+					events.SessionEventID: ctx.SessionID,
+					events.EventNamespace: ctx.Namespace,
+					events.EventUser:      ctx.User,
+					events.EventLogin:     ctx.Login,
+					events.PID:            event.PID,
+					events.Path:           argv[0],
+					events.Environment:    env,
+				}); err != nil {
+					log.WithError(err).Warn("Failed to emit command environment event.")
+				}
+			}
+		}
+
+		// Flag programs executed with dynamic linker overrides. Loader
+		// environment variables allow injecting arbitrary shared
+		// libraries into a process, emit a library load event so these
+		// are visible in the audit log.
+		if env := CaptureEnvironment(event.PID, []string{"LD_PRELOAD", "LD_LIBRARY_PATH"}, nil); len(env) != 0 {
+			if err := s.AuditLog.EmitAuditEventLegacy(events.SessionLibraryLoadE, events.EventFields{
+				events.SessionEventID: ctx.SessionID,
+				events.EventNamespace: ctx.Namespace,
+				events.EventUser:      ctx.User,
+				events.EventLogin:     ctx.Login,
+				events.PID:            event.PID,
+				events.Path:           argv[0],
+				events.Environment:    env,
+			}); err != nil {
+				log.WithError(err).Warn("Failed to emit library load event.")
+			}
+		}
+
+		// Reverse shell heuristic: a shell executed with a socket wired to
+		// both standard input and output almost always means a connection
+		// was redirected into an interactive shell, for example with
+		// dup2(2) on a connected socket before the exec. Best-effort: the
+		// file descriptors are read from procfs.
+		if isShell(argv[0]) && stdioIsSocket(event.PID) {
+			if err := s.AuditLog.EmitAuditEventLegacy(events.SessionReverseShellE, events.EventFields{
+				events.SessionEventID: ctx.SessionID,
+				events.EventNamespace: ctx.Namespace,
+				events.EventUser:      ctx.User,
+				events.EventLogin:     ctx.Login,
+				events.PID:            event.PID,
+				events.Path:           argv[0],
+				events.Argv:           argv[1:],
+			}); err != nil {
+				log.WithError(err).Warn("Failed to emit reverse shell event.")
+			}
+		}
 
 		// Now that the event has been processed, remove from cache.
 		s.argsCache.Remove(strconv.FormatUint(event.PID, 10))
@@ -323,6 +481,12 @@ func (s *Service) emitCommandEvent(eventBytes []byte) {
 
 // emitDiskEvent will parse and emit disk events to the Audit Log.
 func (s *Service) emitDiskEvent(eventBytes []byte) {
+	// If disk events have been disabled by the overhead watchdog, drop the
+	// event before doing any processing.
+	if atomic.LoadInt32(&s.dropDisk) == 1 {
+		return
+	}
+
 	// Unmarshal raw event bytes.
 	var event rawOpenEvent
 	err := unmarshalEvent(eventBytes, &event)
@@ -337,16 +501,25 @@ func (s *Service) emitDiskEvent(eventBytes []byte) {
 		return
 	}
 
-	// If the network event is not being monitored, don't process it.
+	path := convertString(unsafe.Pointer(&event.Path))
+	restricted := isRestrictedWrite(ctx, event.Flags, path)
+
+	// If disk events are not being monitored, don't process the event,
+	// with the exception of restricted write attempts which are always
+	// audited.
 	_, ok = ctx.Events[teleport.EnhancedRecordingDisk]
-	if !ok {
+	if !ok && !restricted {
 		return
 	}
 
+	code := events.SessionDiskCode
+	if restricted {
+		code = events.SessionDiskRestrictedCode
+	}
 	sessionDiskEvent := &events.SessionDisk{
 		Metadata: events.Metadata{
 			Type: events.SessionDiskEvent,
-			Code: events.SessionDiskCode,
+			Code: code,
 		},
 		ServerMetadata: events.ServerMetadata{
 			ServerID:        ctx.ServerID,
@@ -365,17 +538,21 @@ func (s *Service) emitDiskEvent(eventBytes []byte) {
 			PID:      event.PID,
 		},
 		Flags:      event.Flags,
-		Path:       convertString(unsafe.Pointer(&event.Path)),
+		Path:       path,
 		ReturnCode: event.ReturnCode,
 	}
 	// Logs can be DoS by event failures here
 	_ = ctx.Emitter.EmitAuditEvent(ctx.Context, sessionDiskEvent)
+	if ctx.Summary != nil {
+		ctx.Summary.AddFile(sessionDiskEvent.Path)
+	}
 }
 
-// emit4NetworkEvent will parse and emit IPv4 events to the Audit Log.
-func (s *Service) emit4NetworkEvent(eventBytes []byte) {
+// emitLibraryEvent will parse and emit shared library load events to the
+// Audit Log.
+func (s *Service) emitLibraryEvent(eventBytes []byte) {
 	// Unmarshal raw event bytes.
-	var event rawConn4Event
+	var event rawLibraryEvent
 	err := unmarshalEvent(eventBytes, &event)
 	if err != nil {
 		log.Debugf("Failed to read binary data: %v.", err)
@@ -388,8 +565,64 @@ func (s *Service) emit4NetworkEvent(eventBytes []byte) {
 		return
 	}
 
-	// If the network event is not being monitored, don't process it.
-	_, ok = ctx.Events[teleport.EnhancedRecordingNetwork]
+	// Library loads are emitted as part of command auditing, if command
+	// events are not being monitored, don't process the event.
+	_, ok = ctx.Events[teleport.EnhancedRecordingCommand]
+	if !ok {
+		return
+	}
+
+	if err := s.AuditLog.EmitAuditEventLegacy(events.SessionLibraryLoadE, events.EventFields{
+		events.SessionEventID: ctx.SessionID,
+		events.EventNamespace: ctx.Namespace,
+		events.EventUser:      ctx.User,
+		events.EventLogin:     ctx.Login,
+		events.PID:            event.PID,
+		events.Path:           convertString(unsafe.Pointer(&event.Path)),
+	}); err != nil {
+		log.WithError(err).Warn("Failed to emit library load event.")
+	}
+}
+
+// isRestrictedWrite returns true if the open attempt carries write intent
+// and targets a path under one of the session's restricted prefixes. On
+// kernels without BPF LSM support the attempt is audited rather than
+// blocked.
+func isRestrictedWrite(ctx *SessionContext, flags int32, path string) bool {
+	if len(ctx.BlockedWritePaths) == 0 {
+		return false
+	}
+	accessMode := flags & syscall.O_ACCMODE
+	if accessMode != syscall.O_WRONLY && accessMode != syscall.O_RDWR &&
+		flags&(syscall.O_CREAT|syscall.O_TRUNC|syscall.O_APPEND) == 0 {
+		return false
+	}
+	for _, prefix := range ctx.BlockedWritePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// emit4NetworkEvent will parse and emit IPv4 events to the Audit Log.
+func (s *Service) emit4NetworkEvent(eventBytes []byte) {
+	// If network events have been disabled by the overhead watchdog, drop
+	// the event before doing any processing.
+	if atomic.LoadInt32(&s.dropNetwork) == 1 {
+		return
+	}
+
+	// Unmarshal raw event bytes.
+	var event rawConn4Event
+	err := unmarshalEvent(eventBytes, &event)
+	if err != nil {
+		log.Debugf("Failed to read binary data: %v.", err)
+		return
+	}
+
+	// If the event comes from a unmonitored process/cgroup, don't process it.
+	ctx, ok := s.getWatch(event.CgroupID)
 	if !ok {
 		return
 	}
@@ -404,10 +637,24 @@ func (s *Service) emit4NetworkEvent(eventBytes []byte) {
 	binary.LittleEndian.PutUint32(dst, uint32(event.DstAddr))
 	dstAddr := net.IP(dst)
 
+	restricted := s.RestrictedNetworks.Denied(dstAddr)
+
+	// If network events are not being monitored, don't process the event,
+	// with the exception of connections to restricted destinations which
+	// are always audited.
+	_, ok = ctx.Events[teleport.EnhancedRecordingNetwork]
+	if !ok && !restricted {
+		return
+	}
+
+	code := events.SessionNetworkCode
+	if restricted {
+		code = events.SessionNetworkRestrictedCode
+	}
 	sessionNetworkEvent := &events.SessionNetwork{
 		Metadata: events.Metadata{
 			Type: events.SessionNetworkEvent,
-			Code: events.SessionNetworkCode,
+			Code: code,
 		},
 		ServerMetadata: events.ServerMetadata{
 			ServerID:        ctx.ServerID,
@@ -433,10 +680,19 @@ func (s *Service) emit4NetworkEvent(eventBytes []byte) {
 	if err := ctx.Emitter.EmitAuditEvent(ctx.Context, sessionNetworkEvent); err != nil {
 		log.WithError(err).Warn("Failed to emit network event.")
 	}
+	if ctx.Summary != nil {
+		ctx.Summary.AddNetwork(net.JoinHostPort(sessionNetworkEvent.DstAddr, strconv.Itoa(int(sessionNetworkEvent.DstPort))))
+	}
 }
 
 // emit6NetworkEvent will parse and emit IPv6 events to the Audit Log.
 func (s *Service) emit6NetworkEvent(eventBytes []byte) {
+	// If network events have been disabled by the overhead watchdog, drop
+	// the event before doing any processing.
+	if atomic.LoadInt32(&s.dropNetwork) == 1 {
+		return
+	}
+
 	// Unmarshal raw event bytes.
 	var event rawConn6Event
 	err := unmarshalEvent(eventBytes, &event)
@@ -451,12 +707,6 @@ func (s *Service) emit6NetworkEvent(eventBytes []byte) {
 		return
 	}
 
-	// If the network event is not being monitored, don't process it.
-	_, ok = ctx.Events[teleport.EnhancedRecordingNetwork]
-	if !ok {
-		return
-	}
-
 	// Source.
 	src := make([]byte, 16)
 	binary.LittleEndian.PutUint32(src[0:], event.SrcAddr[0])
@@ -473,10 +723,24 @@ func (s *Service) emit6NetworkEvent(eventBytes []byte) {
 	binary.LittleEndian.PutUint32(dst[12:], event.DstAddr[3])
 	dstAddr := net.IP(dst)
 
+	restricted := s.RestrictedNetworks.Denied(dstAddr)
+
+	// If network events are not being monitored, don't process the event,
+	// with the exception of connections to restricted destinations which
+	// are always audited.
+	_, ok = ctx.Events[teleport.EnhancedRecordingNetwork]
+	if !ok && !restricted {
+		return
+	}
+
+	code := events.SessionNetworkCode
+	if restricted {
+		code = events.SessionNetworkRestrictedCode
+	}
 	sessionNetworkEvent := &events.SessionNetwork{
 		Metadata: events.Metadata{
 			Type: events.SessionNetworkEvent,
-			Code: events.SessionNetworkCode,
+			Code: code,
 		},
 		ServerMetadata: events.ServerMetadata{
 			ServerID:        ctx.ServerID,
@@ -502,6 +766,60 @@ func (s *Service) emit6NetworkEvent(eventBytes []byte) {
 	if err := ctx.Emitter.EmitAuditEvent(ctx.Context, sessionNetworkEvent); err != nil {
 		log.WithError(err).Warn("Failed to emit network event.")
 	}
+	if ctx.Summary != nil {
+		ctx.Summary.AddNetwork(net.JoinHostPort(sessionNetworkEvent.DstAddr, strconv.Itoa(int(sessionNetworkEvent.DstPort))))
+	}
+}
+
+// emitUnixEvent will parse and emit Unix domain socket events to the
+// Audit Log.
+func (s *Service) emitUnixEvent(eventBytes []byte) {
+	// If network events have been disabled by the overhead watchdog, drop
+	// the event before doing any processing.
+	if atomic.LoadInt32(&s.dropNetwork) == 1 {
+		return
+	}
+
+	// Unmarshal raw event bytes.
+	var event rawConnUnixEvent
+	err := unmarshalEvent(eventBytes, &event)
+	if err != nil {
+		log.Debugf("Failed to read binary data: %v.", err)
+		return
+	}
+
+	// If the event comes from a unmonitored process/cgroup, don't process it.
+	ctx, ok := s.getWatch(event.CgroupID)
+	if !ok {
+		return
+	}
+
+	// If network events are not being monitored, don't process the event.
+	_, ok = ctx.Events[teleport.EnhancedRecordingNetwork]
+	if !ok {
+		return
+	}
+
+	// Abstract socket names start with a NUL byte, render them with the
+	// conventional "@" prefix.
+	path := convertString(unsafe.Pointer(&event.Path))
+	if path == "" && event.Path[1] != 0 {
+		path = "@" + convertString(unsafe.Pointer(&event.Path[1]))
+	}
+	if path == "" {
+		return
+	}
+
+	if err := s.AuditLog.EmitAuditEventLegacy(events.SessionUnixConnectE, events.EventFields{
+		events.SessionEventID: ctx.SessionID,
+		events.EventNamespace: ctx.Namespace,
+		events.EventUser:      ctx.User,
+		events.EventLogin:     ctx.Login,
+		events.PID:            uint64(event.PID),
+		events.Path:           path,
+	}); err != nil {
+		log.WithError(err).Warn("Failed to emit unix socket event.")
+	}
 }
 
 func (s *Service) getWatch(cgoupID uint64) (ctx *SessionContext, ok bool) {
@@ -1,4 +1,4 @@
-// +build !bpf 386
+// +build !bpf 386 arm
 
 /*
 Copyright 2019 Gravitational, Inc.
@@ -18,6 +18,10 @@ limitations under the License.
 
 package bpf
 
+import (
+	"github.com/gravitational/trace"
+)
+
 // Service is used on non-Linux systems as a NOP service that allows the
 // caller to open and close sessions that do nothing on systems that don't
 // support eBPF.
@@ -34,3 +38,9 @@ func New(config *Config) (BPF, error) {
 func SystemHasBPF() bool {
 	return false
 }
+
+// IsHostCompatible checks that BPF programs can run on this host. The
+// binary was built without BPF support, so they can not.
+func IsHostCompatible() error {
+	return trace.BadParameter("this binary is built without BPF support")
+}
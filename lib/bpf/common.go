@@ -16,23 +16,20 @@ limitations under the License.
 
 package bpf
 
-// #cgo LDFLAGS: -ldl
-// #include <dlfcn.h>
-// #include <stdlib.h>
-import "C"
-
 import (
 	"context"
-	"unsafe"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/gravitational/trace"
-
-	"github.com/coreos/go-semver/semver"
 )
 
 // BPF implements an interface to open and close a recording session.
@@ -81,6 +78,25 @@ type SessionContext struct {
 	// Events is the set of events (command, disk, or network) to record for
 	// this session.
 	Events map[string]bool
+
+	// BlockedWritePaths is the set of absolute path prefixes writes to
+	// which are restricted for this session. On kernels without BPF LSM
+	// support attempts are detected and audited rather than blocked.
+	BlockedWritePaths []string
+
+	// MemoryLimit is the number of megabytes of memory processes in this
+	// session may consume, applied to the session cgroup. Zero means no
+	// limit.
+	MemoryLimit int64
+
+	// CPULimit is the percentage of a single CPU core processes in this
+	// session may consume, applied to the session cgroup. Zero means no
+	// limit.
+	CPULimit int64
+
+	// Summary, when set, accumulates digests of the recorded events so a
+	// compact summary can be reported when the session closes.
+	Summary *Summary
 }
 
 // Config holds configuration for the BPF service.
@@ -99,6 +115,249 @@ type Config struct {
 
 	// CgroupPath is where the cgroupv2 hierarchy is mounted.
 	CgroupPath string
+
+	// CPULimit is the percentage of a single CPU core event processing may
+	// consume before enhanced recording degrades itself. Zero means no limit.
+	CPULimit int
+
+	// MemoryLimit is the number of megabytes of memory the process may
+	// consume before enhanced recording degrades itself. Zero means no limit.
+	MemoryLimit int
+
+	// ServerID is the UUID of the server, included in the audit event
+	// emitted when enhanced recording degrades itself.
+	ServerID string
+
+	// AuditLog is used to emit an audit event when enhanced recording
+	// degrades itself.
+	AuditLog events.IAuditLog
+
+	// RestrictedNetworks is the parsed cluster-wide network restrictions
+	// applied to all recorded sessions on this node. Nil means no
+	// restrictions. On kernels without BPF LSM support connections to
+	// denied destinations are detected and audited rather than blocked.
+	RestrictedNetworks *RestrictedNetworks
+
+	// CaptureEnv enables recording of the environment variables programs
+	// within a session were executed with.
+	CaptureEnv bool
+
+	// EnvAllowlist is the list of variable names to capture, all variables
+	// are captured when the list is empty.
+	EnvAllowlist []string
+
+	// EnvDenylist is the list of variable names to never capture,
+	// evaluated before the allowlist.
+	EnvDenylist []string
+}
+
+// CaptureEnvironment reads the environment of a process from procfs and
+// filters it by the allowlist and denylist of variable names. Best-effort:
+// a short-lived process may be gone before the exec event is processed, in
+// which case nil is returned.
+func CaptureEnvironment(pid uint64, allow []string, deny []string) []string {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%v/environ", pid))
+	if err != nil {
+		return nil
+	}
+	return FilterEnvironment(strings.Split(string(data), "\x00"), allow, deny)
+}
+
+// FilterEnvironment returns the environment entries whose variable names
+// pass the denylist and then the allowlist, an empty allowlist passes all
+// names.
+func FilterEnvironment(environ []string, allow []string, deny []string) []string {
+	var out []string
+	for _, entry := range environ {
+		name := entry
+		if index := strings.IndexByte(entry, '='); index >= 0 {
+			name = entry[:index]
+		}
+		if name == "" {
+			continue
+		}
+		if utils.SliceContainsStr(deny, name) {
+			continue
+		}
+		if len(allow) != 0 && !utils.SliceContainsStr(allow, name) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// shellNames is the list of process names commonly used for interactive
+// shells. An exec of one of these with a socket on standard input and
+// output is the canonical reverse shell pattern.
+var shellNames = []string{
+	"sh", "bash", "dash", "ash", "zsh", "ksh", "csh", "tcsh",
+}
+
+// isShell returns true if path names a program commonly used as an
+// interactive shell.
+func isShell(path string) bool {
+	return utils.SliceContainsStr(shellNames, filepath.Base(path))
+}
+
+// stdioIsSocket returns true if both standard input and standard output of
+// the process are connected to sockets. Best-effort: a short-lived process
+// may be gone before its file descriptors can be read from procfs, in
+// which case false is returned.
+func stdioIsSocket(pid uint64) bool {
+	for _, fd := range []string{"0", "1"} {
+		target, err := os.Readlink(fmt.Sprintf("/proc/%v/fd/%v", pid, fd))
+		if err != nil || !strings.HasPrefix(target, "socket:") {
+			return false
+		}
+	}
+	return true
+}
+
+// RestrictedNetworks holds the parsed allow and deny CIDR lists of the
+// cluster-wide network restrictions resource. Deny blocks are evaluated
+// before allow blocks and an empty allow list permits all destinations.
+type RestrictedNetworks struct {
+	// Allow is the list of allowed CIDR blocks.
+	Allow []*net.IPNet
+
+	// Deny is the list of denied CIDR blocks.
+	Deny []*net.IPNet
+}
+
+// ParseRestrictedNetworks parses the allow and deny CIDR lists of the
+// network restrictions resource.
+func ParseRestrictedNetworks(allow []string, deny []string) (*RestrictedNetworks, error) {
+	var restricted RestrictedNetworks
+	for _, block := range allow {
+		_, ipnet, err := net.ParseCIDR(block)
+		if err != nil {
+			return nil, trace.BadParameter("invalid CIDR block %q: %v", block, err)
+		}
+		restricted.Allow = append(restricted.Allow, ipnet)
+	}
+	for _, block := range deny {
+		_, ipnet, err := net.ParseCIDR(block)
+		if err != nil {
+			return nil, trace.BadParameter("invalid CIDR block %q: %v", block, err)
+		}
+		restricted.Deny = append(restricted.Deny, ipnet)
+	}
+	return &restricted, nil
+}
+
+// Denied returns true if connections to the given destination are denied
+// by the restrictions.
+func (n *RestrictedNetworks) Denied(ip net.IP) bool {
+	if n == nil {
+		return false
+	}
+	for _, block := range n.Deny {
+		if block.Contains(ip) {
+			return true
+		}
+	}
+	if len(n.Allow) == 0 {
+		return false
+	}
+	for _, block := range n.Allow {
+		if block.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ConfigOverride is a set of enhanced recording settings the auth server
+// pushes to nodes whose static labels match the selector, so
+// high-sensitivity hosts can get stricter capture without per-host file
+// edits. Nil fields leave the node's own configuration untouched.
+type ConfigOverride struct {
+	// Match is a set of node labels the override applies to, all listed
+	// labels have to match. An empty selector matches every node.
+	Match map[string]string `json:"match,omitempty"`
+
+	// Enabled turns enhanced recording on or off on matching nodes.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// CommandBufferSize overrides the perf buffer size for command events.
+	CommandBufferSize *int `json:"command_buffer_size,omitempty"`
+
+	// DiskBufferSize overrides the perf buffer size for disk events.
+	DiskBufferSize *int `json:"disk_buffer_size,omitempty"`
+
+	// NetworkBufferSize overrides the perf buffer size for network events.
+	NetworkBufferSize *int `json:"network_buffer_size,omitempty"`
+
+	// CPULimit overrides the CPU limit for event processing.
+	CPULimit *int `json:"cpu_limit,omitempty"`
+
+	// MemoryLimit overrides the memory limit for event processing.
+	MemoryLimit *int `json:"memory_limit,omitempty"`
+}
+
+// Matches returns true if all labels in the override selector are present
+// in the node labels with the same values.
+func (o *ConfigOverride) Matches(labels map[string]string) bool {
+	for key, value := range o.Match {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply copies the set fields of the override onto the node BPF
+// configuration.
+func (o *ConfigOverride) Apply(c *Config) {
+	if o.Enabled != nil {
+		c.Enabled = *o.Enabled
+	}
+	if o.CommandBufferSize != nil {
+		c.CommandBufferSize = o.CommandBufferSize
+	}
+	if o.DiskBufferSize != nil {
+		c.DiskBufferSize = o.DiskBufferSize
+	}
+	if o.NetworkBufferSize != nil {
+		c.NetworkBufferSize = o.NetworkBufferSize
+	}
+	if o.CPULimit != nil {
+		c.CPULimit = *o.CPULimit
+	}
+	if o.MemoryLimit != nil {
+		c.MemoryLimit = *o.MemoryLimit
+	}
+}
+
+// MergeOverrides evaluates the overrides in order against the node labels
+// and returns a single override, later matches win per field.
+func MergeOverrides(overrides []ConfigOverride, labels map[string]string) ConfigOverride {
+	var merged ConfigOverride
+	for _, o := range overrides {
+		if !o.Matches(labels) {
+			continue
+		}
+		if o.Enabled != nil {
+			merged.Enabled = o.Enabled
+		}
+		if o.CommandBufferSize != nil {
+			merged.CommandBufferSize = o.CommandBufferSize
+		}
+		if o.DiskBufferSize != nil {
+			merged.DiskBufferSize = o.DiskBufferSize
+		}
+		if o.NetworkBufferSize != nil {
+			merged.NetworkBufferSize = o.NetworkBufferSize
+		}
+		if o.CPULimit != nil {
+			merged.CPULimit = o.CPULimit
+		}
+		if o.MemoryLimit != nil {
+			merged.MemoryLimit = o.MemoryLimit
+		}
+	}
+	return merged
 }
 
 // CheckAndSetDefaults checks BPF configuration.
@@ -106,6 +365,12 @@ func (c *Config) CheckAndSetDefaults() error {
 	var perfBufferPageCount = defaults.PerfBufferPageCount
 	var openPerfBufferPageCount = defaults.OpenPerfBufferPageCount
 
+	if c.CPULimit < 0 {
+		return trace.BadParameter("cpu limit can not be negative")
+	}
+	if c.MemoryLimit < 0 {
+		return trace.BadParameter("memory limit can not be negative")
+	}
 	if c.CommandBufferSize == nil {
 		c.CommandBufferSize = &perfBufferPageCount
 	}
@@ -140,28 +405,3 @@ func (s *NOP) OpenSession(ctx *SessionContext) (uint64, error) {
 func (s *NOP) CloseSession(ctx *SessionContext) error {
 	return nil
 }
-
-// IsHostCompatible checks that BPF programs can run on this host.
-func IsHostCompatible() error {
-	// To find the cgroup ID of a program, bpf_get_current_cgroup_id is needed
-	// which was introduced in 4.18.
-	// https://github.com/torvalds/linux/commit/bf6fa2c893c5237b48569a13fa3c673041430b6c
-	minKernel := semver.New(teleport.EnhancedRecordingMinKernel)
-	version, err := utils.KernelVersion()
-	if err != nil {
-		return trace.Wrap(err)
-	}
-	if version.LessThan(*minKernel) {
-		return trace.BadParameter("incompatible kernel found, minimum supported kernel is %v", minKernel)
-	}
-
-	// Check that libbcc is on the system.
-	libraryName := C.CString("libbcc.so.0")
-	defer C.free(unsafe.Pointer(libraryName))
-	handle := C.dlopen(libraryName, C.RTLD_NOW)
-	if handle == nil {
-		return trace.BadParameter("libbcc.so not found")
-	}
-
-	return nil
-}
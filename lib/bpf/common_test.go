@@ -18,6 +18,7 @@ package bpf
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/utils"
@@ -85,3 +86,54 @@ func (s *CommonSuite) TestCheckAndSetDefaults(c *check.C) {
 		c.Assert(*tt.inConfig.NetworkBufferSize, check.Equals, *tt.outConfig.NetworkBufferSize)
 	}
 }
+
+// TestFilterEnvironment makes sure the denylist is evaluated before the
+// allowlist and that an empty allowlist passes all variable names.
+func (s *CommonSuite) TestFilterEnvironment(c *check.C) {
+	environ := []string{"PATH=/bin", "LD_PRELOAD=/tmp/evil.so", "SECRET=hunter2", ""}
+
+	// No filters: all non-empty entries pass.
+	c.Assert(FilterEnvironment(environ, nil, nil), check.DeepEquals,
+		[]string{"PATH=/bin", "LD_PRELOAD=/tmp/evil.so", "SECRET=hunter2"})
+
+	// Denylist drops matching names.
+	c.Assert(FilterEnvironment(environ, nil, []string{"SECRET"}), check.DeepEquals,
+		[]string{"PATH=/bin", "LD_PRELOAD=/tmp/evil.so"})
+
+	// Allowlist keeps only matching names, denylist wins over allowlist.
+	c.Assert(FilterEnvironment(environ, []string{"PATH", "SECRET"}, []string{"SECRET"}), check.DeepEquals,
+		[]string{"PATH=/bin"})
+}
+
+func (s *CommonSuite) TestIsShell(c *check.C) {
+	c.Assert(isShell("/bin/bash"), check.Equals, true)
+	c.Assert(isShell("sh"), check.Equals, true)
+	c.Assert(isShell("/usr/bin/zsh"), check.Equals, true)
+	c.Assert(isShell("/bin/ls"), check.Equals, false)
+	c.Assert(isShell("/opt/bashful"), check.Equals, false)
+}
+
+// TestRestrictedNetworks makes sure deny blocks are evaluated before allow
+// blocks and that an empty allow list permits all destinations.
+func (s *CommonSuite) TestRestrictedNetworks(c *check.C) {
+	// Invalid CIDR blocks are rejected.
+	_, err := ParseRestrictedNetworks(nil, []string{"not-a-cidr"})
+	c.Assert(err, check.NotNil)
+
+	// Nil restrictions deny nothing.
+	var nilRestricted *RestrictedNetworks
+	c.Assert(nilRestricted.Denied(net.ParseIP("10.0.0.1")), check.Equals, false)
+
+	// Deny list only: everything outside it is allowed.
+	restricted, err := ParseRestrictedNetworks(nil, []string{"10.0.0.0/8"})
+	c.Assert(err, check.IsNil)
+	c.Assert(restricted.Denied(net.ParseIP("10.1.2.3")), check.Equals, true)
+	c.Assert(restricted.Denied(net.ParseIP("192.168.0.1")), check.Equals, false)
+
+	// Allow list restricts everything else, deny wins over allow.
+	restricted, err = ParseRestrictedNetworks([]string{"10.0.0.0/8"}, []string{"10.2.0.0/16"})
+	c.Assert(err, check.IsNil)
+	c.Assert(restricted.Denied(net.ParseIP("10.1.2.3")), check.Equals, false)
+	c.Assert(restricted.Denied(net.ParseIP("10.2.2.3")), check.Equals, true)
+	c.Assert(restricted.Denied(net.ParseIP("192.168.0.1")), check.Equals, true)
+}
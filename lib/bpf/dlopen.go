@@ -0,0 +1,214 @@
+// +build bpf,!386,!arm
+
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bpf
+
+import "C"
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport"
+
+	"github.com/gravitational/trace"
+
+	"github.com/iovisor/gobpf/bcc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	lostLibraryEvents = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: teleport.MetricLostLibraryEvents,
+			Help: "Number of lost library load events.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(lostLibraryEvents)
+}
+
+// rawLibraryEvent is sent by the eBPF program that Teleport pulls off the
+// perf buffer.
+type rawLibraryEvent struct {
+	// CgroupID is the internal cgroupv2 ID of the event.
+	CgroupID uint64
+
+	// PID is the ID of the process.
+	PID uint64
+
+	// Handle is the handle returned by dlopen, zero when the load failed.
+	Handle uint64
+
+	// Command is name of the executable loading the library.
+	Command [commMax]byte
+
+	// Path is the path to the library being loaded.
+	Path [pathMax]byte
+
+	// Flags are the flags passed to dlopen.
+	Flags int32
+}
+
+// dlopen runs a BPF program that hooks dlopen with uprobes to observe
+// shared library loads within sessions.
+type dlopen struct {
+	closeContext context.Context
+
+	eventCh <-chan []byte
+	lostCh  <-chan uint64
+
+	perfMaps []*bcc.PerfMap
+	module   *bcc.Module
+}
+
+// startDlopen will compile, load, start, and pull events off the perf
+// buffer for the BPF program.
+func startDlopen(closeContext context.Context, pageCount int) (*dlopen, error) {
+	var err error
+
+	e := &dlopen{
+		closeContext: closeContext,
+	}
+
+	// If the page count is zero, don't start any BPF module.
+	if pageCount == 0 {
+		return e, nil
+	}
+
+	// Compile the BPF program.
+	e.module = bcc.NewModule(dlopenSource, []string{})
+	if e.module == nil {
+		return nil, trace.BadParameter("failed to load libbcc")
+	}
+
+	// Hook dlopen. In modern glibc dlopen lives in libc itself, in older
+	// glibc it lives in libdl, try both. Best-effort: statically linked or
+	// musl-based programs are not observable this way, library loads by
+	// the dynamic linker at exec are flagged through the environment of
+	// the exec event instead.
+	err = attachUprobe(e.module, "c", "dlopen", "trace_dlopen_entry", "trace_dlopen_return")
+	if err != nil {
+		err = attachUprobe(e.module, "dl", "dlopen", "trace_dlopen_entry", "trace_dlopen_return")
+	}
+	if err != nil {
+		log.Warningf("Failed to attach dlopen uprobes, library loads will not be audited: %v.", err)
+	}
+
+	// Open perf buffer and start processing library load events.
+	e.eventCh, e.lostCh, err = openPerfBuffer(e.module, e.perfMaps, pageCount, "library_events")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	// Start a loop that will emit lost events to prometheus.
+	go e.lostLoop()
+
+	return e, nil
+}
+
+// close will stop reading events off the perf buffer and unload the BPF
+// program.
+func (e *dlopen) close() {
+	for _, perfMap := range e.perfMaps {
+		perfMap.Stop()
+	}
+	e.module.Close()
+}
+
+// lostLoop keeps emitting the number of lost events to prometheus.
+func (e *dlopen) lostLoop() {
+	for {
+		select {
+		case n := <-e.lostCh:
+			log.Debugf("Lost %v library load events.", n)
+			lostLibraryEvents.Add(float64(n))
+		case <-e.closeContext.Done():
+			return
+		}
+	}
+}
+
+// events contains raw events off the perf buffer.
+func (e *dlopen) events() <-chan []byte {
+	return e.eventCh
+}
+
+const dlopenSource string = `
+#include <uapi/linux/ptrace.h>
+#include <uapi/linux/limits.h>
+#include <linux/sched.h>
+
+struct val_t {
+    u64 pid;
+    char comm[TASK_COMM_LEN];
+    const char *fname;
+    int flags;
+};
+
+struct data_t {
+    u64 cgroup;
+    u64 pid;
+    u64 handle;
+    char comm[TASK_COMM_LEN];
+    char fname[NAME_MAX];
+    int flags;
+};
+
+BPF_HASH(dltmp, u64, struct val_t);
+BPF_PERF_OUTPUT(library_events);
+
+int trace_dlopen_entry(struct pt_regs *ctx, const char __user *filename, int flags)
+{
+    struct val_t val = {};
+    u64 id = bpf_get_current_pid_tgid();
+
+    if (bpf_get_current_comm(&val.comm, sizeof(val.comm)) == 0) {
+        val.pid = id >> 32;
+        val.fname = filename;
+        val.flags = flags;
+        dltmp.update(&id, &val);
+    }
+
+    return 0;
+};
+
+int trace_dlopen_return(struct pt_regs *ctx)
+{
+    u64 id = bpf_get_current_pid_tgid();
+    struct val_t *valp;
+    struct data_t data = {};
+
+    valp = dltmp.lookup(&id);
+    if (valp == 0) {
+        // Missed entry.
+        return 0;
+    }
+    bpf_probe_read(&data.comm, sizeof(data.comm), valp->comm);
+    bpf_probe_read(&data.fname, sizeof(data.fname), (void *)valp->fname);
+    data.pid = valp->pid;
+    data.flags = valp->flags;
+    data.handle = PT_REGS_RC(ctx);
+    data.cgroup = bpf_get_current_cgroup_id();
+
+    library_events.perf_submit(ctx, &data, sizeof(data));
+    dltmp.delete(&id);
+
+    return 0;
+}`
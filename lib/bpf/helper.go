@@ -1,4 +1,4 @@
-// +build bpf,!386
+// +build bpf,!386,!arm
 
 /*
 Copyright 2019 Gravitational, Inc.
@@ -61,6 +61,32 @@ func attachRetProbe(module *bcc.Module, eventName string, functionName string) e
 	return nil
 }
 
+// attachUprobe will attach a uprobe and uretprobe to the given symbol in a
+// library.
+func attachUprobe(module *bcc.Module, library string, symbol string, entryFunction string, returnFunction string) error {
+	uprobe, err := module.LoadUprobe(entryFunction)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = module.AttachUprobe(library, symbol, uprobe, -1)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	uretprobe, err := module.LoadUprobe(returnFunction)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	err = module.AttachUretprobe(library, symbol, uretprobe, -1)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
 // openPerfBuffer will open a perf buffer for a particular module.
 func openPerfBuffer(module *bcc.Module, perfMaps []*bcc.PerfMap, pageCount int, name string) (<-chan []byte, <-chan uint64, error) {
 	var err error
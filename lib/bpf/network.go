@@ -1,4 +1,4 @@
-// +build bpf,!386
+// +build bpf,!386,!arm
 
 /*
 Copyright 2019 Gravitational, Inc.
@@ -94,6 +94,23 @@ type rawConn6Event struct {
 	Command [commMax]byte
 }
 
+// rawConnUnixEvent is sent by the eBPF program that Teleport pulls off the
+// perf buffer.
+type rawConnUnixEvent struct {
+	// CgroupID is the internal cgroupv2 ID of the event.
+	CgroupID uint64
+
+	// PID is the process ID.
+	PID uint32
+
+	// Command is name of the executable making the connection.
+	Command [commMax]byte
+
+	// Path is the filesystem path (or abstract name) of the socket the
+	// connection is being made to.
+	Path [pathMax]byte
+}
+
 type conn struct {
 	closeContext context.Context
 
@@ -102,11 +119,19 @@ type conn struct {
 	v4EventCh <-chan []byte
 	v6EventCh <-chan []byte
 
+	// unixEventCh is the channel upon which the perf buffer places
+	// AF_UNIX events.
+	unixEventCh <-chan []byte
+
 	// v{4,6}LostCh are the channels upon which the perf buffer places lost
 	// event count.
 	v4LostCh <-chan uint64
 	v6LostCh <-chan uint64
 
+	// unixLostCh is the channel upon which the perf buffer places lost
+	// AF_UNIX event count.
+	unixLostCh <-chan uint64
+
 	module   *bcc.Module
 	perfMaps []*bcc.PerfMap
 }
@@ -148,6 +173,19 @@ func startConn(closeContext context.Context, pageCount int) (*conn, error) {
 		return nil, trace.Wrap(err)
 	}
 
+	// Hook Unix domain socket connection attempts, both stream and
+	// datagram sockets go through the same tracing function. The socket
+	// address is only available on entry, so attempts are audited rather
+	// than established connections.
+	err = attachProbe(e.module, "unix_stream_connect", "trace_unix_connect")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	err = attachProbe(e.module, "unix_dgram_connect", "trace_unix_connect")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	// Open perf buffer and start processing IPv4 events.
 	e.v4EventCh, e.v4LostCh, err = openPerfBuffer(e.module, e.perfMaps, pageCount, "ipv4_events")
 	if err != nil {
@@ -160,6 +198,12 @@ func startConn(closeContext context.Context, pageCount int) (*conn, error) {
 		return nil, trace.Wrap(err)
 	}
 
+	// Open perf buffer and start processing Unix socket events.
+	e.unixEventCh, e.unixLostCh, err = openPerfBuffer(e.module, e.perfMaps, pageCount, "unix_events")
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
 	// Start a loop that will emit lost events to prometheus.
 	go e.lostLoop()
 
@@ -185,6 +229,9 @@ func (e *conn) lostLoop() {
 		case n := <-e.v6LostCh:
 			log.Debugf("Lost %v IPv6 events.", n)
 			lostNetworkEvents.Add(float64(n))
+		case n := <-e.unixLostCh:
+			log.Debugf("Lost %v Unix socket events.", n)
+			lostNetworkEvents.Add(float64(n))
 		case <-e.closeContext.Done():
 			return
 		}
@@ -201,9 +248,17 @@ func (e *conn) v6Events() <-chan []byte {
 	return e.v6EventCh
 }
 
+// unixEvents contains raw events off the perf buffer.
+func (e *conn) unixEvents() <-chan []byte {
+	return e.unixEventCh
+}
+
 const connSource string = `
 #include <uapi/linux/ptrace.h>
+#include <uapi/linux/limits.h>
+#include <linux/un.h>
 #include <net/sock.h>
+#include <net/af_unix.h>
 #include <bcc/proto.h>
 
 BPF_HASH(currsock, u32, struct sock *);
@@ -231,6 +286,32 @@ struct ipv6_data_t {
 };
 BPF_PERF_OUTPUT(ipv6_events);
 
+struct unix_data_t {
+    u64 cgroup;
+    u32 pid;
+    char task[TASK_COMM_LEN];
+    char path[NAME_MAX];
+};
+BPF_PERF_OUTPUT(unix_events);
+
+// trace_unix_connect is hooked on both unix_stream_connect and
+// unix_dgram_connect which share the same first arguments. The socket
+// address is only available on entry, so connection attempts are emitted.
+int trace_unix_connect(struct pt_regs *ctx, struct socket *sock, struct sockaddr *uaddr)
+{
+    struct sockaddr_un *sunaddr = (struct sockaddr_un *)uaddr;
+    struct unix_data_t data = {};
+
+    data.pid = bpf_get_current_pid_tgid();
+    data.cgroup = bpf_get_current_cgroup_id();
+    bpf_get_current_comm(&data.task, sizeof(data.task));
+    bpf_probe_read(&data.path, UNIX_PATH_MAX, sunaddr->sun_path);
+
+    unix_events.perf_submit(ctx, &data, sizeof(data));
+
+    return 0;
+}
+
 int trace_connect_entry(struct pt_regs *ctx, struct sock *sk)
 {
     u32 pid = bpf_get_current_pid_tgid();
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bpf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// summaryTopN is how many entries of each kind are included in the
+// rendered session summary.
+const summaryTopN = 5
+
+// Summary accumulates digests of the BPF events observed during a
+// session: executed programs, opened files and network destinations.
+// It lets reviewers triage a session without replaying the recording.
+type Summary struct {
+	mu       sync.Mutex
+	programs map[string]int
+	files    map[string]int
+	network  map[string]int
+}
+
+// NewSummary returns an empty session summary.
+func NewSummary() *Summary {
+	return &Summary{
+		programs: make(map[string]int),
+		files:    make(map[string]int),
+		network:  make(map[string]int),
+	}
+}
+
+// AddProgram counts an executed program.
+func (s *Summary) AddProgram(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.programs[path]++
+}
+
+// AddFile counts an opened file.
+func (s *Summary) AddFile(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path]++
+}
+
+// AddNetwork counts a network destination, e.g. "10.0.0.1:443".
+func (s *Summary) AddNetwork(destination string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.network[destination]++
+}
+
+// Empty returns true if no events were recorded.
+func (s *Summary) Empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.programs) == 0 && len(s.files) == 0 && len(s.network) == 0
+}
+
+// String renders a compact digest with counts and top-N lists, e.g.
+// "commands: 12 (top: curl:5, bash:3), files: 80, network: 2 (top: 10.0.0.1:443:2)".
+func (s *Summary) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parts := []string{
+		renderDigest("commands", s.programs),
+		renderDigest("files", s.files),
+		renderDigest("network", s.network),
+	}
+	return strings.Join(parts, ", ")
+}
+
+// renderDigest renders total count and top-N entries of a single counter
+func renderDigest(name string, counts map[string]int) string {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return fmt.Sprintf("%v: 0", name)
+	}
+	type entry struct {
+		name  string
+		count int
+	}
+	entries := make([]entry, 0, len(counts))
+	for name, count := range counts {
+		entries = append(entries, entry{name: name, count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].count != entries[j].count {
+			return entries[i].count > entries[j].count
+		}
+		return entries[i].name < entries[j].name
+	})
+	if len(entries) > summaryTopN {
+		entries = entries[:summaryTopN]
+	}
+	top := make([]string, 0, len(entries))
+	for _, e := range entries {
+		top = append(top, fmt.Sprintf("%v:%v", e.name, e.count))
+	}
+	return fmt.Sprintf("%v: %v (top: %v)", name, total, strings.Join(top, ", "))
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bpf
+
+import (
+	"fmt"
+
+	"gopkg.in/check.v1"
+)
+
+type SummarySuite struct{}
+
+var _ = check.Suite(&SummarySuite{})
+
+// TestSummaryDigest makes sure the session summary renders counts and
+// top-N lists sorted by frequency.
+func (s *SummarySuite) TestSummaryDigest(c *check.C) {
+	summary := NewSummary()
+	c.Assert(summary.Empty(), check.Equals, true)
+
+	for i := 0; i < 3; i++ {
+		summary.AddProgram("/bin/curl")
+	}
+	summary.AddProgram("/bin/ls")
+	summary.AddFile("/etc/passwd")
+	summary.AddNetwork("10.0.0.1:443")
+	summary.AddNetwork("10.0.0.1:443")
+
+	c.Assert(summary.Empty(), check.Equals, false)
+	c.Assert(summary.String(), check.Equals,
+		"commands: 4 (top: /bin/curl:3, /bin/ls:1), "+
+			"files: 1 (top: /etc/passwd:1), "+
+			"network: 2 (top: 10.0.0.1:443:2)")
+
+	// only the top entries are rendered
+	summary = NewSummary()
+	for i := 0; i < summaryTopN+2; i++ {
+		summary.AddFile(fmt.Sprintf("/tmp/file-%v", i))
+	}
+	c.Assert(summary.String(), check.Equals,
+		"commands: 0, files: 7 (top: /tmp/file-0:1, /tmp/file-1:1, /tmp/file-2:1, /tmp/file-3:1, /tmp/file-4:1), "+
+			"network: 0")
+}
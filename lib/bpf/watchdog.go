@@ -0,0 +1,173 @@
+// +build bpf,!386,!arm
+
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bpf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/events"
+
+	"github.com/gravitational/trace"
+)
+
+const (
+	// overheadCheckInterval is how often the watchdog samples the CPU and
+	// memory consumed by the process.
+	overheadCheckInterval = 10 * time.Second
+
+	// clockTicksPerSecond is USER_HZ, the unit /proc/self/stat reports CPU
+	// time in. It has been hardcoded to 100 on Linux for a long time.
+	clockTicksPerSecond = 100
+)
+
+// watchOverhead periodically samples the CPU and memory consumed by this
+// process and degrades enhanced session recording when the configured limits
+// are exceeded: disk events are disabled first, network events next. Command
+// events are never disabled, they are both the cheapest to process and the
+// most valuable. Each degradation emits an audit event so operators can see
+// why events stopped showing up for a node.
+func (s *Service) watchOverhead() {
+	ticker := time.NewTicker(overheadCheckInterval)
+	defer ticker.Stop()
+
+	lastCPU, err := processCPUTime()
+	if err != nil {
+		log.WithError(err).Warnf("Failed to read process CPU time, overhead limits will not be enforced.")
+		return
+	}
+	lastSample := time.Now()
+
+	for {
+		select {
+		case <-ticker.C:
+			cpu, err := processCPUTime()
+			if err != nil {
+				log.Debugf("Failed to read process CPU time: %v.", err)
+				continue
+			}
+			now := time.Now()
+			percent := 100 * float64(cpu-lastCPU) / float64(now.Sub(lastSample))
+			lastCPU = cpu
+			lastSample = now
+
+			if s.CPULimit > 0 && percent > float64(s.CPULimit) {
+				s.degrade(fmt.Sprintf("CPU usage %.1f%% is over the limit of %v%%", percent, s.CPULimit))
+				continue
+			}
+
+			memory, err := processResidentMemory()
+			if err != nil {
+				log.Debugf("Failed to read process memory usage: %v.", err)
+				continue
+			}
+			memoryMB := memory / 1024 / 1024
+			if s.MemoryLimit > 0 && memoryMB > int64(s.MemoryLimit) {
+				s.degrade(fmt.Sprintf("memory usage %v MB is over the limit of %v MB", memoryMB, s.MemoryLimit))
+			}
+		case <-s.closeContext.Done():
+			return
+		}
+	}
+}
+
+// degrade disables the next most expensive class of events: disk events
+// first, network events next. Command events are always kept.
+func (s *Service) degrade(reason string) {
+	var subsystem string
+	switch {
+	case atomic.CompareAndSwapInt32(&s.dropDisk, 0, 1):
+		subsystem = teleport.EnhancedRecordingDisk
+	case atomic.CompareAndSwapInt32(&s.dropNetwork, 0, 1):
+		subsystem = teleport.EnhancedRecordingNetwork
+	default:
+		// Everything that can be disabled already has been.
+		return
+	}
+	log.Warnf("Enhanced session recording overhead is too high (%v): disabling %v events.", reason, subsystem)
+
+	if s.AuditLog == nil {
+		return
+	}
+	err := s.AuditLog.EmitAuditEventLegacy(events.SessionRecordingDegradeE, events.EventFields{
+		events.SessionServerID:           s.ServerID,
+		events.SessionRecordingSubsystem: subsystem,
+		events.SessionRecordingReason:    reason,
+	})
+	if err != nil {
+		log.WithError(err).Warnf("Failed to emit session recording degrade event.")
+	}
+}
+
+// processCPUTime returns the total CPU time (user and system) consumed by
+// this process as reported by /proc/self/stat.
+func processCPUTime() (time.Duration, error) {
+	data, err := ioutil.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+
+	// The second field (the command) can contain spaces, skip past its
+	// closing parenthesis before splitting the rest.
+	i := strings.LastIndexByte(string(data), ')')
+	if i < 0 {
+		return 0, trace.BadParameter("failed to parse /proc/self/stat")
+	}
+	fields := strings.Fields(string(data[i+1:]))
+
+	// utime and stime are fields 14 and 15 of the full line; 2 fields have
+	// been consumed, leaving them at indexes 11 and 12.
+	if len(fields) < 13 {
+		return 0, trace.BadParameter("failed to parse /proc/self/stat")
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+
+	return time.Duration(utime+stime) * time.Second / clockTicksPerSecond, nil
+}
+
+// processResidentMemory returns the resident set size of this process in
+// bytes as reported by /proc/self/statm.
+func processResidentMemory() (int64, error) {
+	data, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0, trace.ConvertSystemError(err)
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0, trace.BadParameter("failed to parse /proc/self/statm")
+	}
+	pages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	return pages * int64(os.Getpagesize()), nil
+}
@@ -30,9 +30,23 @@ import (
 
 	"github.com/gravitational/trace"
 	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 )
 
+var cacheHealth = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: teleport.MetricCacheHealth,
+		Help: "Whether the cache is up to date (1) or degraded and possibly serving stale data (0)",
+	},
+	[]string{teleport.ComponentLabel},
+)
+
+func init() {
+	// Metrics have to be registered to be exposed:
+	prometheus.MustRegister(cacheHealth)
+}
+
 // ForAuth sets up watch configuration for the auth server
 func ForAuth(cfg Config) Config {
 	cfg.Watches = []services.WatchKind{
@@ -329,6 +343,9 @@ func (c *Cache) update(ctx context.Context) {
 		err := c.fetchAndWatch(ctx, retry)
 		if err != nil {
 			c.setCacheState(err)
+			// the cache may keep serving stale data in "prefer recent"
+			// mode, report degraded health so operators can tell
+			cacheHealth.WithLabelValues(c.Component).Set(0)
 			if !c.isClosed() {
 				c.Warningf("Re-init the cache on error: %v.", trace.Unwrap(err))
 			}
@@ -471,6 +488,7 @@ func (c *Cache) fetchAndWatch(ctx context.Context, retry utils.Retry) error {
 	}
 	retry.Reset()
 	c.wrapper.SetReadError(nil)
+	cacheHealth.WithLabelValues(c.Component).Set(1)
 	c.notify(Event{Type: WatcherStarted})
 	for {
 		select {
@@ -71,6 +71,12 @@ type Service struct {
 	// teleportRoot is the root cgroup that holds all Teleport sessions. Used
 	// to remove all cgroups upon shutdown.
 	teleportRoot string
+
+	// delegated is true when the session hierarchy lives inside a cgroup
+	// systemd delegated to this process. In that case the cgroup2
+	// filesystem is not mounted (or unmounted) by this service and the
+	// top-level layout is left to systemd.
+	delegated bool
 }
 
 // New creates a new cgroup service.
@@ -81,21 +87,108 @@ func New(config *Config) (*Service, error) {
 	}
 
 	s := &Service{
-		Config:       config,
-		teleportRoot: path.Join(config.MountPath, teleportRoot, uuid.New()),
+		Config: config,
 	}
 
-	// Mount the cgroup2 filesystem.
-	err = s.mount()
-	if err != nil {
-		return nil, trace.Wrap(err)
+	// When running under systemd with a delegated cgroup (Delegate=yes on
+	// the unit), create session cgroups inside the delegated hierarchy.
+	// systemd owns the top-level layout of the cgroup2 filesystem it
+	// mounted and will fight over (and eventually remove) cgroups written
+	// directly into the root hierarchy.
+	if delegatedRoot, ok := systemdDelegatedRoot(); ok {
+		s.delegated = true
+		s.teleportRoot = path.Join(delegatedRoot, teleportRoot, uuid.New())
+
+		err = os.MkdirAll(s.teleportRoot, fileMode)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		log.Debugf("Teleport session hierarchy created under systemd-delegated cgroup: %v.", s.teleportRoot)
+	} else {
+		s.teleportRoot = path.Join(config.MountPath, teleportRoot, uuid.New())
+
+		// Mount the cgroup2 filesystem.
+		err = s.mount()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+
+		log.Debugf("Teleport session hierarchy mounted at: %v.", s.teleportRoot)
 	}
 
-	log.Debugf("Teleport session hierarchy mounted at: %v.", s.teleportRoot)
+	// Make the memory and cpu controllers available to session cgroups so
+	// per-session limits can be applied.
+	s.enableControllers()
 
 	return s, nil
 }
 
+// systemdDelegatedRoot returns the cgroup of the current process when the
+// system was booted with systemd and the cgroup has been delegated to the
+// process, in which case session cgroups have to be created inside it.
+func systemdDelegatedRoot() (string, bool) {
+	// Only relevant when the system was booted with systemd.
+	if _, err := os.Stat(systemdRuntimeDir); err != nil {
+		return "", false
+	}
+
+	data, err := ioutil.ReadFile(procSelfCgroup)
+	if err != nil {
+		return "", false
+	}
+
+	// The cgroupv2 entry in /proc/self/cgroup has the format
+	// "0::/system.slice/teleport.service".
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, cgroup2Entry) {
+			continue
+		}
+		relPath := strings.TrimPrefix(line, cgroup2Entry)
+		if relPath == "/" {
+			return "", false
+		}
+
+		// systemd mounts the cgroup2 filesystem at a fixed location. The
+		// unit cgroup is only usable when systemd delegated it to the
+		// process ("Delegate=yes" on the unit), in which case it is
+		// writable.
+		root := path.Join(systemdCgroupPath, relPath)
+		if unix.Access(root, unix.W_OK) != nil {
+			return "", false
+		}
+		return root, true
+	}
+
+	return "", false
+}
+
+// enableControllers enables the memory and cpu controllers at each level of
+// the hierarchy between the mount point (or delegated cgroup) and the
+// session cgroups so they are available to them. Best-effort: on kernels or
+// hosts where the controllers are unavailable session limits will not be
+// applied.
+func (s *Service) enableControllers() {
+	base := s.MountPath
+	if s.delegated {
+		base = systemdCgroupPath
+	}
+	rel, err := filepath.Rel(base, s.teleportRoot)
+	if err != nil {
+		log.Debugf("Failed to enable memory and cpu controllers: %v.", err)
+		return
+	}
+
+	level := base
+	for _, part := range append([]string{""}, strings.Split(rel, string(filepath.Separator))...) {
+		level = path.Join(level, part)
+		err := writeValue(path.Join(level, cgroupSubtreeControl), "+memory +cpu")
+		if err != nil {
+			log.Debugf("Failed to enable memory and cpu controllers in %v: %v.", level, err)
+		}
+	}
+}
+
 // Close will unmount the cgroup filesystem.
 func (s *Service) Close() error {
 	err := s.cleanupHierarchy()
@@ -103,6 +196,17 @@ func (s *Service) Close() error {
 		return trace.Wrap(err)
 	}
 
+	// When the hierarchy is delegated by systemd the cgroup2 filesystem
+	// was never mounted by this service, only remove the session root and
+	// leave the filesystem to systemd.
+	if s.delegated {
+		if err := unix.Rmdir(s.teleportRoot); err != nil {
+			log.Debugf("Failed to remove Teleport session hierarchy at %v: %v.", s.teleportRoot, err)
+		}
+		log.Debugf("Cleaned up Teleport session hierarchy at: %v.", s.teleportRoot)
+		return nil
+	}
+
 	err = s.unmount()
 	if err != nil {
 		return trace.Wrap(err)
@@ -130,9 +234,9 @@ func (s *Service) Remove(sessionID string) error {
 		return trace.Wrap(err)
 	}
 
-	// Move all PIDs to the root controller. This has to be done before a cgroup
-	// can be removed.
-	err = writePids(path.Join(s.MountPath, cgroupProcs), pids)
+	// Move all PIDs out of the session cgroup. This has to be done before a
+	// cgroup can be removed.
+	err = writePids(s.rootProcsFile(), pids)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -148,6 +252,89 @@ func (s *Service) Remove(sessionID string) error {
 	return nil
 }
 
+// rootProcsFile returns the procs file processes are moved to before a
+// session cgroup is removed: the root controller, or the delegated cgroup
+// when running under systemd delegation, the root controller belongs to
+// systemd then.
+func (s *Service) rootProcsFile() string {
+	if s.delegated {
+		// The delegated cgroup is two levels up from the session root
+		// ("teleport/<id>").
+		return path.Join(filepath.Dir(filepath.Dir(s.teleportRoot)), cgroupProcs)
+	}
+	return path.Join(s.MountPath, cgroupProcs)
+}
+
+// SetLimits applies memory and CPU limits to the cgroup for a session.
+// The memory limit is in megabytes and is written to "memory.max", the CPU
+// limit is in percent of a single core and is written to "cpu.max". A zero
+// limit leaves the corresponding controller unconstrained.
+func (s *Service) SetLimits(sessionID string, memoryLimit int64, cpuLimit int64) error {
+	if memoryLimit != 0 {
+		value := strconv.FormatInt(memoryLimit*1024*1024, 10)
+		err := writeValue(path.Join(s.teleportRoot, sessionID, memoryMax), value)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if cpuLimit != 0 {
+		// The format of "cpu.max" is "$MAX $PERIOD": processes in the
+		// cgroup may consume up to $MAX microseconds of CPU time out of
+		// every $PERIOD microseconds.
+		value := strconv.FormatInt(cpuLimit*cpuPeriod/100, 10) + " " + strconv.FormatInt(cpuPeriod, 10)
+		err := writeValue(path.Join(s.teleportRoot, sessionID, cpuMax), value)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// OOMKills returns the number of processes in the cgroup for a session that
+// have been killed by the kernel OOM killer, read from "memory.events".
+func (s *Service) OOMKills(sessionID string) (int64, error) {
+	f, err := os.Open(path.Join(s.teleportRoot, sessionID, memoryEvents))
+	if err != nil {
+		return 0, trace.Wrap(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != "oom_kill" {
+			continue
+		}
+		count, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, trace.Wrap(err)
+		}
+		return count, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, trace.Wrap(err)
+	}
+
+	// Older kernels do not have the "oom_kill" entry in "memory.events".
+	return 0, nil
+}
+
+// writeValue writes a single value to a cgroup control file.
+func writeValue(path string, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, fileMode)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(value)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
 // Place  place a process in the cgroup for that session.
 func (s *Service) Place(sessionID string, pid int) error {
 	// Open cgroup.procs file for the cgroup.
@@ -218,12 +405,17 @@ func (s *Service) cleanupHierarchy() error {
 			return nil
 		}
 
-		// Extract the session ID. Skip over cgroup.procs files not for sessions.
-		parts := strings.Split(path, string(filepath.Separator))
-		if len(parts) != 5 {
+		// Extract the session ID. Skip over cgroup.procs files not for
+		// sessions, session cgroups live directly below the session root.
+		rel, err := filepath.Rel(s.teleportRoot, path)
+		if err != nil {
 			return nil
 		}
-		sessionID := uuid.Parse(parts[3])
+		parts := strings.Split(rel, string(filepath.Separator))
+		if len(parts) != 2 {
+			return nil
+		}
+		sessionID := uuid.Parse(parts[0])
 		if sessionID == nil {
 			return nil
 		}
@@ -383,4 +575,38 @@ const (
 	// cgroupProcs is the name of the file that contains all processes within
 	// a cgroup.
 	cgroupProcs = "cgroup.procs"
+
+	// memoryMax is the name of the file that holds the memory usage hard
+	// limit of a cgroup.
+	memoryMax = "memory.max"
+
+	// memoryEvents is the name of the file that holds the memory event
+	// counters of a cgroup, including the number of OOM kills.
+	memoryEvents = "memory.events"
+
+	// cpuMax is the name of the file that holds the CPU bandwidth limit of
+	// a cgroup.
+	cpuMax = "cpu.max"
+
+	// cpuPeriod is the CPU bandwidth period in microseconds used when
+	// applying a CPU limit.
+	cpuPeriod = int64(100000)
+
+	// cgroupSubtreeControl is the name of the file that controls which
+	// controllers are available to the child cgroups.
+	cgroupSubtreeControl = "cgroup.subtree_control"
+
+	// systemdRuntimeDir only exists when the system was booted with
+	// systemd.
+	systemdRuntimeDir = "/run/systemd/system"
+
+	// systemdCgroupPath is where systemd mounts the cgroup2 filesystem.
+	systemdCgroupPath = "/sys/fs/cgroup"
+
+	// procSelfCgroup lists the cgroups of the current process.
+	procSelfCgroup = "/proc/self/cgroup"
+
+	// cgroup2Entry is the prefix of the cgroupv2 entry in
+	// /proc/self/cgroup.
+	cgroup2Entry = "0::"
 )
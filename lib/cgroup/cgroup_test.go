@@ -129,8 +129,8 @@ func (s *Suite) TestCleanup(c *check.C) {
 	// Make sure the cgroup no longer exists.
 	cgroupPath := path.Join(service.teleportRoot, sessionID)
 	_, err = os.Stat(cgroupPath)
-	if os.IsNotExist(err) {
-		c.Fatalf("Could not find cgroup file %v: %v.", cgroupPath, err)
+	if !os.IsNotExist(err) {
+		c.Fatalf("Found cgroup %v after cleanup: %v.", cgroupPath, err)
 	}
 }
 
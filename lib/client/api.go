@@ -55,6 +55,7 @@ import (
 	"github.com/gravitational/teleport/lib/sshutils/scp"
 	"github.com/gravitational/teleport/lib/utils"
 	"github.com/gravitational/teleport/lib/utils/agentconn"
+	"github.com/gravitational/teleport/lib/utils/proxy"
 	"github.com/gravitational/teleport/lib/wrappers"
 
 	"github.com/gravitational/trace"
@@ -137,6 +138,18 @@ type Config struct {
 	// Labels represent host Labels
 	Labels map[string]string
 
+	// PredicateExpression is an optional predicate expression used to filter
+	// node listings, e.g. `labels.env == "prod"`.
+	PredicateExpression string
+
+	// SearchKeywords is an optional list of fuzzy search keywords used to
+	// filter node listings.
+	SearchKeywords []string
+
+	// CommandConcurrency is the maximum number of nodes a command matched by
+	// labels is executed on at the same time. Zero means the default.
+	CommandConcurrency int
+
 	// Namespace is nodes namespace
 	Namespace string
 
@@ -265,6 +278,10 @@ type Config struct {
 	// similar) socket at $SSH_AUTH_SOCK.
 	UseLocalSSHAgent bool
 
+	// UseMemoryKeyStore will keep keys and certificates in memory instead of
+	// ~/.tsh, reusing them until they expire and never writing them to disk.
+	UseMemoryKeyStore bool
+
 	// EnableEscapeSequences will scan Stdin for SSH escape sequences during
 	// command/shell execution. This also requires Stdin to be an interactive
 	// terminal.
@@ -293,6 +310,12 @@ func MakeDefaultConfig() *Config {
 // ProfileStatus combines metadata from the logged in profile and associated
 // SSH certificate.
 type ProfileStatus struct {
+	// Name is the profile name.
+	Name string
+
+	// Dir is the directory where profile is located.
+	Dir string
+
 	// ProxyURL is the URL the web client is accessible at.
 	ProxyURL url.URL
 
@@ -320,6 +343,19 @@ type ProfileStatus struct {
 	// ActiveRequests tracks the privilege escalation requests applied
 	// during certificate construction.
 	ActiveRequests services.RequestIDs
+
+	// ValidAfter is the time at which the SSH certificate became valid.
+	ValidAfter time.Time
+
+	// Fingerprint is the SHA256 fingerprint of the certified public key.
+	Fingerprint string
+
+	// CriticalOptions are the critical options embedded in the SSH
+	// certificate, e.g. a source-address restriction.
+	CriticalOptions map[string]string
+
+	// TLSValidUntil is the time at which the TLS certificate will expire.
+	TLSValidUntil time.Time
 }
 
 // IsExpired returns true if profile is not expired yet
@@ -327,6 +363,27 @@ func (p *ProfileStatus) IsExpired(clock clockwork.Clock) bool {
 	return p.ValidUntil.Sub(clock.Now()) <= 0
 }
 
+// KeyPath returns path to the private key for the profile.
+//
+// It's kept in <profile-dir>/keys/<proxy>/<user>.
+func (p *ProfileStatus) KeyPath() string {
+	return filepath.Join(p.Dir, sessionKeyDir, p.Name, p.Username)
+}
+
+// CertPath returns path to the SSH certificate for the profile.
+//
+// It's kept in <profile-dir>/keys/<proxy>/<user>-cert.pub.
+func (p *ProfileStatus) CertPath() string {
+	return filepath.Join(p.Dir, sessionKeyDir, p.Name, p.Username+fileExtCert)
+}
+
+// KnownHostsPath returns path to the known hosts file used by the profile.
+//
+// It's kept in <profile-dir>/known_hosts.
+func (p *ProfileStatus) KnownHostsPath() string {
+	return filepath.Join(p.Dir, fileNameKnownHosts)
+}
+
 // RetryWithRelogin is a helper error handling method,
 // attempts to relogin and retry the function once
 func RetryWithRelogin(ctx context.Context, tc *TeleportClient, fn func() error) error {
@@ -368,6 +425,48 @@ func RetryWithRelogin(ctx context.Context, tc *TeleportClient, fn func() error)
 	return fn()
 }
 
+// ReloginIfExpiring checks how much time is left on the current user
+// certificate and, if it is below the supplied threshold (or the certificate
+// has already expired), runs the login sequence again so that long-running
+// commands start with a full TTL instead of getting cut short mid-session.
+// It is a no-op when local auth is skipped (identity files) or when there is
+// no local key yet, in which case the usual relogin-on-failure path applies.
+func (tc *TeleportClient) ReloginIfExpiring(ctx context.Context, threshold time.Duration) error {
+	if tc.SkipLocalAuth {
+		return nil
+	}
+	key, err := tc.LocalAgent().GetKey()
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil
+		}
+		return trace.Wrap(err)
+	}
+	cert, err := key.SSHCert()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	remaining := time.Until(time.Unix(int64(cert.ValidBefore), 0))
+	if remaining > threshold {
+		return nil
+	}
+	log.Infof("Credentials expire in %v, refreshing them before connecting.", remaining.Round(time.Second))
+	key, err = tc.Login(ctx, true)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := tc.SaveProfile("", true); err != nil {
+		return trace.Wrap(err)
+	}
+	authMethod, err := key.AsAuthMethod()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Make sure subsequent connections in this process use the fresh key.
+	tc.Config.AuthMethods = []ssh.AuthMethod{authMethod}
+	return nil
+}
+
 // readProfile reads in the profile as well as the associated certificate
 // and returns a *ProfileStatus which can be used to print the status of the
 // profile.
@@ -400,6 +499,14 @@ func readProfile(profileDir string, profileName string) (*ProfileStatus, error)
 
 	// Extract from the certificate how much longer it will be valid for.
 	validUntil := time.Unix(int64(cert.ValidBefore), 0)
+	validAfter := time.Unix(int64(cert.ValidAfter), 0)
+
+	// Extract the expiry of the TLS certificate as well, it can drift from
+	// the SSH certificate when certificates were issued by different tools.
+	var tlsValidUntil time.Time
+	if tlsCert, err := keys.TLSCertificate(); err == nil {
+		tlsValidUntil = tlsCert.NotAfter
+	}
 
 	// Extract roles from certificate. Note, if the certificate is in old format,
 	// this will be empty.
@@ -458,18 +565,24 @@ func readProfile(profileDir string, profileName string) (*ProfileStatus, error)
 	}
 
 	return &ProfileStatus{
+		Name: profileName,
+		Dir:  profileDir,
 		ProxyURL: url.URL{
 			Scheme: "https",
 			Host:   profile.WebProxyAddr,
 		},
-		Username:       profile.Username,
-		Logins:         cert.ValidPrincipals,
-		ValidUntil:     validUntil,
-		Extensions:     extensions,
-		Roles:          roles,
-		Cluster:        clusterName,
-		Traits:         traits,
-		ActiveRequests: activeRequests,
+		Username:        profile.Username,
+		Logins:          cert.ValidPrincipals,
+		ValidUntil:      validUntil,
+		Extensions:      extensions,
+		Roles:           roles,
+		Cluster:         clusterName,
+		Traits:          traits,
+		ActiveRequests:  activeRequests,
+		ValidAfter:      validAfter,
+		Fingerprint:     ssh.FingerprintSHA256(cert.Key),
+		CriticalOptions: cert.Permissions.CriticalOptions,
+		TLSValidUntil:   tlsValidUntil,
 	}, nil
 }
 
@@ -805,7 +918,11 @@ func NewClient(c *Config) (tc *TeleportClient, err error) {
 	} else {
 		// initialize the local agent (auth agent which uses local SSH keys signed by the CA):
 		webProxyHost, _ := tc.WebProxyHostPort()
-		tc.localAgent, err = NewLocalAgent(c.KeysDir, webProxyHost, c.Username, c.UseLocalSSHAgent)
+		if c.UseMemoryKeyStore {
+			tc.localAgent, err = NewMemLocalAgent(webProxyHost, c.Username, c.UseLocalSSHAgent)
+		} else {
+			tc.localAgent, err = NewLocalAgent(c.KeysDir, webProxyHost, c.Username, c.UseLocalSSHAgent)
+		}
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
@@ -888,6 +1005,17 @@ func (tc *TeleportClient) CreateAccessRequest(ctx context.Context, req services.
 	return proxyClient.CreateAccessRequest(ctx, req)
 }
 
+// ListUnifiedResources fetches the flattened listing of everything the user
+// can access in the current cluster (nodes, kubernetes clusters).
+func (tc *TeleportClient) ListUnifiedResources(ctx context.Context) ([]services.UnifiedResource, error) {
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	return proxyClient.ListUnifiedResources(ctx)
+}
+
 // GetAccessRequests loads all access requests matching the supplied filter.
 func (tc *TeleportClient) GetAccessRequests(ctx context.Context, filter services.AccessRequestFilter) ([]services.AccessRequest, error) {
 	proxyClient, err := tc.ConnectToProxy(ctx)
@@ -1096,42 +1224,12 @@ func (tc *TeleportClient) Join(ctx context.Context, namespace string, sessionID
 }
 
 // Play replays the recorded session
-func (tc *TeleportClient) Play(ctx context.Context, namespace, sessionID string) (err error) {
-	if namespace == "" {
-		return trace.BadParameter(auth.MissingNamespaceError)
-	}
-	sid, err := session.ParseID(sessionID)
-	if err != nil {
-		return fmt.Errorf("'%v' is not a valid session ID (must be GUID)", sid)
-	}
-	// connect to the auth server (site) who made the recording
-	proxyClient, err := tc.ConnectToProxy(ctx)
-	if err != nil {
-		return trace.Wrap(err)
-	}
-	site, err := proxyClient.ConnectToCurrentCluster(ctx, false)
-	if err != nil {
-		return trace.Wrap(err)
-	}
-	// request events for that session (to get timing data)
-	sessionEvents, err := site.GetSessionEvents(namespace, *sid, 0, true)
+func (tc *TeleportClient) Play(ctx context.Context, namespace, sessionID string, speed float64) (err error) {
+	sessionEvents, stream, err := tc.GetSessionRecording(ctx, namespace, sessionID)
 	if err != nil {
 		return trace.Wrap(err)
 	}
 
-	// read the stream into a buffer:
-	var stream []byte
-	for {
-		tmp, err := site.GetSessionChunk(namespace, *sid, len(stream), events.MaxChunkBytes)
-		if err != nil {
-			return trace.Wrap(err)
-		}
-		if len(tmp) == 0 {
-			break
-		}
-		stream = append(stream, tmp...)
-	}
-
 	// configure terminal for direct unbuffered echo-less input:
 	if term.IsTerminal(0) {
 		state, err := term.SetRawTerminal(0)
@@ -1140,7 +1238,7 @@ func (tc *TeleportClient) Play(ctx context.Context, namespace, sessionID string)
 		}
 		defer term.RestoreTerminal(0, state)
 	}
-	player := newSessionPlayer(sessionEvents, stream)
+	player := newSessionPlayer(sessionEvents, stream, speed)
 	// keys:
 	const (
 		keyCtrlC = 3
@@ -1186,6 +1284,66 @@ func (tc *TeleportClient) Play(ctx context.Context, namespace, sessionID string)
 	return trace.Wrap(err)
 }
 
+// GetSessionRecording returns the events and the raw terminal stream of a
+// recorded session.
+func (tc *TeleportClient) GetSessionRecording(ctx context.Context, namespace, sessionID string) ([]events.EventFields, []byte, error) {
+	if namespace == "" {
+		return nil, nil, trace.BadParameter(auth.MissingNamespaceError)
+	}
+	sid, err := session.ParseID(sessionID)
+	if err != nil {
+		return nil, nil, trace.BadParameter("'%v' is not a valid session ID (must be GUID)", sessionID)
+	}
+	// connect to the auth server (site) who made the recording
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	site, err := proxyClient.ConnectToCurrentCluster(ctx, false)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+	// request events for that session (to get timing data)
+	sessionEvents, err := site.GetSessionEvents(namespace, *sid, 0, true)
+	if err != nil {
+		return nil, nil, trace.Wrap(err)
+	}
+
+	// read the stream into a buffer:
+	var stream []byte
+	for {
+		tmp, err := site.GetSessionChunk(namespace, *sid, len(stream), events.MaxChunkBytes)
+		if err != nil {
+			return nil, nil, trace.Wrap(err)
+		}
+		if len(tmp) == 0 {
+			break
+		}
+		stream = append(stream, tmp...)
+	}
+	return sessionEvents, stream, nil
+}
+
+// ListActiveSessions returns the list of active sessions in the current
+// cluster along with their participants.
+func (tc *TeleportClient) ListActiveSessions(ctx context.Context) ([]session.Session, error) {
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+	site, err := proxyClient.ConnectToCurrentCluster(ctx, false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	sessions, err := site.GetSessions(tc.Namespace)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return sessions, nil
+}
+
 // ExecuteSCP executes SCP command. It executes scp.Command using
 // lower-level API integrations that mimic SCP CLI command behavior
 func (tc *TeleportClient) ExecuteSCP(ctx context.Context, cmd scp.Command) (err error) {
@@ -1239,7 +1397,7 @@ func (tc *TeleportClient) ExecuteSCP(ctx context.Context, cmd scp.Command) (err
 }
 
 // SCP securely copies file(s) from one SSH server to another
-func (tc *TeleportClient) SCP(ctx context.Context, args []string, port int, recursive bool, quiet bool) (err error) {
+func (tc *TeleportClient) SCP(ctx context.Context, args []string, port int, flags scp.Flags, quiet bool) (err error) {
 	if len(args) < 2 {
 		return trace.Errorf("Need at least two arguments for scp")
 	}
@@ -1254,7 +1412,7 @@ func (tc *TeleportClient) SCP(ctx context.Context, args []string, port int, recu
 	if !tc.Config.ProxySpecified() {
 		return trace.BadParameter("proxy server is not specified")
 	}
-	log.Infof("Connecting to proxy to copy (recursively=%v)...", recursive)
+	log.Infof("Connecting to proxy to copy (recursively=%v)...", flags.Recursive)
 	proxyClient, err := tc.ConnectToProxy(ctx)
 	if err != nil {
 		return trace.Wrap(err)
@@ -1313,15 +1471,14 @@ func (tc *TeleportClient) SCP(ctx context.Context, args []string, port int, recu
 
 		// copy everything except the last arg (that's destination)
 		for _, src := range filesToUpload {
+			srcFlags := flags
+			srcFlags.Target = []string{src}
+			srcFlags.DirectoryMode = directoryMode
 			scpConfig := scp.Config{
 				User:           tc.Username,
 				ProgressWriter: progressWriter,
 				RemoteLocation: dest.Path,
-				Flags: scp.Flags{
-					Target:        []string{src},
-					Recursive:     recursive,
-					DirectoryMode: directoryMode,
-				},
+				Flags:          srcFlags,
 			}
 
 			cmd, err := scp.CreateUploadCommand(scpConfig)
@@ -1350,12 +1507,11 @@ func (tc *TeleportClient) SCP(ctx context.Context, args []string, port int, recu
 		}
 		// copy everything except the last arg (that's destination)
 		for _, dest := range args[1:] {
+			destFlags := flags
+			destFlags.Target = []string{dest}
 			scpConfig := scp.Config{
-				User: tc.Username,
-				Flags: scp.Flags{
-					Recursive: recursive,
-					Target:    []string{dest},
-				},
+				User:           tc.Username,
+				Flags:          destFlags,
 				RemoteLocation: src.Path,
 				ProgressWriter: progressWriter,
 			}
@@ -1396,7 +1552,36 @@ func (tc *TeleportClient) ListNodes(ctx context.Context) ([]services.Server, err
 	}
 	defer proxyClient.Close()
 
-	return proxyClient.FindServersByLabels(ctx, tc.Namespace, tc.Labels)
+	nodes, err := proxyClient.FindServersByLabels(ctx, tc.Namespace, tc.Labels)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return tc.filterNodes(nodes)
+}
+
+// filterNodes applies the predicate expression and search keyword filters
+// to the list of nodes returned by the proxy.
+func (tc *TeleportClient) filterNodes(nodes []services.Server) ([]services.Server, error) {
+	if tc.PredicateExpression == "" && len(tc.SearchKeywords) == 0 {
+		return nodes, nil
+	}
+	var filtered []services.Server
+	for _, node := range nodes {
+		if tc.PredicateExpression != "" {
+			match, err := services.MatchServerQuery(node, tc.PredicateExpression)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			if !match {
+				continue
+			}
+		}
+		if len(tc.SearchKeywords) != 0 && !services.MatchServerSearch(node, tc.SearchKeywords) {
+			continue
+		}
+		filtered = append(filtered, node)
+	}
+	return filtered, nil
 }
 
 // ListAllNodes is the same as ListNodes except that it ignores labels.
@@ -1414,9 +1599,18 @@ func (tc *TeleportClient) ListAllNodes(ctx context.Context) ([]services.Server,
 func (tc *TeleportClient) runCommandOnNodes(
 	ctx context.Context, siteName string, nodeAddresses []string, proxyClient *ProxyClient, command []string) error {
 
+	concurrency := tc.Config.CommandConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultCommandConcurrency
+	}
+	// semaphore caps how many nodes are being executed on at the same time.
+	semaphore := make(chan struct{}, concurrency)
 	resultsC := make(chan error, len(nodeAddresses))
 	for _, address := range nodeAddresses {
 		go func(address string) {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
 			var err error
 			defer func() {
 				resultsC <- err
@@ -1433,23 +1627,42 @@ func (tc *TeleportClient) runCommandOnNodes(
 			}
 			defer nodeClient.Close()
 
-			fmt.Printf("Running command on %v:\n", address)
-			err = tc.runCommand(ctx, nodeClient, command)
+			// Prefix the interleaved output of the nodes with the node
+			// address so the lines can be told apart.
+			stdout := newLabeledWriter(tc.Stdout, nodeName(address))
+			stderr := newLabeledWriter(tc.Stderr, nodeName(address))
+			err = tc.runCommandWithOutput(ctx, nodeClient, command, stdout, stderr)
+			stdout.Flush()
+			stderr.Flush()
 			// err is passed to resultsC in the defer above.
 		}(address)
 	}
-	var lastError error
+	var errors []error
 	for range nodeAddresses {
 		if err := <-resultsC; err != nil {
-			lastError = err
+			errors = append(errors, err)
+		}
+	}
+	if len(errors) != 0 {
+		// make sure the aggregated run is reported as failed even if the
+		// individual exit statuses got lost in translation
+		if tc.ExitStatus == 0 {
+			tc.ExitStatus = 1
 		}
+		return trace.NewAggregate(errors...)
 	}
-	return trace.Wrap(lastError)
+	return nil
 }
 
 // runCommand executes a given bash command on an established NodeClient.
 func (tc *TeleportClient) runCommand(ctx context.Context, nodeClient *NodeClient, command []string) error {
-	nodeSession, err := newSession(nodeClient, nil, tc.Config.Env, tc.Stdin, tc.Stdout, tc.Stderr, tc.useLegacyID(nodeClient), tc.EnableEscapeSequences)
+	return tc.runCommandWithOutput(ctx, nodeClient, command, tc.Stdout, tc.Stderr)
+}
+
+// runCommandWithOutput executes a given bash command on an established
+// NodeClient, sending the command output to the given writers.
+func (tc *TeleportClient) runCommandWithOutput(ctx context.Context, nodeClient *NodeClient, command []string, stdout, stderr io.Writer) error {
+	nodeSession, err := newSession(nodeClient, nil, tc.Config.Env, tc.Stdin, stdout, stderr, tc.useLegacyID(nodeClient), tc.EnableEscapeSequences)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -1582,6 +1795,9 @@ func (tc *TeleportClient) connectToProxy(ctx context.Context) (*ProxyClient, err
 		}
 	}
 	successMsg := fmt.Sprintf("Successful auth with proxy %v", sshProxyAddr)
+	// Dial through an outbound HTTP or SOCKS5 proxy if one is set in the
+	// environment, otherwise connect to the Teleport proxy directly.
+	dialer := proxy.DialerFromEnvironment(sshProxyAddr)
 	var err error
 	// try to authenticate using every non interactive auth method we have:
 	for i, m := range tc.authMethods() {
@@ -1589,7 +1805,7 @@ func (tc *TeleportClient) connectToProxy(ctx context.Context) (*ProxyClient, err
 		var sshClient *ssh.Client
 
 		sshConfig.Auth = []ssh.AuthMethod{m}
-		sshClient, err = ssh.Dial("tcp", sshProxyAddr, sshConfig)
+		sshClient, err = dialer.Dial("tcp", sshProxyAddr, sshConfig)
 		if err != nil {
 			log.Warningf("Failed to authenticate with proxy: %v", err)
 			err = trace.BadParameter("failed to authenticate with proxy %v: %v", sshProxyAddr, err)
@@ -2043,6 +2259,13 @@ func (tc *TeleportClient) directLogin(ctx context.Context, secondFactorType stri
 	return response, trace.Wrap(err)
 }
 
+// StartAccountRecovery exchanges a one-time recovery code for a reset
+// password token via the proxy.
+func (tc *TeleportClient) StartAccountRecovery(ctx context.Context, user string, recoveryCode string) (*AccountRecoveryResponse, error) {
+	response, err := StartAccountRecovery(ctx, tc.WebProxyAddr, tc.InsecureSkipVerify, loopbackPool(tc.WebProxyAddr), user, recoveryCode)
+	return response, trace.Wrap(err)
+}
+
 // samlLogin opens browser window and uses OIDC or SAML redirect cycle with browser
 func (tc *TeleportClient) ssoLogin(ctx context.Context, connectorID string, pub []byte, protocol string) (*auth.SSHLoginResponse, error) {
 	log.Debugf("samlLogin start")
@@ -2182,6 +2405,17 @@ func (tc *TeleportClient) AskOTP() (token string, err error) {
 	return token, nil
 }
 
+// AskRecoveryCode prompts the user to enter a one-time recovery code.
+func (tc *TeleportClient) AskRecoveryCode() (code string, err error) {
+	fmt.Printf("Enter a recovery code for Teleport user %v:\n", tc.Config.Username)
+	code, err = passwordFromConsole()
+	if err != nil {
+		fmt.Fprintln(tc.Stderr, err)
+		return "", trace.Wrap(err)
+	}
+	return code, nil
+}
+
 // AskPassword prompts the user to enter the password
 func (tc *TeleportClient) AskPassword() (pwd string, err error) {
 	fmt.Printf("Enter password for Teleport user %v:\n", tc.Config.Username)
@@ -2317,6 +2551,45 @@ func ParseLabelSpec(spec string) (map[string]string, error) {
 	return labels, nil
 }
 
+// ParseSearchKeywords parses a string ("foo,bar","baz") into a list of
+// search keywords, trimming whitespace and stripping quotes. Phrases
+// enclosed in quotations are treated as a single keyword.
+func ParseSearchKeywords(spec string, customDelimiter rune) []string {
+	delimiter := customDelimiter
+	if delimiter == 0 {
+		delimiter = rune(',')
+	}
+
+	var keywords []string
+	var openQuotes = false
+	var tokenStart int
+	specLen := len(spec)
+	// tokenize the label search:
+	for i, ch := range spec {
+		endOfToken := false
+		if i+utf8.RuneLen(ch) == specLen {
+			i += utf8.RuneLen(ch)
+			endOfToken = true
+		}
+		switch ch {
+		case '"':
+			openQuotes = !openQuotes
+		case delimiter:
+			if !openQuotes {
+				endOfToken = true
+			}
+		}
+		if endOfToken && i > tokenStart {
+			keyword := strings.TrimSpace(strings.Trim(spec[tokenStart:i], `"`))
+			if keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+			tokenStart = i + 1
+		}
+	}
+	return keywords
+}
+
 // Executes the given command on the client machine (localhost). If no command is given,
 // executes shell
 func runLocalCommand(command []string) error {
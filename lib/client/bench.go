@@ -19,8 +19,11 @@ package client
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -54,6 +57,39 @@ type BenchmarkResult struct {
 	LastError error
 }
 
+// ExportLatencyProfile exports the benchmark latency histogram in the
+// format expected by the HdrHistogram plotter
+// (https://hdrhistogram.github.io/HdrHistogram/plotFiles.html) to a
+// timestamped file in the given directory and returns the file name.
+func ExportLatencyProfile(path string, h *hdrhistogram.Histogram) (string, error) {
+	timestamp := time.Now().Format("2006-01-02_15:04:05")
+	fileName := filepath.Join(path, fmt.Sprintf("latency_profile_%v.txt", timestamp))
+	fo, err := os.Create(fileName)
+	if err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%12v %14v %10v %14v\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)")
+	for _, bracket := range h.CumulativeDistribution() {
+		inverted := "inf"
+		if bracket.Quantile < 100 {
+			inverted = fmt.Sprintf("%14.2f", 100/(100-bracket.Quantile))
+		}
+		fmt.Fprintf(&buf, "%12v %14.6f %10v %14v\n",
+			bracket.ValueAt, bracket.Quantile/100, bracket.Count, inverted)
+	}
+
+	if _, err := fo.Write(buf.Bytes()); err != nil {
+		fo.Close()
+		return "", trace.ConvertSystemError(err)
+	}
+	if err := fo.Close(); err != nil {
+		return "", trace.ConvertSystemError(err)
+	}
+	return fileName, nil
+}
+
 // Benchmark connects to remote server and executes requests in parallel according
 // to benchmark spec. It returns benchmark result when completed.
 // This is a blocking function that can be cancelled via context argument.
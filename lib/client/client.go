@@ -178,6 +178,28 @@ func (proxy *ProxyClient) ReissueUserCerts(ctx context.Context, params ReissuePa
 	return trace.Wrap(err)
 }
 
+// ListUnifiedResources fetches the complete unified resource listing for
+// the current cluster, following pagination.
+func (proxy *ProxyClient) ListUnifiedResources(ctx context.Context) ([]services.UnifiedResource, error) {
+	site, err := proxy.ConnectToCurrentCluster(ctx, false)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var resources []services.UnifiedResource
+	var startKey string
+	for {
+		page, err := site.ListUnifiedResources(startKey, 0)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, page.Resources...)
+		if page.NextKey == "" {
+			return resources, nil
+		}
+		startKey = page.NextKey
+	}
+}
+
 // CreateAccessRequest registers a new access request with the auth server.
 func (proxy *ProxyClient) CreateAccessRequest(ctx context.Context, req services.AccessRequest) error {
 	site, err := proxy.ConnectToCurrentCluster(ctx, false)
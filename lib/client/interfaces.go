@@ -27,7 +27,6 @@ import (
 
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib/auth"
-	"github.com/gravitational/teleport/lib/auth/native"
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/sshutils"
 	"github.com/gravitational/teleport/lib/tlsca"
@@ -63,8 +62,11 @@ type Key struct {
 
 // NewKey generates a new unsigned key. Such key must be signed by a
 // Teleport CA (auth server) before it becomes useful.
+//
+// The key is produced by the installed session key generator, which
+// defaults to software RSA keys but may be backed by a hardware token.
 func NewKey() (key *Key, err error) {
-	priv, pub, err := native.GenerateKeyPair("")
+	priv, pub, err := getSessionKeyGenerator()()
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
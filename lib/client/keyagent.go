@@ -68,12 +68,24 @@ type LocalKeyAgent struct {
 
 // NewLocalAgent reads all Teleport certificates from disk (using FSLocalKeyStore),
 // creates a LocalKeyAgent, loads all certificates into it, and returns the agent.
-func NewLocalAgent(keyDir, proxyHost, username string, useLocalSSHAgent bool) (a *LocalKeyAgent, err error) {
+func NewLocalAgent(keyDir, proxyHost, username string, useLocalSSHAgent bool) (*LocalKeyAgent, error) {
 	keystore, err := NewFSLocalKeyStore(keyDir)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	return newLocalAgent(keystore, proxyHost, username, useLocalSSHAgent)
+}
+
+// NewMemLocalAgent is like NewLocalAgent, but keys and certificates are held
+// in memory only: they are reused until they expire and are never written
+// to disk.
+func NewMemLocalAgent(proxyHost, username string, useLocalSSHAgent bool) (*LocalKeyAgent, error) {
+	return newLocalAgent(NewMemLocalKeyStore(), proxyHost, username, useLocalSSHAgent)
+}
 
+// newLocalAgent creates a LocalKeyAgent on top of the supplied storage
+// backend, loads the user's certificates into it, and returns the agent.
+func newLocalAgent(keystore LocalKeyStore, proxyHost, username string, useLocalSSHAgent bool) (a *LocalKeyAgent, err error) {
 	a = &LocalKeyAgent{
 		log: logrus.WithFields(logrus.Fields{
 			trace.Component: teleport.ComponentKeyAgent,
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sync"
+
+	"github.com/gravitational/teleport/lib/auth/native"
+)
+
+// SessionKeyGenerator produces the private key material for a login session.
+// It returns the private key in PEM encoding and the corresponding public
+// key in SSH authorized_keys format.
+//
+// The default implementation generates RSA keys in software. Builds with
+// hardware key support (e.g. PIV smart cards) can install a generator whose
+// private key bytes reference a key held on the token instead of containing
+// the key itself; such keys never leave the hardware.
+type SessionKeyGenerator func() (priv []byte, pub []byte, err error)
+
+var (
+	keygenMutex sync.RWMutex
+
+	// sessionKeyGenerator generates software RSA keys unless overridden
+	// with SetSessionKeyGenerator.
+	sessionKeyGenerator SessionKeyGenerator = func() ([]byte, []byte, error) {
+		return native.GenerateKeyPair("")
+	}
+)
+
+// SetSessionKeyGenerator overrides the generator used to produce private
+// keys for login sessions. It is used by builds with hardware key (PIV)
+// support and must be called before any login attempt.
+func SetSessionKeyGenerator(generator SessionKeyGenerator) {
+	keygenMutex.Lock()
+	defer keygenMutex.Unlock()
+	sessionKeyGenerator = generator
+}
+
+// getSessionKeyGenerator returns the installed session key generator.
+func getSessionKeyGenerator() SessionKeyGenerator {
+	keygenMutex.RLock()
+	defer keygenMutex.RUnlock()
+	return sessionKeyGenerator
+}
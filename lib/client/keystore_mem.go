@@ -0,0 +1,194 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/sshutils"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// MemLocalKeyStore is an in-memory implementation of LocalKeyStore. It is
+// used when keys and certificates should never touch disk, for example
+// short-lived certificates issued per target. Keys are held for the
+// lifetime of the process and evicted once their certificate expires, so
+// callers transparently fall back to reissuing instead of presenting a
+// certificate that will be rejected.
+type MemLocalKeyStore struct {
+	// log holds the structured logger.
+	log *logrus.Entry
+
+	sync.Mutex
+	// keys maps proxy host and username to a session key.
+	keys map[string]*Key
+	// knownHosts maps hostname to trusted host keys.
+	knownHosts map[string][]ssh.PublicKey
+	// certsPEM maps proxy host to trusted certificate authority PEM blocks.
+	certsPEM map[string][][]byte
+}
+
+// NewMemLocalKeyStore creates a new in-memory local keystore object.
+func NewMemLocalKeyStore() *MemLocalKeyStore {
+	return &MemLocalKeyStore{
+		log: logrus.WithFields(logrus.Fields{
+			trace.Component: teleport.ComponentKeyStore,
+		}),
+		keys:       make(map[string]*Key),
+		knownHosts: make(map[string][]ssh.PublicKey),
+		certsPEM:   make(map[string][][]byte),
+	}
+}
+
+// memKeyID returns the map key a session key is stored under.
+func memKeyID(proxyHost, username string) string {
+	return proxyHost + "/" + username
+}
+
+// AddKey adds a new key to the store. If a key for the host is already
+// stored, overwrites it.
+func (m *MemLocalKeyStore) AddKey(host, username string, key *Key) error {
+	m.Lock()
+	defer m.Unlock()
+	m.keys[memKeyID(host, username)] = key
+	return nil
+}
+
+// GetKey returns a key for a given host. If the key is not found or its
+// certificate has expired, returns trace.NotFound error.
+func (m *MemLocalKeyStore) GetKey(proxyHost string, username string) (*Key, error) {
+	m.Lock()
+	defer m.Unlock()
+	key, ok := m.keys[memKeyID(proxyHost, username)]
+	if !ok {
+		return nil, trace.NotFound("no session keys for %v in %v", username, proxyHost)
+	}
+	validBefore, err := key.CertValidBefore()
+	if err == nil && time.Now().After(validBefore) {
+		delete(m.keys, memKeyID(proxyHost, username))
+		return nil, trace.NotFound("session key for %v in %v has expired", username, proxyHost)
+	}
+	return key, nil
+}
+
+// DeleteKey removes a specific session key.
+func (m *MemLocalKeyStore) DeleteKey(host string, username string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.keys[memKeyID(host, username)]; !ok {
+		return trace.NotFound("no session keys for %v in %v", username, host)
+	}
+	delete(m.keys, memKeyID(host, username))
+	return nil
+}
+
+// DeleteKeys removes all session keys and trusted certificates.
+func (m *MemLocalKeyStore) DeleteKeys() error {
+	m.Lock()
+	defer m.Unlock()
+	m.keys = make(map[string]*Key)
+	m.certsPEM = make(map[string][][]byte)
+	return nil
+}
+
+// AddKnownHostKeys adds the public keys to the list of known hosts for
+// a hostname.
+func (m *MemLocalKeyStore) AddKnownHostKeys(hostname string, hostKeys []ssh.PublicKey) error {
+	m.Lock()
+	defer m.Unlock()
+	for i := range hostKeys {
+		duplicate := false
+		for _, existing := range m.knownHosts[hostname] {
+			if sshutils.KeysEqual(hostKeys[i], existing) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			m.log.Debugf("Adding known host %s with key: %v", hostname, sshutils.Fingerprint(hostKeys[i]))
+			m.knownHosts[hostname] = append(m.knownHosts[hostname], hostKeys[i])
+		}
+	}
+	return nil
+}
+
+// GetKnownHostKeys returns all public keys for a hostname, or all known
+// public keys if hostname is empty.
+func (m *MemLocalKeyStore) GetKnownHostKeys(hostname string) ([]ssh.PublicKey, error) {
+	m.Lock()
+	defer m.Unlock()
+	if hostname != "" {
+		return m.knownHosts[hostname], nil
+	}
+	retval := make([]ssh.PublicKey, 0)
+	for _, hostKeys := range m.knownHosts {
+		retval = append(retval, hostKeys...)
+	}
+	return retval, nil
+}
+
+// SaveCerts saves trusted TLS certificates of certificate authorities.
+func (m *MemLocalKeyStore) SaveCerts(proxy string, cas []auth.TrustedCerts) error {
+	m.Lock()
+	defer m.Unlock()
+	var blocks [][]byte
+	for _, ca := range cas {
+		blocks = append(blocks, ca.TLSCertificates...)
+	}
+	m.certsPEM[proxy] = blocks
+	return nil
+}
+
+// GetCertsPEM returns trusted TLS certificates of certificate authorities
+// PEM blocks.
+func (m *MemLocalKeyStore) GetCertsPEM(proxy string) ([][]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.certsPEM[proxy], nil
+}
+
+// GetCerts returns trusted TLS certificates of certificate authorities as
+// x509.CertPool.
+func (m *MemLocalKeyStore) GetCerts(proxy string) (*x509.CertPool, error) {
+	blocks, err := m.GetCertsPEM(proxy)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	pool := x509.NewCertPool()
+	for _, bytes := range blocks {
+		block, _ := pem.Decode(bytes)
+		if block == nil {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, trace.BadParameter("failed to parse certificate: %v", err)
+		}
+		m.log.Debugf("Adding trusted cluster certificate authority %q to trusted pool.", cert.Issuer)
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
@@ -145,6 +145,35 @@ func (s *KeyStoreTestSuite) TestKeyCRUD(c *check.C) {
 	c.Assert(trace.IsNotFound(err), check.Equals, true)
 }
 
+func (s *KeyStoreTestSuite) TestMemKeyCRUD(c *check.C) {
+	store := NewMemLocalKeyStore()
+	key := s.makeSignedKey(c, false)
+
+	// add key:
+	err := store.AddKey("host.a", "bob", key)
+	c.Assert(err, check.IsNil)
+
+	// load back and compare:
+	keyCopy, err := store.GetKey("host.a", "bob")
+	c.Assert(err, check.IsNil)
+	c.Assert(key.EqualsTo(keyCopy), check.Equals, true)
+
+	// expired certificates are evicted on read:
+	expiredKey := s.makeSignedKey(c, true)
+	err = store.AddKey("host.b", "bob", expiredKey)
+	c.Assert(err, check.IsNil)
+	_, err = store.GetKey("host.b", "bob")
+	c.Assert(err, check.NotNil)
+	c.Assert(trace.IsNotFound(err), check.Equals, true)
+
+	// delete & verify that it's gone:
+	err = store.DeleteKey("host.a", "bob")
+	c.Assert(err, check.IsNil)
+	_, err = store.GetKey("host.a", "bob")
+	c.Assert(err, check.NotNil)
+	c.Assert(trace.IsNotFound(err), check.Equals, true)
+}
+
 func (s *KeyStoreTestSuite) TestDeleteAll(c *check.C) {
 	key := s.makeSignedKey(c, false)
 
@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// defaultCommandConcurrency caps how many nodes a command matched by labels
+// is executed on at the same time, unless overridden in the config.
+const defaultCommandConcurrency = 10
+
+// labeledWriter prepends "[label] " to every line written through it, which
+// lets the interleaved output of commands running on multiple nodes be
+// attributed to the node that produced it.
+type labeledWriter struct {
+	w     io.Writer
+	label string
+
+	mtx    sync.Mutex
+	buffer []byte
+}
+
+func newLabeledWriter(w io.Writer, label string) *labeledWriter {
+	return &labeledWriter{w: w, label: label}
+}
+
+// Write buffers incoming bytes and emits them to the underlying writer one
+// complete prefixed line at a time.
+func (l *labeledWriter) Write(p []byte) (int, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.buffer = append(l.buffer, p...)
+	for {
+		i := bytes.IndexByte(l.buffer, '\n')
+		if i < 0 {
+			break
+		}
+		line := bytes.TrimRight(l.buffer[:i], "\r")
+		if _, err := fmt.Fprintf(l.w, "[%v] %s\n", l.label, line); err != nil {
+			return len(p), err
+		}
+		l.buffer = l.buffer[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits any remaining partial line to the underlying writer.
+func (l *labeledWriter) Flush() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if len(l.buffer) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(l.w, "[%v] %s\n", l.label, l.buffer)
+	l.buffer = nil
+	return err
+}
@@ -40,6 +40,10 @@ type sessionPlayer struct {
 	stream        []byte
 	sessionEvents []events.EventFields
 
+	// speed is a playback speed multiplier: 2 plays the session back twice
+	// as fast, 0.5 at half speed.
+	speed float64
+
 	state    int
 	position int
 
@@ -47,10 +51,14 @@ type sessionPlayer struct {
 	stopC chan int
 }
 
-func newSessionPlayer(sessionEvents []events.EventFields, stream []byte) *sessionPlayer {
+func newSessionPlayer(sessionEvents []events.EventFields, stream []byte, speed float64) *sessionPlayer {
+	if speed <= 0 {
+		speed = 1
+	}
 	return &sessionPlayer{
 		stream:        stream,
 		sessionEvents: sessionEvents,
+		speed:         speed,
 		stopC:         make(chan int),
 	}
 }
@@ -157,6 +165,8 @@ func (p *sessionPlayer) playRange(from, to int) {
 		// before "from"? play that instantly:
 		if i >= from {
 			delay := ms - prev
+			// scale the delay by playback speed before smoothing
+			delay = time.Duration(float64(delay) / p.speed)
 			// make playback smoother:
 			if delay < 10 {
 				delay = 0
@@ -488,6 +488,44 @@ func SSHAgentLogin(ctx context.Context, login SSHLoginDirect) (*auth.SSHLoginRes
 	return out, nil
 }
 
+// AccountRecoveryResponse is returned by the proxy when a recovery code is
+// accepted and carries the reset token the user completes recovery with.
+type AccountRecoveryResponse struct {
+	// TokenID is the name of the issued reset password token.
+	TokenID string `json:"tokenId"`
+	// User is the user the token was issued for.
+	User string `json:"user"`
+	// Expiry is the time when the token expires.
+	Expiry time.Time `json:"expiry"`
+}
+
+// StartAccountRecovery is used by tsh to exchange a one-time recovery code
+// for a reset password token.
+func StartAccountRecovery(ctx context.Context, proxyAddr string, insecure bool, pool *x509.CertPool, user string, recoveryCode string) (*AccountRecoveryResponse, error) {
+	clt, _, err := initClient(proxyAddr, insecure, pool)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	re, err := clt.PostJSON(ctx, clt.Endpoint("webapi", "recovery", "start"), struct {
+		User         string `json:"user"`
+		RecoveryCode string `json:"recovery_code"`
+	}{
+		User:         user,
+		RecoveryCode: recoveryCode,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	var out *AccountRecoveryResponse
+	if err := json.Unmarshal(re.Bytes(), &out); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	return out, nil
+}
+
 // SSHAgentU2FLogin requests a U2F sign request (authentication challenge) via
 // the proxy. If the credentials are valid, the proxy wiil return a challenge.
 // We then call the official u2f-host binary to perform the signing and pass
@@ -16,8 +16,8 @@ limitations under the License.
 
 // Package 'config' provides facilities for configuring Teleport daemons
 // including
-//	- parsing YAML configuration
-//	- parsing CLI flags
+//   - parsing YAML configuration
+//   - parsing CLI flags
 package config
 
 import (
@@ -27,23 +27,28 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 	"unicode"
 
 	"golang.org/x/crypto/ssh"
 
+	"github.com/coreos/go-semver/semver"
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib"
 	"github.com/gravitational/teleport/lib/backend"
 	"github.com/gravitational/teleport/lib/backend/lite"
 	"github.com/gravitational/teleport/lib/client"
 	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/limiter"
 	"github.com/gravitational/teleport/lib/pam"
 	"github.com/gravitational/teleport/lib/service"
 	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/regular"
 	"github.com/gravitational/teleport/lib/utils"
 	"github.com/gravitational/trace"
 
@@ -150,6 +155,40 @@ func ReadResources(filePath string) ([]services.Resource, error) {
 	return resources, nil
 }
 
+// ReadBootstrapResources reads resources to bootstrap the cluster with from
+// the supplied path, which is either a single file or a directory of YAML or
+// JSON resource files applied in lexical order.
+func ReadBootstrapResources(path string) ([]services.Resource, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if !fi.IsDir() {
+		return ReadResources(path)
+	}
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	var resources []services.Resource
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		read, err := ReadResources(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		resources = append(resources, read...)
+	}
+	return resources, nil
+}
+
 // ApplyFileConfig applies configuration from a YAML file to Teleport
 // runtime config
 func ApplyFileConfig(fc *FileConfig, cfg *service.Config) error {
@@ -291,9 +330,15 @@ func ApplyFileConfig(fc *FileConfig, cfg *service.Config) error {
 		cfg.CASignatureAlgorithm = fc.CASignatureAlgorithm
 	}
 
-	// Read in how nodes will validate the CA.
-	if fc.CAPin != "" {
-		cfg.CAPin = fc.CAPin
+	// Read in how nodes will validate the CA. Like the join token, each
+	// pin may be an absolute path or an env: reference, so secrets can
+	// stay out of the configuration file.
+	for _, pin := range fc.CAPin {
+		pin, err := utils.ReadToken(pin)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		cfg.CAPins = append(cfg.CAPins, pin)
 	}
 
 	// apply connection throttling:
@@ -316,6 +361,15 @@ func ApplyFileConfig(fc *FileConfig, cfg *service.Config) error {
 				Burst:   rate.Burst,
 			})
 		}
+		if len(fc.Limits.Allowlist) != 0 {
+			if _, err := limiter.ParseAllowlist(fc.Limits.Allowlist); err != nil {
+				return trace.Wrap(err)
+			}
+			l.Allowlist = fc.Limits.Allowlist
+		}
+		if fc.Limits.TrustedHeader != "" {
+			l.TrustedHeader = fc.Limits.TrustedHeader
+		}
 	}
 
 	// Apply configuration for "auth_service", "proxy_service", and
@@ -446,6 +500,64 @@ func applyAuthConfig(fc *FileConfig, cfg *service.Config) error {
 		return trace.Wrap(err)
 	}
 
+	if fc.Auth.Notifications != nil {
+		if fc.Auth.Notifications.Webhook != nil {
+			if fc.Auth.Notifications.Webhook.URL == "" {
+				return trace.BadParameter("notifications.webhook.url is required")
+			}
+			cfg.Auth.WebhookURL = fc.Auth.Notifications.Webhook.URL
+			cfg.Auth.WebhookEvents = fc.Auth.Notifications.Webhook.Events
+			cfg.Auth.WebhookFormat = fc.Auth.Notifications.Webhook.Format
+		}
+		if len(fc.Auth.Notifications.Alerts) != 0 {
+			if cfg.Auth.WebhookURL == "" {
+				return trace.BadParameter("notifications.alerts require notifications.webhook to be configured")
+			}
+			for _, alert := range fc.Auth.Notifications.Alerts {
+				cfg.Auth.AuditAlerts = append(cfg.Auth.AuditAlerts, events.AlertRule{
+					Name:  alert.Name,
+					Where: alert.Where,
+				})
+			}
+		}
+	}
+
+	for _, rule := range fc.Auth.AuditRedaction {
+		if rule.Pattern == "" {
+			return trace.BadParameter("audit_redaction rule %q is missing a pattern", rule.Name)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return trace.BadParameter("audit_redaction rule %q failed to parse: %v", rule.Name, err)
+		}
+		cfg.Auth.AuditRedaction = append(cfg.Auth.AuditRedaction, events.RedactionRule{
+			Name:    rule.Name,
+			Pattern: rule.Pattern,
+			Fields:  rule.Fields,
+			Replace: rule.Replace,
+		})
+	}
+
+	cfg.Auth.DisallowRootLogins = fc.Auth.DisallowRootLogins.Value()
+
+	for _, override := range fc.Auth.BPFOverrides {
+		cfg.Auth.BPFOverrides = append(cfg.Auth.BPFOverrides, override.Parse())
+	}
+
+	if fc.Auth.MinClientVersion != "" {
+		if _, err := semver.NewVersion(fc.Auth.MinClientVersion); err != nil {
+			return trace.BadParameter("min_client_version must be a semver version, e.g. 4.2.0, got %q", fc.Auth.MinClientVersion)
+		}
+		cfg.Auth.MinClientVersion = fc.Auth.MinClientVersion
+	}
+	switch fc.Auth.VersionCheckMode {
+	case "":
+	case teleport.VersionCheckModeEnforce, teleport.VersionCheckModeWarn:
+		cfg.Auth.VersionCheckMode = fc.Auth.VersionCheckMode
+	default:
+		return trace.BadParameter("version_check_mode must be one of %q or %q, got %q",
+			teleport.VersionCheckModeEnforce, teleport.VersionCheckModeWarn, fc.Auth.VersionCheckMode)
+	}
+
 	// read in and set the license file path (not used in open-source version)
 	licenseFile := fc.Auth.LicenseFile
 	if licenseFile != "" {
@@ -467,6 +579,10 @@ func applyProxyConfig(fc *FileConfig, cfg *service.Config) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	cfg.Proxy.ClientCertAuth, err = utils.ParseOnOff("client_cert_auth", fc.Proxy.ClientCertAuth, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
 	if fc.Proxy.ListenAddress != "" {
 		addr, err := utils.ParseHostPortAddr(fc.Proxy.ListenAddress, int(defaults.SSHProxyListenPort))
 		if err != nil {
@@ -575,6 +691,24 @@ func applyProxyConfig(fc *FileConfig, cfg *service.Config) error {
 		}
 		cfg.Proxy.TunnelPublicAddrs = addrs
 	}
+	for _, template := range fc.Proxy.HostTemplates {
+		if template.Template == "" {
+			return trace.BadParameter("host template is missing the template expression")
+		}
+		if template.Host == "" && template.Cluster == "" && len(template.Labels) == 0 {
+			return trace.BadParameter("host template %q needs at least one of host, cluster or labels", template.Template)
+		}
+		expression, err := regexp.Compile(template.Template)
+		if err != nil {
+			return trace.BadParameter("failed to parse host template %q: %v", template.Template, err)
+		}
+		cfg.Proxy.HostTemplates = append(cfg.Proxy.HostTemplates, regular.HostTemplate{
+			Template: expression,
+			Host:     template.Host,
+			Cluster:  template.Cluster,
+			Labels:   template.Labels,
+		})
+	}
 
 	return nil
 
@@ -611,6 +745,9 @@ func applySSHConfig(fc *FileConfig, cfg *service.Config) error {
 	if fc.SSH.PermitUserEnvironment {
 		cfg.SSH.PermitUserEnvironment = true
 	}
+	if fc.SSH.DisconnectOnRoleChange {
+		cfg.SSH.DisconnectOnRoleChange = true
+	}
 	if fc.SSH.PAM != nil {
 		cfg.SSH.PAM = fc.SSH.PAM.Parse()
 
@@ -642,6 +779,15 @@ func applySSHConfig(fc *FileConfig, cfg *service.Config) error {
 	if fc.SSH.BPF != nil {
 		cfg.SSH.BPF = fc.SSH.BPF.Parse()
 	}
+	cfg.SSH.AdditionalPrincipals = append(cfg.SSH.AdditionalPrincipals, fc.SSH.AdditionalPrincipals...)
+	if len(fc.SSH.AdditionalPrincipalsCommand) != 0 {
+		command := fc.SSH.AdditionalPrincipalsCommand
+		out, err := exec.Command(command[0], command[1:]...).Output()
+		if err != nil {
+			return trace.Wrap(err, "failed to run additional_principals_command %v", command)
+		}
+		cfg.SSH.AdditionalPrincipals = append(cfg.SSH.AdditionalPrincipals, strings.Fields(string(out))...)
+	}
 
 	return nil
 }
@@ -845,7 +991,7 @@ func Configure(clf *CommandLineFlags, cfg *service.Config) error {
 	}
 
 	if clf.BootstrapFile != "" {
-		resources, err := ReadResources(clf.BootstrapFile)
+		resources, err := ReadBootstrapResources(clf.BootstrapFile)
 		if err != nil {
 			return trace.Wrap(err)
 		}
@@ -974,7 +1120,14 @@ func Configure(clf *CommandLineFlags, cfg *service.Config) error {
 
 	// Apply flags used for the node to validate the Auth Server.
 	if clf.CAPin != "" {
-		cfg.CAPin = clf.CAPin
+		cfg.CAPins = nil
+		for _, pin := range strings.Split(clf.CAPin, ",") {
+			pin, err := utils.ReadToken(strings.TrimSpace(pin))
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			cfg.CAPins = append(cfg.CAPins, pin)
+		}
 	}
 
 	// apply --listen-ip flag:
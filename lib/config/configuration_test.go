@@ -729,6 +729,28 @@ ssh_service:
 	}
 }
 
+// TestAdditionalPrincipals makes sure the extra host principals configured
+// statically and via a command both end up in the service configuration.
+func (s *ConfigTestSuite) TestAdditionalPrincipals(c *check.C) {
+	conf := `
+ssh_service:
+  additional_principals: ["cname.example.com", "lb.example.com"]
+  additional_principals_command: ["/bin/echo", "metadata.example.com"]
+`
+	clf := CommandLineFlags{
+		ConfigString: base64.StdEncoding.EncodeToString([]byte(conf)),
+	}
+	cfg := service.MakeDefaultConfig()
+
+	err := Configure(&clf, cfg)
+	c.Assert(err, check.IsNil)
+	c.Assert(cfg.SSH.AdditionalPrincipals, check.DeepEquals, []string{
+		"cname.example.com",
+		"lb.example.com",
+		"metadata.example.com",
+	})
+}
+
 // TestDebugFlag ensures that the debug command-line flag is correctly set in the config.
 func (s *ConfigTestSuite) TestDebugFlag(c *check.C) {
 	clf := CommandLineFlags{
@@ -47,125 +47,6 @@ const (
 	randomTokenLenBytes = 24
 )
 
-var (
-	// all possible valid YAML config keys
-	// true  = has sub-keys
-	// false = does not have sub-keys (a leaf)
-	validKeys = map[string]bool{
-		"proxy_protocol":          false,
-		"namespace":               true,
-		"cluster_name":            true,
-		"trusted_clusters":        true,
-		"pid_file":                true,
-		"cert_file":               true,
-		"private_key_file":        true,
-		"cert":                    true,
-		"private_key":             true,
-		"checking_keys":           true,
-		"checking_key_files":      true,
-		"signing_keys":            true,
-		"signing_key_files":       true,
-		"allowed_logins":          true,
-		"teleport":                true,
-		"enabled":                 true,
-		"ssh_service":             true,
-		"proxy_service":           true,
-		"auth_service":            true,
-		"kubernetes":              true,
-		"kubeconfig_file":         true,
-		"auth_token":              true,
-		"auth_servers":            true,
-		"domain_name":             true,
-		"storage":                 false,
-		"nodename":                true,
-		"log":                     true,
-		"period":                  true,
-		"connection_limits":       true,
-		"max_connections":         true,
-		"max_users":               true,
-		"rates":                   true,
-		"commands":                true,
-		"labels":                  false,
-		"output":                  true,
-		"severity":                true,
-		"role":                    true,
-		"name":                    true,
-		"type":                    true,
-		"data_dir":                true,
-		"web_listen_addr":         true,
-		"tunnel_listen_addr":      true,
-		"ssh_listen_addr":         true,
-		"listen_addr":             true,
-		"ca_cert_file":            false,
-		"https_key_file":          true,
-		"https_cert_file":         true,
-		"advertise_ip":            true,
-		"authorities":             true,
-		"keys":                    true,
-		"reverse_tunnels":         true,
-		"addresses":               true,
-		"oidc_connectors":         true,
-		"id":                      true,
-		"issuer_url":              true,
-		"client_id":               true,
-		"client_secret":           true,
-		"redirect_url":            true,
-		"acr_values":              true,
-		"provider":                true,
-		"tokens":                  true,
-		"region":                  true,
-		"table_name":              true,
-		"access_key":              true,
-		"secret_key":              true,
-		"u2f":                     true,
-		"app_id":                  true,
-		"facets":                  true,
-		"authentication":          true,
-		"second_factor":           false,
-		"oidc":                    true,
-		"display":                 false,
-		"scope":                   false,
-		"claims_to_roles":         true,
-		"dynamic_config":          false,
-		"seed_config":             false,
-		"public_addr":             false,
-		"ssh_public_addr":         false,
-		"tunnel_public_addr":      false,
-		"cache":                   true,
-		"ttl":                     false,
-		"issuer":                  false,
-		"permit_user_env":         false,
-		"ciphers":                 false,
-		"kex_algos":               false,
-		"mac_algos":               false,
-		"ca_signature_algo":       false,
-		"connector_name":          false,
-		"session_recording":       false,
-		"read_capacity_units":     false,
-		"write_capacity_units":    false,
-		"license_file":            false,
-		"proxy_checks_host_keys":  false,
-		"audit_table_name":        false,
-		"audit_sessions_uri":      false,
-		"audit_events_uri":        false,
-		"pam":                     true,
-		"service_name":            false,
-		"client_idle_timeout":     false,
-		"session_control_timeout": false,
-		"disconnect_expired_cert": false,
-		"ciphersuites":            false,
-		"ca_pin":                  false,
-		"keep_alive_interval":     false,
-		"keep_alive_count_max":    false,
-		"local_auth":              false,
-		"enhanced_recording":      false,
-		"command_buffer_size":     false,
-		"disk_buffer_size":        false,
-		"network_buffer_size":     false,
-		"cgroup_path":             false,
-	}
-)
-
 var validCASigAlgos = []string{
 	ssh.SigAlgoRSA,
 	ssh.SigAlgoRSASHA2256,
@@ -183,8 +64,6 @@ type FileConfig struct {
 	Proxy  Proxy `yaml:"proxy_service,omitempty"`
 }
 
-type YAMLMap map[interface{}]interface{}
-
 // ReadFromFile reads Teleport configuration from a file. Currently only YAML
 // format is supported
 func ReadFromFile(filePath string) (*FileConfig, error) {
@@ -214,7 +93,10 @@ func ReadConfig(reader io.Reader) (*FileConfig, error) {
 		return nil, trace.Wrap(err, "failed reading Teleport configuration")
 	}
 	var fc FileConfig
-	if err = yaml.Unmarshal(bytes, &fc); err != nil {
+	// decode strictly so that a misspelled or misplaced key is reported
+	// with the line it appears on and the section it does not belong to,
+	// instead of being silently dropped
+	if err = yaml.UnmarshalStrict(bytes, &fc); err != nil {
 		return nil, trace.BadParameter("failed to parse Teleport configuration: %v", err)
 	}
 	// don't start Teleport with invalid ciphers, kex algorithms, or mac algorithms.
@@ -222,35 +104,6 @@ func ReadConfig(reader io.Reader) (*FileConfig, error) {
 	if err != nil {
 		return nil, trace.BadParameter("failed to parse Teleport configuration: %v", err)
 	}
-	// now check for unknown (misspelled) config keys:
-	var validateKeys func(m YAMLMap) error
-	validateKeys = func(m YAMLMap) error {
-		var recursive, ok bool
-		var key string
-		for k, v := range m {
-			if key, ok = k.(string); ok {
-				if recursive, ok = validKeys[key]; !ok {
-					return trace.BadParameter("unrecognized configuration key: '%v'", key)
-				}
-				if recursive {
-					if m2, ok := v.(YAMLMap); ok {
-						if err := validateKeys(m2); err != nil {
-							return err
-						}
-					}
-				}
-			}
-		}
-		return nil
-	}
-	// validate configuration keys:
-	var tmp YAMLMap
-	if err = yaml.Unmarshal(bytes, &tmp); err != nil {
-		return nil, trace.BadParameter("error parsing YAML config")
-	}
-	if err = validateKeys(tmp); err != nil {
-		return nil, trace.Wrap(err)
-	}
 	return &fc, nil
 }
 
@@ -269,7 +122,7 @@ func MakeSampleFileConfig() (fc *FileConfig, err error) {
 	var g Global
 	g.NodeName = conf.Hostname
 	g.AuthToken = randomJoinToken
-	g.CAPin = "sha256:ca-pin-hash-goes-here"
+	g.CAPin = []string{"sha256:ca-pin-hash-goes-here"}
 	g.Logger.Output = "stderr"
 	g.Logger.Severity = "INFO"
 	g.AuthServers = []string{fmt.Sprintf("%s:%d", defaults.Localhost, defaults.AuthListenPort)}
@@ -368,6 +221,12 @@ type ConnectionLimits struct {
 	MaxConnections int64            `yaml:"max_connections"`
 	MaxUsers       int              `yaml:"max_users"`
 	Rates          []ConnectionRate `yaml:"rates,omitempty"`
+	// Allowlist is a list of IP networks in CIDR notation that are
+	// exempt from rate limiting
+	Allowlist []string `yaml:"allowlist,omitempty"`
+	// TrustedHeader is an optional HTTP header to read the client IP
+	// from, for proxies deployed behind a load balancer
+	TrustedHeader string `yaml:"trusted_header,omitempty"`
 }
 
 // Log configures teleport logging
@@ -414,8 +273,10 @@ type Global struct {
 	// If omitted, the default will be used.
 	CASignatureAlgorithm *string `yaml:"ca_signature_algo,omitempty"`
 
-	// CAPin is the SKPI hash of the CA used to verify the Auth Server.
-	CAPin string `yaml:"ca_pin"`
+	// CAPin is the SKPI hash of the CA used to verify the Auth Server. More
+	// than one pin can be listed (e.g. the current and next pin during CA
+	// rotation).
+	CAPin utils.Strings `yaml:"ca_pin"`
 }
 
 // CachePolicy is used to control  local cache
@@ -577,6 +438,25 @@ type Auth struct {
 	// of contact with the auth server before it starts terminating controlled sessions.
 	SessionControlTimeout services.Duration `yaml:"session_control_timeout,omitempty"`
 
+	// DisallowRootLogins strips root and administrator principals from
+	// issued certificates regardless of role configuration.
+	DisallowRootLogins services.Bool `yaml:"disallow_root_logins,omitempty"`
+
+	// BPFOverrides is a list of enhanced recording overrides pushed to
+	// nodes whose static labels match the selector, evaluated in order
+	// with later matches winning per field.
+	BPFOverrides []BPFOverride `yaml:"bpf_overrides,omitempty"`
+
+	// MinClientVersion is the minimum teleport version clients and agents
+	// must run to connect to this cluster, e.g. "4.2.0". When unset, the
+	// compiled-in minimum supported version is used.
+	MinClientVersion string `yaml:"min_client_version,omitempty"`
+
+	// VersionCheckMode controls what happens to clients and agents older
+	// than the minimum version: "enforce" (default) rejects them, "warn"
+	// logs a warning but lets them connect.
+	VersionCheckMode string `yaml:"version_check_mode,omitempty"`
+
 	// KubeconfigFile is an optional path to kubeconfig file,
 	// if specified, teleport will use API server address and
 	// trusted certificate authority information from it
@@ -589,6 +469,59 @@ type Auth struct {
 	// KeepAliveCountMax set the number of keep-alive messages that can be
 	// missed before the server disconnects the client.
 	KeepAliveCountMax int64 `yaml:"keep_alive_count_max,omitempty"`
+
+	// Notifications configures best-effort delivery of audit events to
+	// external systems, e.g. a webhook receiving access request events.
+	Notifications *Notifications `yaml:"notifications,omitempty"`
+
+	// AuditRedaction is a list of redaction rules applied to audit events
+	// before they are persisted or forwarded, keeping secrets typed into
+	// sessions out of long-term audit storage.
+	AuditRedaction []AuditRedactionRule `yaml:"audit_redaction,omitempty"`
+}
+
+// AuditRedactionRule is a single entry of the `audit_redaction` section
+type AuditRedactionRule struct {
+	// Name identifies the rule in logs
+	Name string `yaml:"name,omitempty"`
+	// Pattern is a regular expression, all matches are replaced
+	Pattern string `yaml:"pattern"`
+	// Fields optionally restricts the rule to event fields with these
+	// names; the rule applies to every string field when empty
+	Fields []string `yaml:"fields,omitempty"`
+	// Replace is the replacement text, "***" when empty
+	Replace string `yaml:"replace,omitempty"`
+}
+
+// Notifications is the `notifications` section of `auth_service`
+type Notifications struct {
+	// Webhook configures an HTTP endpoint notified about cluster events
+	Webhook *Webhook `yaml:"webhook,omitempty"`
+
+	// Alerts is a list of predicate rules over audit events, matching
+	// events are delivered to the webhook in near real time
+	Alerts []AuditAlert `yaml:"alerts,omitempty"`
+}
+
+// AuditAlert is a single entry of the `alerts` section
+type AuditAlert struct {
+	// Name identifies the alert in logs and notifications
+	Name string `yaml:"name"`
+	// Where is a predicate expression over audit event fields, e.g.
+	// `equals(event["type"], "session.exec") && equals(event.login, "root")`
+	Where string `yaml:"where"`
+}
+
+// Webhook holds the configuration of a single webhook notification target
+type Webhook struct {
+	// URL is the endpoint matching audit events are POSTed to as JSON
+	URL string `yaml:"url"`
+	// Events is a list of audit event types to deliver, e.g.
+	// "access_request.create"; all events are delivered when empty
+	Events []string `yaml:"events,omitempty"`
+	// Format is the serialization format for delivered events,
+	// one of "json" (default), "cef" or "leef"
+	Format string `yaml:"format,omitempty"`
 }
 
 // TrustedCluster struct holds configuration values under "trusted_clusters" key
@@ -717,9 +650,26 @@ type SSH struct {
 	Commands              []CommandLabel    `yaml:"commands,omitempty"`
 	PermitUserEnvironment bool              `yaml:"permit_user_env,omitempty"`
 	PAM                   *PAM              `yaml:"pam,omitempty"`
+
+	// DisconnectOnRoleChange terminates active connections when the user's
+	// roles no longer allow access to this node. When disabled, role
+	// changes are still re-evaluated but only logged, and apply at next
+	// login.
+	DisconnectOnRoleChange bool `yaml:"disconnect_on_role_change,omitempty"`
 	// PublicAddr sets SSH host principals for SSH service
 	PublicAddr utils.Strings `yaml:"public_addr,omitempty"`
 
+	// AdditionalPrincipals is a list of extra host principals (CNAMEs,
+	// load balancer names, cloud private DNS names) added to the SSH host
+	// certificate in addition to the hostname and public addresses.
+	AdditionalPrincipals utils.Strings `yaml:"additional_principals,omitempty"`
+
+	// AdditionalPrincipalsCommand is an optional command executed once on
+	// startup; every whitespace-separated token it prints is added to the
+	// host certificate principals. Useful when the extra names are only
+	// known at runtime, e.g. from a cloud metadata service.
+	AdditionalPrincipalsCommand []string `yaml:"additional_principals_command,flow,omitempty"`
+
 	// BPF is used to configure BPF-based auditing for this node.
 	BPF *BPF `yaml:"enhanced_recording,omitempty"`
 }
@@ -769,18 +719,91 @@ type BPF struct {
 
 	// CgroupPath controls where cgroupv2 hierarchy is mounted.
 	CgroupPath string `yaml:"cgroup_path"`
+
+	// CPULimit is the percentage of a single CPU core event processing may
+	// consume before enhanced recording degrades itself.
+	CPULimit int `yaml:"cpu_limit,omitempty"`
+
+	// MemoryLimit is the number of megabytes of memory the process may
+	// consume before enhanced recording degrades itself.
+	MemoryLimit int `yaml:"memory_limit,omitempty"`
+
+	// EnvCapture, when present, enables recording of the environment
+	// variables programs were executed with.
+	EnvCapture *EnvCapture `yaml:"env_capture,omitempty"`
+}
+
+// EnvCapture configures recording of the environment variables programs
+// within a session were executed with.
+type EnvCapture struct {
+	// Allow is the list of variable names to capture, all variables are
+	// captured when the list is empty.
+	Allow []string `yaml:"allow,omitempty"`
+
+	// Deny is the list of variable names to never capture, evaluated
+	// before the allow list.
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+// BPFOverride is an enhanced recording override the auth server pushes to
+// nodes whose static labels match the selector.
+type BPFOverride struct {
+	// Match is a set of node labels the override applies to, all listed
+	// labels have to match. An empty selector matches every node.
+	Match map[string]string `yaml:"match,omitempty"`
+
+	// Enabled turns enhanced session recording on or off on matching nodes.
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// CommandBufferSize overrides the perf buffer size for command events.
+	CommandBufferSize *int `yaml:"command_buffer_size,omitempty"`
+
+	// DiskBufferSize overrides the perf buffer size for disk events.
+	DiskBufferSize *int `yaml:"disk_buffer_size,omitempty"`
+
+	// NetworkBufferSize overrides the perf buffer size for network events.
+	NetworkBufferSize *int `yaml:"network_buffer_size,omitempty"`
+
+	// CPULimit overrides the percentage of a single CPU core event
+	// processing may consume before enhanced recording degrades itself.
+	CPULimit *int `yaml:"cpu_limit,omitempty"`
+
+	// MemoryLimit overrides the number of megabytes of memory the process
+	// may consume before enhanced recording degrades itself.
+	MemoryLimit *int `yaml:"memory_limit,omitempty"`
+}
+
+// Parse converts the file configuration override into its runtime form.
+func (b *BPFOverride) Parse() bpf.ConfigOverride {
+	return bpf.ConfigOverride{
+		Match:             b.Match,
+		Enabled:           b.Enabled,
+		CommandBufferSize: b.CommandBufferSize,
+		DiskBufferSize:    b.DiskBufferSize,
+		NetworkBufferSize: b.NetworkBufferSize,
+		CPULimit:          b.CPULimit,
+		MemoryLimit:       b.MemoryLimit,
+	}
 }
 
 // Parse will parse the enhanced session recording configuration.
 func (b *BPF) Parse() *bpf.Config {
 	enabled, _ := utils.ParseBool(b.Enabled)
-	return &bpf.Config{
+	config := &bpf.Config{
 		Enabled:           enabled,
 		CommandBufferSize: b.CommandBufferSize,
 		DiskBufferSize:    b.DiskBufferSize,
 		NetworkBufferSize: b.NetworkBufferSize,
 		CgroupPath:        b.CgroupPath,
+		CPULimit:          b.CPULimit,
+		MemoryLimit:       b.MemoryLimit,
+	}
+	if b.EnvCapture != nil {
+		config.CaptureEnv = true
+		config.EnvAllowlist = b.EnvCapture.Allow
+		config.EnvDenylist = b.EnvCapture.Deny
 	}
+	return config
 }
 
 // Proxy is a `proxy_service` section of the config file:
@@ -800,6 +823,10 @@ type Proxy struct {
 	// as only admin knows whether service is in front of trusted load balancer
 	// or not.
 	ProxyProtocol string `yaml:"proxy_protocol,omitempty"`
+	// ClientCertAuth turns on accepting client TLS certificates issued by
+	// this cluster's user certificate authority as an authentication method
+	// for web API calls.
+	ClientCertAuth string `yaml:"client_cert_auth,omitempty"`
 	// Kube configures kubernetes protocol support of the proxy
 	Kube Kube `yaml:"kubernetes,omitempty"`
 
@@ -817,6 +844,28 @@ type Proxy struct {
 	// endpoint. The hosts in PublicAddr are included in the list of host
 	// principals on the SSH certificate.
 	TunnelPublicAddr utils.Strings `yaml:"tunnel_public_addr,omitempty"`
+
+	// HostTemplates rewrite requested hostnames into a node search before
+	// routing, so existing ssh_config hostname conventions keep working
+	// when hosts move behind Teleport.
+	HostTemplates []HostTemplate `yaml:"host_templates,omitempty"`
+}
+
+// HostTemplate is a single entry of the `host_templates` section of
+// `proxy_service` in the config file.
+type HostTemplate struct {
+	// Template is a regular expression matched against the hostname the
+	// client requested.
+	Template string `yaml:"template"`
+	// Host is the hostname to look up instead, supports $1 style capture
+	// group references.
+	Host string `yaml:"host,omitempty"`
+	// Cluster is the cluster to route to, supports capture group
+	// references.
+	Cluster string `yaml:"cluster,omitempty"`
+	// Labels are labels the target node has to match instead of matching
+	// by hostname, label values support capture group references.
+	Labels map[string]string `yaml:"labels,omitempty"`
 }
 
 // Kube is a `kubernetes_service`
@@ -1002,8 +1051,12 @@ func (o *OIDCConnector) Parse() (services.OIDCConnector, error) {
 }
 
 type U2F struct {
-	AppID  string   `yaml:"app_id,omitempty"`
-	Facets []string `yaml:"facets,omitempty"`
+	// Enabled is deprecated and ignored: U2F is turned on by setting the
+	// second factor to "u2f". It is still parsed so that strict decoding
+	// does not reject older configuration files that carry it.
+	Enabled string   `yaml:"enabled,omitempty"`
+	AppID   string   `yaml:"app_id,omitempty"`
+	Facets  []string `yaml:"facets,omitempty"`
 }
 
 // Parse parses values in the U2F configuration section and validates its content.
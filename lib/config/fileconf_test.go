@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
 
@@ -38,6 +39,18 @@ func (s *FileTestSuite) TearDownSuite(c *check.C) {
 func (s *FileTestSuite) SetUpTest(c *check.C) {
 }
 
+// TestUnknownKeys makes sure a misspelled or misplaced configuration key
+// is rejected with the line it appears on instead of being silently dropped.
+func (s *FileTestSuite) TestUnknownKeys(c *check.C) {
+	_, err := ReadConfig(bytes.NewBufferString(`
+teleport:
+  nodename: edsger
+  advertise_ipp: 10.10.10.1
+`))
+	c.Assert(err, check.NotNil)
+	c.Assert(err, check.ErrorMatches, `(?s).*line 4: field advertise_ipp not found.*`)
+}
+
 func (s *FileTestSuite) TestAuthenticationSection(c *check.C) {
 	tests := []struct {
 		inConfigString          string
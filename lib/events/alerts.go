@@ -0,0 +1,186 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+	"github.com/vulcand/predicate"
+)
+
+// AlertRule is an operator-defined predicate over audit events, events
+// matching the `where` expression are forwarded to the notification
+// target in near real time.
+type AlertRule struct {
+	// Name identifies the rule in logs and notifications
+	Name string
+	// Where is a predicate expression over event fields, e.g.
+	// `equals(event["type"], "session.exec") && equals(event.login, "root")`
+	Where string
+}
+
+// AlertingEmitterConfig provides parameters for the alerting emitter
+type AlertingEmitterConfig struct {
+	// Inner is the emitter matching events are forwarded to,
+	// e.g. a webhook emitter
+	Inner Emitter
+	// Rules is the list of alert rules to evaluate
+	Rules []AlertRule
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *AlertingEmitterConfig) CheckAndSetDefaults() error {
+	if c.Inner == nil {
+		return trace.BadParameter("missing parameter Inner")
+	}
+	if len(c.Rules) == 0 {
+		return trace.BadParameter("missing parameter Rules")
+	}
+	for i, rule := range c.Rules {
+		if rule.Where == "" {
+			return trace.BadParameter("audit alert %q is missing a where expression", rule.Name)
+		}
+		// compile the expression against an empty event to catch
+		// syntax errors at startup rather than at delivery time
+		if _, err := matchAlertRule(rule, EventFields{}); err != nil {
+			return trace.BadParameter("audit alert %v %q failed to parse: %v", i, rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// NewAlertingEmitter returns an emitter that evaluates alert rules
+// against every audit event and forwards matching events to the
+// inner emitter.
+func NewAlertingEmitter(cfg AlertingEmitterConfig) (*AlertingEmitter, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &AlertingEmitter{
+		AlertingEmitterConfig: cfg,
+	}, nil
+}
+
+// AlertingEmitter forwards events matching alert rules to the inner emitter
+type AlertingEmitter struct {
+	AlertingEmitterConfig
+}
+
+// EmitAuditEvent evaluates the alert rules against the event and
+// forwards it if any rule matches. Rule evaluation errors are logged
+// and never fail the audit path.
+func (a *AlertingEmitter) EmitAuditEvent(ctx context.Context, event AuditEvent) error {
+	fields, err := ToEventFields(event)
+	if err != nil {
+		log.WithError(err).Warnf("Failed to convert event %v for alert evaluation.", event.GetType())
+		return nil
+	}
+	for _, rule := range a.Rules {
+		match, err := matchAlertRule(rule, fields)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to evaluate audit alert %q.", rule.Name)
+			continue
+		}
+		if !match {
+			continue
+		}
+		log.Infof("Audit alert %q matched event %v.", rule.Name, event.GetType())
+		if err := a.Inner.EmitAuditEvent(ctx, event); err != nil {
+			log.WithError(err).Warnf("Failed to forward event for audit alert %q.", rule.Name)
+		}
+		// one notification per event is enough, no matter
+		// how many rules matched
+		break
+	}
+	return nil
+}
+
+// matchAlertRule evaluates a single rule against event fields
+func matchAlertRule(rule AlertRule, fields EventFields) (bool, error) {
+	parser, err := newAlertParser(fields)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	ifn, err := parser.Parse(rule.Where)
+	if err != nil {
+		return false, trace.Wrap(err)
+	}
+	fn, ok := ifn.(predicate.BoolPredicate)
+	if !ok {
+		return false, trace.BadParameter("unsupported type: %T", ifn)
+	}
+	return fn(), nil
+}
+
+// alertField resolves a field reference against the event, "type" and
+// "code" map to the event type and code, any other name is looked up in
+// the event fields directly. Unknown fields evaluate to an empty string
+// so that rules can refer to fields other event types lack.
+func alertField(fields EventFields, key string) interface{} {
+	switch key {
+	case "type":
+		return fields.GetType()
+	case "code":
+		return fields.GetCode()
+	}
+	if value, ok := fields[key]; ok {
+		return value
+	}
+	return ""
+}
+
+// newAlertParser returns a predicate parser evaluating expressions
+// against the fields of a single audit event
+func newAlertParser(fields EventFields) (predicate.Parser, error) {
+	return predicate.NewParser(predicate.Def{
+		Operators: predicate.Operators{
+			AND: predicate.And,
+			OR:  predicate.Or,
+			NOT: predicate.Not,
+		},
+		Functions: map[string]interface{}{
+			"equals":   predicate.Equals,
+			"contains": predicate.Contains,
+		},
+		GetIdentifier: func(parts []string) (interface{}, error) {
+			if parts[0] != "event" {
+				return nil, trace.BadParameter("unsupported identifier %q, supported identifiers begin with \"event\"", strings.Join(parts, "."))
+			}
+			// bare `event` is used with index syntax, e.g.
+			// event["type"], which covers fields whose names
+			// collide with Go keywords
+			if len(parts) == 1 {
+				return fields, nil
+			}
+			return alertField(fields, strings.Join(parts[1:], ".")), nil
+		},
+		GetProperty: func(mapVal, keyVal interface{}) (interface{}, error) {
+			m, ok := mapVal.(EventFields)
+			if !ok {
+				return nil, trace.BadParameter("type %T is not supported", mapVal)
+			}
+			key, ok := keyVal.(string)
+			if !ok {
+				return nil, trace.BadParameter("only string keys are supported")
+			}
+			return alertField(m, key), nil
+		},
+	})
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// captureEmitter collects emitted events for assertions
+type captureEmitter struct {
+	events []AuditEvent
+}
+
+func (c *captureEmitter) EmitAuditEvent(ctx context.Context, event AuditEvent) error {
+	c.events = append(c.events, event)
+	return nil
+}
+
+// TestAlertRules verifies that alert rules match the expected events
+// and invalid expressions are rejected at startup.
+func TestAlertRules(t *testing.T) {
+	ctx := context.Background()
+	testEvents := GenerateTestSession(SessionParams{PrintEvents: 1})
+
+	var testCases = []struct {
+		name    string
+		where   string
+		matches int
+	}{
+		{
+			name:    "match by type",
+			where:   `equals(event["type"], "session.start")`,
+			matches: 1,
+		},
+		{
+			name:    "match by type and login",
+			where:   `equals(event["type"], "session.start") && equals(event.login, "bob")`,
+			matches: 1,
+		},
+		{
+			name:    "no match on other login",
+			where:   `equals(event["type"], "session.start") && equals(event.login, "alice")`,
+			matches: 0,
+		},
+		{
+			name:    "negation matches everything but print events",
+			where:   `!equals(event["type"], "print")`,
+			matches: len(testEvents) - 1,
+		},
+		{
+			name:    "unknown fields evaluate to empty strings",
+			where:   `equals(event.no_such_field, "")`,
+			matches: len(testEvents),
+		},
+	}
+	for _, tc := range testCases {
+		capture := &captureEmitter{}
+		alerting, err := NewAlertingEmitter(AlertingEmitterConfig{
+			Inner: capture,
+			Rules: []AlertRule{{Name: tc.name, Where: tc.where}},
+		})
+		assert.NoError(t, err, tc.name)
+		for _, event := range testEvents {
+			assert.NoError(t, alerting.EmitAuditEvent(ctx, event))
+		}
+		assert.Len(t, capture.events, tc.matches, tc.name)
+	}
+
+	// expressions that fail to parse are rejected at startup
+	for _, where := range []string{
+		"",
+		"equals(",
+		`equals(user.name, "bob")`,
+	} {
+		_, err := NewAlertingEmitter(AlertingEmitterConfig{
+			Inner: &captureEmitter{},
+			Rules: []AlertRule{{Name: "bad", Where: where}},
+		})
+		assert.Error(t, err, where)
+	}
+}
@@ -41,6 +41,10 @@ const (
 	EventLogin = "login"
 	// EventUser is teleport user name
 	EventUser = "user"
+	// EventSearch is a free-text search filter, it is not stored on events
+	// but interpreted by the search back-ends as a case-insensitive
+	// substring match over the raw event
+	EventSearch = "search"
 	// EventProtocol specifies protocol that was captured
 	EventProtocol = "proto"
 	// EventProtocolsSSH specifies SSH as a type of captured protocol
@@ -72,6 +76,10 @@ const (
 	// session when the terminal IO event happened
 	SessionEventTimestamp = "ms"
 
+	// SessionPrintEventData contains the bytes written to the terminal,
+	// included only when a recording is exported with payloads inlined
+	SessionPrintEventData = "data"
+
 	// SessionEvent indicates that session has been initiated
 	// or updated by a joining party on the server
 	SessionStartEvent = "session.start"
@@ -107,6 +115,26 @@ const (
 	// enhanced recording or not.
 	SessionEnhancedRecording = "enhanced_recording"
 
+	// SessionRecordingMode is the new session recording mode after a
+	// cluster configuration change.
+	SessionRecordingMode = "session_recording"
+
+	// NetworkRestrictionsAllow is the list of allowed CIDR blocks after a
+	// network restrictions change.
+	NetworkRestrictionsAllow = "allow"
+
+	// NetworkRestrictionsDeny is the list of denied CIDR blocks after a
+	// network restrictions change.
+	NetworkRestrictionsDeny = "deny"
+
+	// SessionRecordingSubsystem is the enhanced recording subsystem
+	// (disk or network) a node disabled to stay within its overhead limits.
+	SessionRecordingSubsystem = "subsystem"
+
+	// SessionRecordingReason is why a node disabled part of enhanced
+	// session recording.
+	SessionRecordingReason = "reason"
+
 	// SessionInteractive is used to indicate if the session was interactive
 	// (has PTY attached) or not (exec session).
 	SessionInteractive = "interactive"
@@ -167,6 +195,14 @@ const (
 	// UserPasswordChangeEvent is when the user changes their own password.
 	UserPasswordChangeEvent = "user.password_change"
 
+	// RecoveryCodesGenerateEvent is emitted when new recovery codes are
+	// generated for a user, invalidating any previous codes.
+	RecoveryCodesGenerateEvent = "recovery_codes.generate"
+
+	// RecoveryCodeUseEvent is emitted when a user attempts to use a
+	// recovery code to regain access to their account.
+	RecoveryCodeUseEvent = "recovery_code.used"
+
 	// UserExpires is when the user will expire.
 	UserExpires = "expires"
 
@@ -266,6 +302,53 @@ const (
 	// session.
 	SessionNetworkEvent = "session.network"
 
+	// SessionRecordingChangeEvent is emitted when the session recording
+	// mode of the cluster is changed.
+	SessionRecordingChangeEvent = "session.recording.change"
+
+	// SessionRecordingDegradeEvent is emitted when a node disables part of
+	// enhanced session recording because it exceeded its overhead limits.
+	SessionRecordingDegradeEvent = "session.recording.degrade"
+
+	// NetworkRestrictionsChangeEvent is emitted when the cluster-wide
+	// network restrictions resource is changed or deleted.
+	NetworkRestrictionsChangeEvent = "network.restrictions.change"
+
+	// SessionOOMEvent is emitted when processes in a session have been
+	// killed by the kernel OOM killer because the session exceeded the
+	// memory limit applied to its cgroup.
+	SessionOOMEvent = "session.oom"
+
+	// OOMKills is the number of processes killed by the kernel OOM killer
+	// within a session.
+	OOMKills = "oom_kills"
+
+	// SessionCommandEnvEvent is emitted alongside a session command event
+	// and carries the environment variables the program was executed with.
+	SessionCommandEnvEvent = "session.command.env"
+
+	// Environment is the list of environment variables a program was
+	// executed with.
+	Environment = "environment"
+
+	// SessionLibraryLoadEvent is emitted when a program within a session
+	// loads a shared library with dlopen or is executed with dynamic
+	// linker overrides like LD_PRELOAD.
+	SessionLibraryLoadEvent = "session.library_load"
+
+	// SessionUnixConnectEvent is emitted when a program within a session
+	// attempts to connect to a Unix domain socket.
+	SessionUnixConnectEvent = "session.unix.connect"
+
+	// SessionRecordingDeleteEvent is emitted when a stored session
+	// recording is deleted, e.g. by a retention policy.
+	SessionRecordingDeleteEvent = "session.recording.delete"
+
+	// SessionReverseShellEvent is emitted when a shell is executed within
+	// a session with a socket connected to its standard input and output,
+	// the canonical reverse shell pattern.
+	SessionReverseShellEvent = "session.reverse_shell"
+
 	// PID is the ID of the process.
 	PID = "pid"
 
@@ -566,6 +649,14 @@ type IAuditLog interface {
 	// UploadSessionRecording uploads session recording to the audit server
 	UploadSessionRecording(r SessionRecording) error
 
+	// DownloadSessionRecording downloads the stored session recording
+	// tarball from the session storage handler and writes it to writer
+	DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error
+
+	// DeleteSessionRecording deletes the stored session recording from the
+	// session storage handler, audit events of the session are not affected
+	DeleteSessionRecording(namespace string, sid session.ID) error
+
 	// GetSessionChunk returns a reader which can be used to read a byte stream
 	// of a recorded session starting from 'offsetBytes' (pass 0 to start from the
 	// beginning) up to maxBytes bytes.
@@ -24,6 +24,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -91,10 +92,17 @@ var (
 
 	auditFailedEmit = prometheus.NewCounter(
 		prometheus.CounterOpts{
-			Name: "audit_failed_emit_events",
+			Name: teleport.MetricAuditFailedEmitEvents,
 			Help: "Number of times emitting audit event failed.",
 		},
 	)
+
+	auditEmitted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: teleport.MetricAuditEmittedEvents,
+			Help: "Number of audit events emitted.",
+		},
+	)
 )
 
 func init() {
@@ -103,6 +111,7 @@ func init() {
 	prometheus.MustRegister(auditDiskUsed)
 	prometheus.MustRegister(auditFailedDisk)
 	prometheus.MustRegister(auditFailedEmit)
+	prometheus.MustRegister(auditEmitted)
 }
 
 // AuditLog is a new combined facility to record Teleport events and
@@ -351,6 +360,46 @@ func (l *AuditLog) UploadSessionRecording(r SessionRecording) error {
 	})
 }
 
+// DownloadSessionRecording downloads the stored session recording tarball
+// from the session storage handler and writes it to writer.
+func (l *AuditLog) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	if namespace == "" {
+		return trace.BadParameter("missing parameter namespace")
+	}
+	// The upload handlers require an io.WriterAt, download into a
+	// temporary file first and stream it out.
+	tmp, err := ioutil.TempFile(l.playbackDir, string(sid)+".download.*.tar")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if err := l.UploadHandler.Download(l.ctx, sid, tmp); err != nil {
+		return trace.Wrap(err)
+	}
+	if _, err := tmp.Seek(0, 0); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if _, err := io.Copy(writer, tmp); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// DeleteSessionRecording deletes the stored session recording from the
+// session storage handler. Audit events of the session are not affected.
+// Returns NotImplemented if the handler does not support deletion.
+func (l *AuditLog) DeleteSessionRecording(namespace string, sid session.ID) error {
+	if namespace == "" {
+		return trace.BadParameter("missing parameter namespace")
+	}
+	deleter, ok := l.UploadHandler.(RecordingDeleter)
+	if !ok {
+		return trace.NotImplemented("session storage handler does not support deleting recordings")
+	}
+	return trace.Wrap(deleter.DeleteRecording(l.ctx, sid))
+}
+
 // PostSessionSlice submits slice of session chunks to the audit log server.
 func (l *AuditLog) PostSessionSlice(slice SessionSlice) error {
 	if slice.Namespace == "" {
@@ -960,6 +1009,7 @@ func (l *AuditLog) EmitAuditEvent(ctx context.Context, event AuditEvent) error {
 		auditFailedEmit.Inc()
 		return trace.Wrap(err)
 	}
+	auditEmitted.Inc()
 	return nil
 }
 
@@ -982,7 +1032,7 @@ func (l *AuditLog) EmitAuditEventLegacy(event Event, fields EventFields) error {
 		auditFailedEmit.Inc()
 		return trace.Wrap(err)
 	}
-
+	auditEmitted.Inc()
 	return nil
 }
 
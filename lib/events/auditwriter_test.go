@@ -201,6 +201,31 @@ func TestAuditWriter(t *testing.T) {
 
 }
 
+// TestAuditWriterUpdateStatus verifies that the writer trims acknowledged
+// events from its replay buffer, including the case when the server only
+// confirmed the first buffered event.
+func TestAuditWriterUpdateStatus(t *testing.T) {
+	events := GenerateTestSession(SessionParams{
+		PrintEvents: 3,
+	})
+	writer := &AuditWriter{
+		log:    log.WithFields(log.Fields{}),
+		buffer: append([]AuditEvent{}, events...),
+	}
+
+	// negative index means no events were confirmed yet
+	writer.updateStatus(StreamStatus{LastEventIndex: -1})
+	assert.Len(t, writer.buffer, len(events))
+
+	// confirming the first event alone releases it from the buffer
+	writer.updateStatus(StreamStatus{LastEventIndex: events[0].GetIndex()})
+	assert.Len(t, writer.buffer, len(events)-1)
+
+	// confirming the last event drains the buffer
+	writer.updateStatus(StreamStatus{LastEventIndex: events[len(events)-1].GetIndex()})
+	assert.Len(t, writer.buffer, 0)
+}
+
 type auditWriterTest struct {
 	eventsCh chan UploadEvent
 	uploader *MemoryUploader
@@ -196,6 +196,79 @@ var (
 		Name: SessionNetworkEvent,
 		Code: SessionNetworkCode,
 	}
+	// SessionRecordingChangeE is emitted when the session recording mode
+	// of the cluster is changed.
+	SessionRecordingChangeE = Event{
+		Name: SessionRecordingChangeEvent,
+		Code: SessionRecordingChangeCode,
+	}
+	// SessionRecordingDegradeE is emitted when a node disables part of
+	// enhanced session recording because it exceeded its overhead limits.
+	SessionRecordingDegradeE = Event{
+		Name: SessionRecordingDegradeEvent,
+		Code: SessionRecordingDegradeCode,
+	}
+	// NetworkRestrictionsChangeE is emitted when the cluster-wide network
+	// restrictions resource is changed or deleted.
+	NetworkRestrictionsChangeE = Event{
+		Name: NetworkRestrictionsChangeEvent,
+		Code: NetworkRestrictionsChangeCode,
+	}
+	// SessionOOME is emitted when processes in a session have been killed
+	// by the kernel OOM killer because the session exceeded its memory
+	// limit.
+	SessionOOME = Event{
+		Name: SessionOOMEvent,
+		Code: SessionOOMCode,
+	}
+	// SessionCommandEnvE is emitted alongside a session command event and
+	// carries the environment variables the program was executed with.
+	SessionCommandEnvE = Event{
+		Name: SessionCommandEnvEvent,
+		Code: SessionCommandEnvCode,
+	}
+	// SessionLibraryLoadE is emitted when a program within a session loads
+	// a shared library with dlopen or is executed with dynamic linker
+	// overrides.
+	SessionLibraryLoadE = Event{
+		Name: SessionLibraryLoadEvent,
+		Code: SessionLibraryLoadCode,
+	}
+	// SessionUnixConnectE is emitted when a program within a session
+	// attempts to connect to a Unix domain socket.
+	SessionUnixConnectE = Event{
+		Name: SessionUnixConnectEvent,
+		Code: SessionUnixConnectCode,
+	}
+	// SessionRecordingDeleteE is emitted when a stored session recording
+	// is deleted.
+	SessionRecordingDeleteE = Event{
+		Name: SessionRecordingDeleteEvent,
+		Code: SessionRecordingDeleteCode,
+	}
+	// SessionReverseShellE is emitted when a shell is executed within a
+	// session with a socket connected to its standard input and output.
+	SessionReverseShellE = Event{
+		Name: SessionReverseShellEvent,
+		Code: SessionReverseShellCode,
+	}
+	// RecoveryCodesGenerateE is emitted when new recovery codes are
+	// generated for a user.
+	RecoveryCodesGenerateE = Event{
+		Name: RecoveryCodesGenerateEvent,
+		Code: RecoveryCodesGenerateCode,
+	}
+	// RecoveryCodeUseE is emitted when a user successfully uses a
+	// recovery code.
+	RecoveryCodeUseE = Event{
+		Name: RecoveryCodeUseEvent,
+		Code: RecoveryCodeUseCode,
+	}
+	// RecoveryCodeUseFailureE is emitted when a recovery code attempt fails.
+	RecoveryCodeUseFailureE = Event{
+		Name: RecoveryCodeUseEvent,
+		Code: RecoveryCodeUseFailureCode,
+	}
 	// ResetPasswordTokenCreatedE is emitted when a password reset token is created.
 	ResetPasswordTokenCreatedE = Event{
 		Name: ResetPasswordTokenCreateEvent,
@@ -295,6 +368,16 @@ const (
 	// session/connection has been rejected.
 	SessionRejectedCode = "T1006W"
 
+	// RecoveryCodesGenerateCode is an event code for when new recovery codes
+	// are generated for a user.
+	RecoveryCodesGenerateCode = "T1007I"
+	// RecoveryCodeUseCode is an event code for when a user successfully uses
+	// a recovery code.
+	RecoveryCodeUseCode = "T1008I"
+	// RecoveryCodeUseFailureCode is an event code for when a recovery code
+	// attempt fails.
+	RecoveryCodeUseFailureCode = "T1008W"
+
 	// SessionStartCode is the session start event code.
 	SessionStartCode = "T2000I"
 	// SessionJoinCode is the session join event code.
@@ -343,8 +426,32 @@ const (
 	SessionCommandCode = "T4000I"
 	// SessionDiskCode is a session disk code.
 	SessionDiskCode = "T4001I"
+	// SessionDiskRestrictedCode is emitted when a session process attempts
+	// to write under a path prefix restricted by a role.
+	SessionDiskRestrictedCode = "T4001W"
 	// SessionNetworkCode is a session network code.
 	SessionNetworkCode = "T4002I"
+	// SessionNetworkRestrictedCode is emitted when a session process
+	// connects to a destination denied by the cluster network restrictions.
+	SessionNetworkRestrictedCode = "T4002W"
+	// SessionRecordingChangeCode is a session recording change code.
+	SessionRecordingChangeCode = "T4003I"
+	// SessionRecordingDegradeCode is a session recording degrade code.
+	SessionRecordingDegradeCode = "T4004W"
+	// NetworkRestrictionsChangeCode is a network restrictions change code.
+	NetworkRestrictionsChangeCode = "T4005I"
+	// SessionOOMCode is a session OOM kill code.
+	SessionOOMCode = "T4006W"
+	// SessionCommandEnvCode is a session command environment code.
+	SessionCommandEnvCode = "T4007I"
+	// SessionLibraryLoadCode is a session library load code.
+	SessionLibraryLoadCode = "T4008I"
+	// SessionUnixConnectCode is a session Unix domain socket connect code.
+	SessionUnixConnectCode = "T4009I"
+	// SessionRecordingDeleteCode is a session recording delete code.
+	SessionRecordingDeleteCode = "T4010I"
+	// SessionReverseShellCode is a session reverse shell detection code.
+	SessionReverseShellCode = "T4011W"
 
 	// AccessRequestCreateCode is the the access request creation code.
 	AccessRequestCreateCode = "T5000I"
@@ -20,6 +20,7 @@ package dynamoevents
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/url"
 	"sort"
 	"time"
@@ -368,6 +369,14 @@ func (l *Log) UploadSessionRecording(events.SessionRecording) error {
 	return trace.BadParameter("not supported")
 }
 
+func (l *Log) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	return trace.NotImplemented("not implemented")
+}
+
+func (l *Log) DeleteSessionRecording(namespace string, sid session.ID) error {
+	return trace.NotImplemented("not implemented")
+}
+
 // GetSessionChunk returns a reader which can be used to read a byte stream
 // of a recorded session starting from 'offsetBytes' (pass 0 to start from the
 // beginning) up to maxBytes bytes.
@@ -434,9 +443,16 @@ func (l *Log) SearchEvents(fromUTC, toUTC time.Time, filter string, limit int) (
 	if err != nil {
 		return nil, trace.BadParameter("missing parameter query")
 	}
-	eventFilter, ok := filterVals[events.EventType]
-	if !ok && len(filterVals) > 0 {
-		return nil, nil
+	eventFilter := filterVals[events.EventType]
+	userFilter := filterVals[events.EventUser]
+	searchFilter := filterVals[events.EventSearch]
+	// queries with unsupported filters match nothing
+	for key := range filterVals {
+		switch key {
+		case events.EventType, events.EventUser, events.EventSearch:
+		default:
+			return nil, nil
+		}
 	}
 	doFilter := len(eventFilter) > 0
 
@@ -481,12 +497,19 @@ func (l *Log) SearchEvents(fromUTC, toUTC time.Time, filter string, limit int) (
 				break
 			}
 		}
-		if accepted || !doFilter {
-			values = append(values, fields)
-			total += 1
-			if limit > 0 && total >= limit {
-				break
-			}
+		if doFilter && !accepted {
+			continue
+		}
+		if !events.MatchFieldFilter(fields.GetString(events.EventUser), userFilter) {
+			continue
+		}
+		if !events.MatchSearchFilter(e.Fields, searchFilter) {
+			continue
+		}
+		values = append(values, fields)
+		total += 1
+		if limit > 0 && total >= limit {
+			break
 		}
 	}
 	sort.Sort(events.ByTimeAndIndex(values))
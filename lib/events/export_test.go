@@ -0,0 +1,100 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/session"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exportTestLog serves a fixed set of session events and a fixed chunk
+// stream, paging chunk reads to exercise the page-crossing logic.
+type exportTestLog struct {
+	DiscardAuditLog
+	events []EventFields
+	chunk  []byte
+}
+
+func (l *exportTestLog) GetSessionEvents(namespace string, sid session.ID, after int, includePrintEvents bool) ([]EventFields, error) {
+	return l.events, nil
+}
+
+func (l *exportTestLog) GetSessionChunk(namespace string, sid session.ID, offsetBytes, maxBytes int) ([]byte, error) {
+	if offsetBytes >= len(l.chunk) {
+		return nil, nil
+	}
+	// serve tiny pages so a single print event spans several fetches
+	end := offsetBytes + 4
+	if end > len(l.chunk) {
+		end = len(l.chunk)
+	}
+	return l.chunk[offsetBytes:end], nil
+}
+
+// TestExportSession makes sure exported recordings inline the print
+// payloads with absolute timestamps and keep the other events intact.
+func TestExportSession(t *testing.T) {
+	start := time.Date(2020, 3, 20, 10, 0, 0, 0, time.UTC)
+	alog := &exportTestLog{
+		chunk: []byte("hello, worldgoodbye"),
+		events: []EventFields{
+			{EventType: SessionStartEvent, EventTime: start.Format(time.RFC3339)},
+			{EventType: SessionPrintEvent, SessionByteOffset: 0, SessionPrintEventBytes: 12, SessionEventTimestamp: 100},
+			{EventType: ResizeEvent, EventTime: start.Add(time.Second).Format(time.RFC3339), TerminalSize: "80:25"},
+			{EventType: SessionPrintEvent, SessionByteOffset: 12, SessionPrintEventBytes: 7, SessionEventTimestamp: 2500},
+			{EventType: SessionEndEvent, EventTime: start.Add(3 * time.Second).Format(time.RFC3339)},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := ExportSession(alog, &buf, defaults.Namespace, session.NewID())
+	assert.NoError(t, err)
+
+	var exported []EventFields
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var fields EventFields
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &fields))
+		exported = append(exported, fields)
+	}
+	assert.Len(t, exported, 5)
+
+	// non-print events pass through with their types intact
+	assert.Equal(t, SessionStartEvent, exported[0].GetType())
+	assert.Equal(t, ResizeEvent, exported[2].GetType())
+	assert.Equal(t, "80:25", exported[2].GetString(TerminalSize))
+
+	// print payloads are inlined and timestamps computed from the offset
+	data, err := base64.StdEncoding.DecodeString(exported[1].GetString(SessionPrintEventData))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello, world", string(data))
+	assert.Equal(t, start.Add(100*time.Millisecond), exported[1].GetTime(EventTime))
+
+	data, err = base64.StdEncoding.DecodeString(exported[3].GetString(SessionPrintEventData))
+	assert.NoError(t, err)
+	assert.Equal(t, "goodbye", string(data))
+	assert.Equal(t, start.Add(2500*time.Millisecond), exported[3].GetTime(EventTime))
+}
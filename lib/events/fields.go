@@ -20,6 +20,7 @@ import (
 	"archive/tar"
 	"bufio"
 	"compress/gzip"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"strings"
@@ -32,6 +33,20 @@ import (
 	"github.com/jonboulle/clockwork"
 )
 
+// ToEventFields converts a structured audit event to the legacy
+// EventFields map representation via its JSON form.
+func ToEventFields(event AuditEvent) (EventFields, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var fields EventFields
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return fields, nil
+}
+
 // ValidateServerMetadata checks that event server ID of the event
 // if present, matches the passed server ID and namespace has proper syntax
 func ValidateServerMetadata(event AuditEvent, serverID string) error {
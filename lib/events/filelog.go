@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -268,6 +269,14 @@ func (l *FileLog) UploadSessionRecording(SessionRecording) error {
 	return trace.NotImplemented("not implemented")
 }
 
+func (l *FileLog) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	return trace.NotImplemented("not implemented")
+}
+
+func (l *FileLog) DeleteSessionRecording(namespace string, sid session.ID) error {
+	return trace.NotImplemented("not implemented")
+}
+
 func (l *FileLog) PostSessionSlice(slice SessionSlice) error {
 	if slice.Namespace == "" {
 		return trace.BadParameter("missing parameter Namespace")
@@ -432,9 +441,16 @@ func (l *FileLog) findInFile(fn string, query url.Values, total *int, limit int)
 	l.Debugf("Called findInFile(%s, %v).", fn, query)
 	retval := make([]EventFields, 0)
 
-	eventFilter, ok := query[EventType]
-	if !ok && len(query) > 0 {
-		return nil, nil
+	eventFilter := query[EventType]
+	userFilter := query[EventUser]
+	searchFilter := query[EventSearch]
+	// queries with unsupported filters match nothing
+	for key := range query {
+		switch key {
+		case EventType, EventUser, EventSearch:
+		default:
+			return nil, nil
+		}
 	}
 	doFilter := len(eventFilter) > 0
 
@@ -473,17 +489,55 @@ func (l *FileLog) findInFile(fn string, query url.Values, total *int, limit int)
 				break
 			}
 		}
-		if accepted || !doFilter {
-			retval = append(retval, ef)
-			*total++
-			if limit > 0 && *total >= limit {
-				break
-			}
+		if doFilter && !accepted {
+			continue
+		}
+		if !MatchFieldFilter(ef.GetString(EventUser), userFilter) {
+			continue
+		}
+		if !MatchSearchFilter(scanner.Text(), searchFilter) {
+			continue
+		}
+		retval = append(retval, ef)
+		*total++
+		if limit > 0 && *total >= limit {
+			break
 		}
 	}
 	return retval, nil
 }
 
+// MatchFieldFilter returns true if the value matches one of the filter
+// values, an empty filter matches any value. It is shared by the search
+// back-ends.
+func MatchFieldFilter(value string, filter []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for i := range filter {
+		if value == filter[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSearchFilter returns true if the raw event line contains every search
+// term, the comparison is case-insensitive. It is shared by the search
+// back-ends.
+func MatchSearchFilter(line string, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	line = strings.ToLower(line)
+	for i := range terms {
+		if !strings.Contains(line, strings.ToLower(terms[i])) {
+			return false
+		}
+	}
+	return true
+}
+
 type eventFile struct {
 	os.FileInfo
 	path string
@@ -121,6 +121,15 @@ func (l *Handler) Upload(ctx context.Context, sessionID session.ID, reader io.Re
 	return fmt.Sprintf("%v://%v", teleport.SchemeFile, path), nil
 }
 
+// DeleteRecording deletes the stored recording of the session, in case of
+// file handler removes the file from the local directory
+func (l *Handler) DeleteRecording(ctx context.Context, sessionID session.ID) error {
+	if err := os.Remove(l.path(sessionID)); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	return nil
+}
+
 func (l *Handler) path(sessionID session.ID) string {
 	return filepath.Join(l.Directory, string(sessionID)+tarExt)
 }
@@ -19,6 +19,7 @@ package firestoreevents
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/url"
 	"sort"
 	"strconv"
@@ -381,6 +382,14 @@ func (l *Log) UploadSessionRecording(events.SessionRecording) error {
 	return trace.NotImplemented("UploadSessionRecording not implemented for firestore backend")
 }
 
+func (l *Log) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	return trace.NotImplemented("DownloadSessionRecording not implemented for firestore backend")
+}
+
+func (l *Log) DeleteSessionRecording(namespace string, sid session.ID) error {
+	return trace.NotImplemented("DeleteSessionRecording not implemented for firestore backend")
+}
+
 // GetSessionChunk returns a reader which can be used to read a byte stream
 // of a recorded session starting from 'offsetBytes' (pass 0 to start from the
 // beginning) up to maxBytes bytes.
@@ -19,6 +19,7 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"sync"
 	"time"
 
@@ -215,6 +216,18 @@ func (l *Forwarder) UploadSessionRecording(r SessionRecording) error {
 	return l.ForwardTo.UploadSessionRecording(r)
 }
 
+// DownloadSessionRecording downloads the stored session recording tarball
+// from the audit server and writes it to writer
+func (l *Forwarder) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	return l.ForwardTo.DownloadSessionRecording(namespace, sid, writer)
+}
+
+// DeleteSessionRecording deletes the stored session recording from the
+// audit server
+func (l *Forwarder) DeleteSessionRecording(namespace string, sid session.ID) error {
+	return l.ForwardTo.DeleteSessionRecording(namespace, sid)
+}
+
 // GetSessionChunk returns a reader which can be used to read a byte stream
 // of a recorded session starting from 'offsetBytes' (pass 0 to start from the
 // beginning) up to maxBytes bytes.
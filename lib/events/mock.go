@@ -18,6 +18,7 @@ package events
 
 import (
 	"context"
+	"io"
 	"sync"
 	"time"
 
@@ -79,6 +80,14 @@ func (d *MockAuditLog) UploadSessionRecording(SessionRecording) error {
 	return nil
 }
 
+func (d *MockAuditLog) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	return nil
+}
+
+func (d *MockAuditLog) DeleteSessionRecording(namespace string, sid session.ID) error {
+	return nil
+}
+
 func (d *MockAuditLog) PostSessionSlice(slice SessionSlice) error {
 	if err := d.GetError(); err != nil {
 		d.FailedAttemptsC <- &slice
@@ -18,6 +18,7 @@ package events
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/gravitational/teleport/lib/session"
@@ -72,6 +73,31 @@ func (m *MultiLog) UploadSessionRecording(rec SessionRecording) error {
 	return trace.NewAggregate(errors...)
 }
 
+// DownloadSessionRecording downloads the stored session recording tarball
+// from the first logger that implements the operation and writes it to
+// writer
+func (m *MultiLog) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) (err error) {
+	for _, log := range m.loggers {
+		err = log.DownloadSessionRecording(namespace, sid, writer)
+		if !trace.IsNotImplemented(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// DeleteSessionRecording deletes the stored session recording from the
+// first logger that implements the operation
+func (m *MultiLog) DeleteSessionRecording(namespace string, sid session.ID) (err error) {
+	for _, log := range m.loggers {
+		err = log.DeleteSessionRecording(namespace, sid)
+		if !trace.IsNotImplemented(err) {
+			return err
+		}
+	}
+	return err
+}
+
 // DELETE IN: 2.7.0
 // This method is no longer necessary as nodes and proxies >= 2.7.0
 // use UploadSessionRecording method.
@@ -0,0 +1,190 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+
+	"github.com/gravitational/teleport/lib/utils"
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// RedactionRule is an operator-defined substitution applied to audit events
+// before they are persisted or forwarded, so secrets typed into sessions
+// (tokens, passwords) never reach long-term audit storage.
+type RedactionRule struct {
+	// Name identifies the rule in logs
+	Name string
+	// Pattern is a regular expression, all matches are replaced
+	Pattern string
+	// Fields optionally restricts the rule to event fields with these
+	// names (at any nesting depth); the rule applies to every string
+	// field when empty
+	Fields []string
+	// Replace is the replacement text, "***" when empty; capture group
+	// references such as $1 are supported
+	Replace string
+}
+
+// redactionDefaultReplace is used when a rule does not set a replacement.
+const redactionDefaultReplace = "***"
+
+// RedactingEmitterConfig provides parameters for the redacting emitter
+type RedactingEmitterConfig struct {
+	// Inner is the emitter redacted events are forwarded to
+	Inner Emitter
+	// Rules is the list of redaction rules to apply
+	Rules []RedactionRule
+}
+
+// compiledRedaction is a redaction rule with its pattern compiled.
+type compiledRedaction struct {
+	RedactionRule
+	expr *regexp.Regexp
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *RedactingEmitterConfig) CheckAndSetDefaults() error {
+	if c.Inner == nil {
+		return trace.BadParameter("missing parameter Inner")
+	}
+	if len(c.Rules) == 0 {
+		return trace.BadParameter("missing parameter Rules")
+	}
+	for i, rule := range c.Rules {
+		if rule.Pattern == "" {
+			return trace.BadParameter("audit redaction %v %q is missing a pattern", i, rule.Name)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return trace.BadParameter("audit redaction %v %q failed to parse: %v", i, rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// NewRedactingEmitter returns an emitter that applies redaction rules to
+// every audit event before handing it to the inner emitter.
+func NewRedactingEmitter(cfg RedactingEmitterConfig) (*RedactingEmitter, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	compiled := make([]compiledRedaction, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		if rule.Replace == "" {
+			rule.Replace = redactionDefaultReplace
+		}
+		compiled = append(compiled, compiledRedaction{
+			RedactionRule: rule,
+			expr:          regexp.MustCompile(rule.Pattern),
+		})
+	}
+	return &RedactingEmitter{
+		RedactingEmitterConfig: cfg,
+		compiled:               compiled,
+	}, nil
+}
+
+// RedactingEmitter rewrites string fields of audit events matching the
+// configured redaction rules before the events reach the inner emitter.
+type RedactingEmitter struct {
+	RedactingEmitterConfig
+	compiled []compiledRedaction
+}
+
+// EmitAuditEvent applies the redaction rules to the event and forwards it.
+// Events that cannot be processed are forwarded unredacted rather than
+// dropped: losing audit records is worse than missing one substitution.
+func (r *RedactingEmitter) EmitAuditEvent(ctx context.Context, event AuditEvent) error {
+	if err := r.redact(event); err != nil {
+		log.WithError(err).Warnf("Failed to redact event %v.", event.GetType())
+	}
+	return trace.Wrap(r.Inner.EmitAuditEvent(ctx, event))
+}
+
+// redact round-trips the event through its JSON representation, rewriting
+// string values in place when a rule matches.
+func (r *RedactingEmitter) redact(event AuditEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return trace.Wrap(err)
+	}
+	if !r.redactMap(fields) {
+		return nil
+	}
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(json.Unmarshal(data, event))
+}
+
+// redactMap walks the field map recursively, returns true if any value
+// was rewritten.
+func (r *RedactingEmitter) redactMap(fields map[string]interface{}) bool {
+	changed := false
+	for key, value := range fields {
+		switch typed := value.(type) {
+		case string:
+			if redacted, ok := r.redactString(key, typed); ok {
+				fields[key] = redacted
+				changed = true
+			}
+		case map[string]interface{}:
+			if r.redactMap(typed) {
+				changed = true
+			}
+		case []interface{}:
+			for i, element := range typed {
+				switch item := element.(type) {
+				case string:
+					if redacted, ok := r.redactString(key, item); ok {
+						typed[i] = redacted
+						changed = true
+					}
+				case map[string]interface{}:
+					if r.redactMap(item) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return changed
+}
+
+// redactString applies every applicable rule to a single field value.
+func (r *RedactingEmitter) redactString(key string, value string) (string, bool) {
+	changed := false
+	for _, rule := range r.compiled {
+		if len(rule.Fields) != 0 && !utils.SliceContainsStr(rule.Fields, key) {
+			continue
+		}
+		redacted := rule.expr.ReplaceAllString(value, rule.Replace)
+		if redacted != value {
+			value = redacted
+			changed = true
+		}
+	}
+	return value, changed
+}
@@ -54,6 +54,20 @@ type Config struct {
 	Insecure bool
 	//DisableServerSideEncryption is an optional switch to opt out of SSE in case the provider does not support it
 	DisableServerSideEncryption bool
+	// ArchiveAfterDays if set, transitions session recordings older than the
+	// given number of days to the Glacier storage tier via a bucket lifecycle
+	// rule. Archived recordings are restored on demand during playback.
+	ArchiveAfterDays int64
+	// SSEKMSKey is an optional customer managed KMS key id or ARN used for
+	// server side encryption instead of the AWS managed default key
+	SSEKMSKey string
+	// ACL is an optional canned ACL applied to uploaded recordings, for
+	// example "bucket-owner-full-control" when the bucket is owned by
+	// another account
+	ACL string
+	// UploadPartSizeBytes is an optional part size for multipart uploads,
+	// larger parts keep very long sessions under the S3 part count limit
+	UploadPartSizeBytes int64
 	// Session is an optional existing AWS client session
 	Session *awssession.Session
 	// Credentials if supplied are used in tests
@@ -83,6 +97,26 @@ func (s *Config) SetFromURL(in *url.URL, inRegion string) error {
 		}
 		s.DisableServerSideEncryption = disableServerSideEncryption
 	}
+	if val := in.Query().Get(teleport.ArchiveAfterDays); val != "" {
+		archiveAfterDays, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || archiveAfterDays <= 0 {
+			return trace.BadParameter("failed to parse URI %q flag %q - %q, expected a positive number of days", in.String(), teleport.ArchiveAfterDays, val)
+		}
+		s.ArchiveAfterDays = archiveAfterDays
+	}
+	if val := in.Query().Get(teleport.SSEKMSKey); val != "" {
+		s.SSEKMSKey = val
+	}
+	if val := in.Query().Get(teleport.S3ACL); val != "" {
+		s.ACL = val
+	}
+	if val := in.Query().Get(teleport.UploadPartSize); val != "" {
+		partSize, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return trace.BadParameter("failed to parse URI %q flag %q - %q, expected a part size in bytes", in.String(), teleport.UploadPartSize, val)
+		}
+		s.UploadPartSizeBytes = partSize
+	}
 	s.Region = region
 	s.Bucket = in.Host
 	s.Path = in.Path
@@ -94,6 +128,12 @@ func (s *Config) CheckAndSetDefaults() error {
 	if s.Bucket == "" {
 		return trace.BadParameter("missing parameter Bucket")
 	}
+	if s.SSEKMSKey != "" && s.DisableServerSideEncryption {
+		return trace.BadParameter("SSE KMS key %q can not be used with server side encryption disabled", s.SSEKMSKey)
+	}
+	if s.UploadPartSizeBytes != 0 && s.UploadPartSizeBytes < s3manager.MinUploadPartSize {
+		return trace.BadParameter("upload part size must be at least %v bytes", s3manager.MinUploadPartSize)
+	}
 	if s.Session == nil {
 		// create an AWS session using default SDK behavior, i.e. it will interpret
 		// the environment and ~/.aws directory just like an AWS CLI tool would:
@@ -133,8 +173,12 @@ func NewHandler(cfg Config) (*Handler, error) {
 		Entry: log.WithFields(log.Fields{
 			trace.Component: teleport.Component(teleport.SchemeS3),
 		}),
-		Config:     cfg,
-		uploader:   s3manager.NewUploader(cfg.Session),
+		Config: cfg,
+		uploader: s3manager.NewUploader(cfg.Session, func(u *s3manager.Uploader) {
+			if cfg.UploadPartSizeBytes != 0 {
+				u.PartSize = cfg.UploadPartSizeBytes
+			}
+		}),
 		downloader: s3manager.NewDownloader(cfg.Session),
 		client:     s3.New(cfg.Session),
 	}
@@ -143,6 +187,13 @@ func NewHandler(cfg Config) (*Handler, error) {
 	if err := h.ensureBucket(); err != nil {
 		return nil, trace.Wrap(err)
 	}
+	if cfg.ArchiveAfterDays > 0 {
+		if err := h.ensureLifecycle(); err != nil {
+			// Archival is best effort, deployments that gave Teleport
+			// write-only access manage the lifecycle rules themselves.
+			h.Errorf("Failed to set up archival lifecycle rule for bucket %q: %v.", h.Bucket, err)
+		}
+	}
 	h.WithFields(log.Fields{"duration": time.Since(start)}).Infof("Setup bucket %q completed.", h.Bucket)
 	return h, nil
 }
@@ -176,6 +227,12 @@ func (h *Handler) Upload(ctx context.Context, sessionID session.ID, reader io.Re
 	}
 	if !h.Config.DisableServerSideEncryption {
 		uploadInput.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		if h.Config.SSEKMSKey != "" {
+			uploadInput.SSEKMSKeyId = aws.String(h.Config.SSEKMSKey)
+		}
+	}
+	if h.Config.ACL != "" {
+		uploadInput.ACL = aws.String(h.Config.ACL)
 	}
 	_, err = h.uploader.UploadWithContext(ctx, uploadInput)
 	if err != nil {
@@ -203,6 +260,11 @@ func (h *Handler) Download(ctx context.Context, sessionID session.ID, writer io.
 		VersionId: aws.String(versionID),
 	})
 	if err != nil {
+		// If the recording has been transitioned to the archive tier,
+		// request a restore and ask the caller to retry later.
+		if isArchivedObjectError(err) {
+			return trace.Wrap(h.startRestore(sessionID, versionID))
+		}
 		return ConvertS3Error(err)
 	}
 	if written == 0 {
@@ -211,6 +273,72 @@ func (h *Handler) Download(ctx context.Context, sessionID session.ID, writer io.
 	return nil
 }
 
+// restoreExpiryDays is how long a restored copy of an archived recording
+// stays available in the standard tier before S3 removes it again.
+const restoreExpiryDays = 7
+
+// startRestore requests a restore of an archived session recording back into
+// the standard storage tier. Restores take minutes to hours depending on the
+// tier, so the returned error asks the caller to retry playback later.
+func (h *Handler) startRestore(sessionID session.ID, versionID string) error {
+	h.Infof("Requesting restore of archived recording %v/%v.", h.Bucket, h.path(sessionID))
+	_, err := h.client.RestoreObject(&s3.RestoreObjectInput{
+		Bucket:    aws.String(h.Bucket),
+		Key:       aws.String(h.path(sessionID)),
+		VersionId: aws.String(versionID),
+		RestoreRequest: &s3.RestoreRequest{
+			Days: aws.Int64(restoreExpiryDays),
+			GlacierJobParameters: &s3.GlacierJobParameters{
+				Tier: aws.String(s3.TierStandard),
+			},
+		},
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != "RestoreAlreadyInProgress" {
+			return ConvertS3Error(err)
+		}
+	}
+	return trace.ConnectionProblem(nil,
+		"session recording %v is archived and is being restored, retry playback in a few minutes", sessionID)
+}
+
+// isArchivedObjectError returns true if the error indicates the object has
+// been transitioned to an archive storage tier and has to be restored before
+// it can be read.
+func isArchivedObjectError(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == "InvalidObjectState"
+	}
+	return false
+}
+
+// ensureLifecycle sets up a bucket lifecycle rule that transitions session
+// recordings older than ArchiveAfterDays to the Glacier storage tier. Object
+// metadata stays in place, so recordings remain listable while archived.
+func (h *Handler) ensureLifecycle() error {
+	_, err := h.client.PutBucketLifecycleConfiguration(&s3.PutBucketLifecycleConfigurationInput{
+		Bucket: aws.String(h.Bucket),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{
+			Rules: []*s3.LifecycleRule{{
+				ID:     aws.String("teleport-archive-sessions"),
+				Status: aws.String("Enabled"),
+				Filter: &s3.LifecycleRuleFilter{
+					Prefix: aws.String(strings.TrimPrefix(h.Path, "/")),
+				},
+				Transitions: []*s3.Transition{{
+					Days:         aws.Int64(h.ArchiveAfterDays),
+					StorageClass: aws.String(s3.TransitionStorageClassGlacier),
+				}},
+			}},
+		},
+	})
+	if err != nil {
+		return ConvertS3Error(err)
+	}
+	h.Infof("Recordings in bucket %q transition to %v after %v days.", h.Bucket, s3.TransitionStorageClassGlacier, h.ArchiveAfterDays)
+	return nil
+}
+
 // versionID is used to store versions of a key to allow sorting by timestamp.
 type versionID struct {
 	// ID is the version ID.
@@ -332,13 +460,17 @@ func (h *Handler) ensureBucket() error {
 
 	// Turn on server-side encryption for the bucket.
 	if !h.DisableServerSideEncryption {
+		applyByDefault := &s3.ServerSideEncryptionByDefault{
+			SSEAlgorithm: aws.String(s3.ServerSideEncryptionAwsKms),
+		}
+		if h.SSEKMSKey != "" {
+			applyByDefault.KMSMasterKeyID = aws.String(h.SSEKMSKey)
+		}
 		_, err = h.client.PutBucketEncryption(&s3.PutBucketEncryptionInput{
 			Bucket: aws.String(h.Bucket),
 			ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
 				Rules: []*s3.ServerSideEncryptionRule{&s3.ServerSideEncryptionRule{
-					ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
-						SSEAlgorithm: aws.String(s3.ServerSideEncryptionAwsKms),
-					},
+					ApplyServerSideEncryptionByDefault: applyByDefault,
 				}},
 			},
 		})
@@ -45,6 +45,12 @@ func (h *Handler) CreateUpload(ctx context.Context, sessionID session.ID) (*even
 	}
 	if !h.Config.DisableServerSideEncryption {
 		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		if h.Config.SSEKMSKey != "" {
+			input.SSEKMSKeyId = aws.String(h.Config.SSEKMSKey)
+		}
+	}
+	if h.Config.ACL != "" {
+		input.ACL = aws.String(h.Config.ACL)
 	}
 
 	resp, err := h.client.CreateMultipartUploadWithContext(ctx, input)
@@ -0,0 +1,162 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/teleport"
+)
+
+const (
+	// FormatJSON serializes forwarded events as JSON objects
+	FormatJSON = "json"
+	// FormatCEF serializes forwarded events in the ArcSight
+	// Common Event Format
+	FormatCEF = "cef"
+	// FormatLEEF serializes forwarded events in the QRadar
+	// Log Event Extended Format
+	FormatLEEF = "leef"
+
+	// siemVendor is the vendor reported in CEF/LEEF headers
+	siemVendor = "Gravitational"
+	// siemProduct is the product reported in CEF/LEEF headers
+	siemProduct = "Teleport"
+)
+
+// siemFieldNames maps well known audit event fields to their standard
+// CEF/LEEF extension names, all other fields are passed through with
+// their Teleport names.
+var siemFieldNames = map[string]string{
+	EventUser:              "suser",
+	EventLogin:             "duser",
+	RemoteAddr:             "src",
+	LocalAddr:              "dst",
+	EventTime:              "end",
+	SessionPrintEventBytes: "out",
+}
+
+// MarshalCEF serializes the event fields in the ArcSight Common Event
+// Format: a pipe-delimited header identifying the event followed by
+// space-delimited key=value extensions.
+func MarshalCEF(fields EventFields) string {
+	header := []string{
+		"CEF:0",
+		cefEscapeHeader(siemVendor),
+		cefEscapeHeader(siemProduct),
+		cefEscapeHeader(teleport.Version),
+		cefEscapeHeader(fields.GetCode()),
+		cefEscapeHeader(fields.GetType()),
+		fmt.Sprintf("%v", siemSeverity(fields.GetCode())),
+	}
+	var extensions []string
+	for _, key := range siemFieldOrder(fields) {
+		extensions = append(extensions, fmt.Sprintf("%v=%v",
+			siemFieldName(key), cefEscapeExtension(fmt.Sprintf("%v", fields[key]))))
+	}
+	return strings.Join(header, "|") + "|" + strings.Join(extensions, " ")
+}
+
+// MarshalLEEF serializes the event fields in the QRadar Log Event
+// Extended Format: a pipe-delimited header followed by tab-delimited
+// key=value attributes.
+func MarshalLEEF(fields EventFields) string {
+	header := []string{
+		"LEEF:1.0",
+		leefEscapeHeader(siemVendor),
+		leefEscapeHeader(siemProduct),
+		leefEscapeHeader(teleport.Version),
+		leefEscapeHeader(fields.GetType()),
+	}
+	var attributes []string
+	for _, key := range siemFieldOrder(fields) {
+		attributes = append(attributes, fmt.Sprintf("%v=%v",
+			siemFieldName(key), leefEscapeAttribute(fmt.Sprintf("%v", fields[key]))))
+	}
+	return strings.Join(header, "|") + "|" + strings.Join(attributes, "\t")
+}
+
+// siemFieldOrder returns the event field names in a stable order,
+// skipping the fields already present in the header
+func siemFieldOrder(fields EventFields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key == EventType || key == EventCode {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// siemFieldName maps a field name to its standard CEF/LEEF counterpart
+func siemFieldName(key string) string {
+	if name, ok := siemFieldNames[key]; ok {
+		return name
+	}
+	return key
+}
+
+// siemSeverity derives a 0-10 CEF severity from the event code
+// suffix: informational events map to 3, warnings to 5, errors to 8
+func siemSeverity(code string) int {
+	switch {
+	case strings.HasSuffix(code, "W"):
+		return 5
+	case strings.HasSuffix(code, "E"):
+		return 8
+	default:
+		return 3
+	}
+}
+
+// cefEscapeHeader escapes pipes and backslashes in CEF header values
+func cefEscapeHeader(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "|", `\|`, -1)
+	return stripNewlines(value)
+}
+
+// cefEscapeExtension escapes equal signs and backslashes in CEF
+// extension values
+func cefEscapeExtension(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "=", `\=`, -1)
+	return stripNewlines(value)
+}
+
+// leefEscapeHeader strips delimiters from LEEF header values
+func leefEscapeHeader(value string) string {
+	value = strings.Replace(value, "|", " ", -1)
+	return stripNewlines(value)
+}
+
+// leefEscapeAttribute strips delimiters from LEEF attribute values
+func leefEscapeAttribute(value string) string {
+	value = strings.Replace(value, "\t", " ", -1)
+	return stripNewlines(value)
+}
+
+// stripNewlines replaces newlines with spaces, both formats are
+// single-line
+func stripNewlines(value string) string {
+	value = strings.Replace(value, "\r", " ", -1)
+	return strings.Replace(value, "\n", " ", -1)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gravitational/teleport"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMarshalCEF verifies the CEF header, field name mapping and
+// escaping of delimiter characters.
+func TestMarshalCEF(t *testing.T) {
+	fields := EventFields{
+		EventType:  SessionStartEvent,
+		EventCode:  SessionStartCode,
+		EventUser:  "alice",
+		EventLogin: "root",
+		RemoteAddr: "10.0.0.1:3022",
+	}
+	assert.Equal(t, fmt.Sprintf(
+		"CEF:0|Gravitational|Teleport|%v|%v|%v|3|src=10.0.0.1:3022 duser=root suser=alice",
+		teleport.Version, SessionStartCode, SessionStartEvent), MarshalCEF(fields))
+
+	// pipes in header values and equal signs in extension values are escaped
+	escaped := EventFields{
+		EventType: "bad|type",
+		EventCode: "T0000E",
+		EventUser: "a=b\nc",
+	}
+	assert.Equal(t, fmt.Sprintf(
+		`CEF:0|Gravitational|Teleport|%v|T0000E|bad\|type|8|suser=a\=b c`,
+		teleport.Version), MarshalCEF(escaped))
+}
+
+// TestMarshalLEEF verifies the LEEF header and tab-delimited attributes.
+func TestMarshalLEEF(t *testing.T) {
+	fields := EventFields{
+		EventType:  SessionEndEvent,
+		EventCode:  SessionEndCode,
+		EventUser:  "alice",
+		EventLogin: "root",
+	}
+	assert.Equal(t, fmt.Sprintf(
+		"LEEF:1.0|Gravitational|Teleport|%v|%v|duser=root\tsuser=alice",
+		teleport.Version, SessionEndEvent), MarshalLEEF(fields))
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/gravitational/teleport/lib/session"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// TeeHandlerConfig provides parameters for the tee handler
+type TeeHandlerConfig struct {
+	// Primary is the authoritative handler, usually external storage
+	Primary MultipartHandler
+	// Backup is the redundant handler, usually local disk, it receives
+	// a best-effort copy of everything written to the primary
+	Backup MultipartHandler
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *TeeHandlerConfig) CheckAndSetDefaults() error {
+	if c.Primary == nil {
+		return trace.BadParameter("missing parameter Primary")
+	}
+	if c.Backup == nil {
+		return trace.BadParameter("missing parameter Backup")
+	}
+	return nil
+}
+
+// NewTeeHandler returns a handler that writes session recordings to both
+// the primary (usually external) handler and a backup (usually local disk)
+// handler. The primary is authoritative: its errors are returned to the
+// caller, while backup failures are logged and do not fail the operation.
+// If an upload to the primary fails after the backup copy has been written,
+// the recording survives on the backup and can be reconciled later with
+// `tctl recordings sync`.
+func NewTeeHandler(cfg TeeHandlerConfig) (*TeeHandler, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &TeeHandler{
+		TeeHandlerConfig: cfg,
+		backupUploads:    make(map[string]*StreamUpload),
+	}, nil
+}
+
+// TeeHandler duplicates session recording writes to a backup handler
+type TeeHandler struct {
+	TeeHandlerConfig
+
+	// mtx protects backupUploads
+	mtx sync.Mutex
+	// backupUploads maps primary upload IDs to in-flight backup uploads
+	backupUploads map[string]*StreamUpload
+}
+
+// Upload writes the session tarball to the backup handler first so a local
+// copy exists before the primary upload is attempted, then uploads it to
+// the primary. If the primary upload fails, the backup URL is returned and
+// the error is logged so the session can be reconciled later.
+func (t *TeeHandler) Upload(ctx context.Context, sessionID session.ID, reader io.Reader) (string, error) {
+	backupURL, err := t.Backup.Upload(ctx, sessionID, reader)
+	if err != nil {
+		return "", trace.Wrap(err)
+	}
+
+	// Re-read the session from the backup copy, the reader has been
+	// consumed by the backup upload.
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(t.Backup.Download(ctx, sessionID, writerAt{pw}))
+	}()
+	url, err := t.Primary.Upload(ctx, sessionID, pr)
+	if err != nil {
+		log.WithError(err).Warningf(
+			"Failed to upload session %v to primary storage, local copy kept at %v.", sessionID, backupURL)
+		return backupURL, nil
+	}
+	return url, nil
+}
+
+// Download downloads the session from the primary handler, falling back to
+// the backup copy if the primary fails.
+func (t *TeeHandler) Download(ctx context.Context, sessionID session.ID, writer io.WriterAt) error {
+	err := t.Primary.Download(ctx, sessionID, writer)
+	if err == nil {
+		return nil
+	}
+	if backupErr := t.Backup.Download(ctx, sessionID, writer); backupErr == nil {
+		log.WithError(err).Warningf(
+			"Failed to download session %v from primary storage, used local copy.", sessionID)
+		return nil
+	}
+	return trace.Wrap(err)
+}
+
+// CreateUpload creates a multipart upload on the primary handler and a
+// best-effort mirror upload on the backup handler.
+func (t *TeeHandler) CreateUpload(ctx context.Context, sessionID session.ID) (*StreamUpload, error) {
+	upload, err := t.Primary.CreateUpload(ctx, sessionID)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	backupUpload, err := t.Backup.CreateUpload(ctx, sessionID)
+	if err != nil {
+		log.WithError(err).Warningf(
+			"Failed to create backup upload for session %v, writing to primary storage only.", sessionID)
+		return upload, nil
+	}
+	t.mtx.Lock()
+	t.backupUploads[upload.ID] = backupUpload
+	t.mtx.Unlock()
+	return upload, nil
+}
+
+// UploadPart uploads the part to the primary handler and mirrors it to the
+// backup handler.
+func (t *TeeHandler) UploadPart(ctx context.Context, upload StreamUpload, partNumber int64, partBody io.ReadSeeker) (*StreamPart, error) {
+	part, err := t.Primary.UploadPart(ctx, upload, partNumber, partBody)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	backupUpload := t.getBackupUpload(upload.ID)
+	if backupUpload == nil {
+		return part, nil
+	}
+	if _, err := partBody.Seek(0, io.SeekStart); err != nil {
+		log.WithError(err).Warningf("Failed to rewind part %v of session %v for backup.", partNumber, upload.SessionID)
+		return part, nil
+	}
+	if _, err := t.Backup.UploadPart(ctx, *backupUpload, partNumber, partBody); err != nil {
+		log.WithError(err).Warningf("Failed to write part %v of session %v to backup storage.", partNumber, upload.SessionID)
+	}
+	return part, nil
+}
+
+// CompleteUpload completes the upload on the primary handler and the mirror
+// upload on the backup handler.
+func (t *TeeHandler) CompleteUpload(ctx context.Context, upload StreamUpload, parts []StreamPart) error {
+	if err := t.Primary.CompleteUpload(ctx, upload, parts); err != nil {
+		return trace.Wrap(err)
+	}
+	backupUpload := t.getBackupUpload(upload.ID)
+	if backupUpload == nil {
+		return nil
+	}
+	t.mtx.Lock()
+	delete(t.backupUploads, upload.ID)
+	t.mtx.Unlock()
+	backupParts, err := t.Backup.ListParts(ctx, *backupUpload)
+	if err == nil {
+		err = t.Backup.CompleteUpload(ctx, *backupUpload, backupParts)
+	}
+	if err != nil {
+		log.WithError(err).Warningf("Failed to complete backup upload for session %v.", upload.SessionID)
+	}
+	return nil
+}
+
+// ListParts lists parts of the primary upload
+func (t *TeeHandler) ListParts(ctx context.Context, upload StreamUpload) ([]StreamPart, error) {
+	parts, err := t.Primary.ListParts(ctx, upload)
+	return parts, trace.Wrap(err)
+}
+
+// ListUploads lists uploads of the primary handler
+func (t *TeeHandler) ListUploads(ctx context.Context) ([]StreamUpload, error) {
+	uploads, err := t.Primary.ListUploads(ctx)
+	return uploads, trace.Wrap(err)
+}
+
+// getBackupUpload returns the backup upload mirroring the primary upload
+// with the given ID, nil if there is none.
+func (t *TeeHandler) getBackupUpload(uploadID string) *StreamUpload {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.backupUploads[uploadID]
+}
+
+// writerAt adapts a sequential writer to the io.WriterAt accepted by
+// Download, offsets are ignored as handlers write sequentially.
+type writerAt struct {
+	io.Writer
+}
+
+func (w writerAt) WriteAt(p []byte, off int64) (int, error) {
+	return w.Writer.Write(p)
+}
@@ -45,6 +45,13 @@ type UploadHandler interface {
 	Download(ctx context.Context, sessionID session.ID, writer io.WriterAt) error
 }
 
+// RecordingDeleter is implemented by upload handlers that support
+// deleting stored session recordings, e.g. to enforce retention policies
+type RecordingDeleter interface {
+	// DeleteRecording deletes the stored recording of the session
+	DeleteRecording(ctx context.Context, sessionID session.ID) error
+}
+
 // MultipartHandler handles both multipart uploads and downloads
 type MultipartHandler interface {
 	UploadHandler
@@ -0,0 +1,184 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/gravitational/teleport/lib/defaults"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// WebhookEmitterConfig provides parameters for the webhook emitter
+type WebhookEmitterConfig struct {
+	// Context is a context to signal shutdown of the delivery goroutine
+	Context context.Context
+	// URL is the endpoint events are POSTed to as JSON
+	URL string
+	// Types is an optional list of event types to deliver,
+	// when empty all events are delivered
+	Types []string
+	// Client is an optional HTTP client, used in tests
+	Client *http.Client
+	// QueueSize is the size of the delivery queue, events are
+	// dropped (with a warning) when the queue is full so that
+	// notifications can never block or fail the audit path
+	QueueSize int
+	// Format is the serialization format for delivered events,
+	// one of "json" (default), "cef" or "leef"
+	Format string
+}
+
+// CheckAndSetDefaults checks and sets default values
+func (c *WebhookEmitterConfig) CheckAndSetDefaults() error {
+	if c.URL == "" {
+		return trace.BadParameter("missing parameter URL")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return trace.BadParameter("failed to parse webhook URL %q: %v", c.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return trace.BadParameter("unsupported webhook URL scheme %q, use http or https", u.Scheme)
+	}
+	if c.Context == nil {
+		c.Context = context.Background()
+	}
+	if c.Client == nil {
+		c.Client = &http.Client{Timeout: defaults.DefaultDialTimeout}
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = defaults.NodeQueueSize
+	}
+	switch c.Format {
+	case "":
+		c.Format = FormatJSON
+	case FormatJSON, FormatCEF, FormatLEEF:
+	default:
+		return trace.BadParameter("unsupported webhook format %q, supported formats are: %v, %v, %v",
+			c.Format, FormatJSON, FormatCEF, FormatLEEF)
+	}
+	return nil
+}
+
+// NewWebhookEmitter returns an emitter that delivers matching audit
+// events to an HTTP endpoint. Delivery is asynchronous and best-effort:
+// events are dropped when the endpoint can not keep up.
+func NewWebhookEmitter(cfg WebhookEmitterConfig) (*WebhookEmitter, error) {
+	if err := cfg.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	types := make(map[string]bool, len(cfg.Types))
+	for _, eventType := range cfg.Types {
+		types[eventType] = true
+	}
+	w := &WebhookEmitter{
+		WebhookEmitterConfig: cfg,
+		types:                types,
+		eventsC:              make(chan AuditEvent, cfg.QueueSize),
+	}
+	go w.forward()
+	return w, nil
+}
+
+// WebhookEmitter posts audit events to an HTTP endpoint
+type WebhookEmitter struct {
+	WebhookEmitterConfig
+	types   map[string]bool
+	eventsC chan AuditEvent
+}
+
+// EmitAuditEvent queues the event for delivery if its type matches,
+// it never blocks the caller
+func (w *WebhookEmitter) EmitAuditEvent(ctx context.Context, event AuditEvent) error {
+	if len(w.types) != 0 && !w.types[event.GetType()] {
+		return nil
+	}
+	select {
+	case w.eventsC <- event:
+	default:
+		log.Warnf("Webhook queue overflow, dropping event %v.", event.GetType())
+	}
+	return nil
+}
+
+// forward delivers queued events until the context is closed
+func (w *WebhookEmitter) forward() {
+	for {
+		select {
+		case <-w.Context.Done():
+			return
+		case event := <-w.eventsC:
+			if err := w.deliver(event); err != nil {
+				log.WithError(err).Warnf("Failed to deliver webhook for event %v.", event.GetType())
+			}
+		}
+	}
+}
+
+// deliver posts a single event to the endpoint
+func (w *WebhookEmitter) deliver(event AuditEvent) error {
+	data, contentType, err := w.serialize(event)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	ctx, cancel := context.WithTimeout(w.Context, defaults.DefaultDialTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", contentType)
+	re, err := w.Client.Do(req)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer re.Body.Close()
+	if re.StatusCode < http.StatusOK || re.StatusCode >= http.StatusMultipleChoices {
+		return trace.BadParameter("webhook endpoint returned status %v", re.StatusCode)
+	}
+	return nil
+}
+
+// serialize renders the event in the configured format and returns
+// the payload along with its content type
+func (w *WebhookEmitter) serialize(event AuditEvent) ([]byte, string, error) {
+	switch w.Format {
+	case FormatCEF, FormatLEEF:
+		fields, err := ToEventFields(event)
+		if err != nil {
+			return nil, "", trace.Wrap(err)
+		}
+		if w.Format == FormatCEF {
+			return []byte(MarshalCEF(fields)), "text/plain", nil
+		}
+		return []byte(MarshalLEEF(fields)), "text/plain", nil
+	default:
+		data, err := json.Marshal(event)
+		if err != nil {
+			return nil, "", trace.Wrap(err)
+		}
+		return data, "application/json", nil
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWebhookEmitter verifies that matching events are delivered to the
+// endpoint and events outside the configured types are filtered out.
+func TestWebhookEmitter(t *testing.T) {
+	receivedC := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var fields EventFields
+		assert.NoError(t, json.Unmarshal(data, &fields))
+		receivedC <- fields.GetType()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	emitter, err := NewWebhookEmitter(WebhookEmitterConfig{
+		Context: ctx,
+		URL:     server.URL,
+		Types:   []string{SessionStartEvent},
+	})
+	assert.NoError(t, err)
+
+	events := GenerateTestSession(SessionParams{PrintEvents: 1})
+	for _, event := range events {
+		assert.NoError(t, emitter.EmitAuditEvent(ctx, event))
+	}
+
+	select {
+	case eventType := <-receivedC:
+		assert.Equal(t, SessionStartEvent, eventType)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for webhook delivery")
+	}
+	// print and end events were filtered out
+	select {
+	case eventType := <-receivedC:
+		t.Fatalf("unexpected delivery of filtered event %v", eventType)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// bad URLs are rejected outright
+	_, err = NewWebhookEmitter(WebhookEmitterConfig{URL: "ftp://example.com"})
+	assert.Error(t, err)
+}
@@ -83,6 +83,18 @@ func (w *WriterLog) UploadSessionRecording(r SessionRecording) error {
 	return trace.NotImplemented("not implemented")
 }
 
+// DownloadSessionRecording downloads the stored session recording tarball
+// from the session storage handler and writes it to writer
+func (w *WriterLog) DownloadSessionRecording(namespace string, sid session.ID, writer io.Writer) error {
+	return trace.NotImplemented("not implemented")
+}
+
+// DeleteSessionRecording deletes the stored session recording from the
+// session storage handler
+func (w *WriterLog) DeleteSessionRecording(namespace string, sid session.ID) error {
+	return trace.NotImplemented("not implemented")
+}
+
 // GetSessionChunk returns a reader which can be used to read a byte stream
 // of a recorded session starting from 'offsetBytes' (pass 0 to start from the
 // beginning) up to maxBytes bytes.
@@ -29,9 +29,20 @@ const (
 	// DefaultStreamCreationTimeout
 	DefaultStreamCreationTimeout = 30 * time.Second
 
+	// IdleTimeout is the default streaming connection idle timeout,
+	// used when the cluster keep-alive settings are not available
 	IdleTimeout = 15 * time.Minute
 )
 
+// adjustIdleTimeout returns the streaming connection idle timeout to use,
+// falling back to the default when keep-alive settings are not set
+func adjustIdleTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return IdleTimeout
+	}
+	return timeout
+}
+
 // These constants are for remote command execution and port forwarding and are
 // used by both the client side and server side components.
 //
@@ -466,10 +466,74 @@ func (f *Forwarder) newStreamer(ctx *authContext) (events.Streamer, error) {
 	return events.NewTeeStreamer(fileStreamer, f.Client), nil
 }
 
+// acquireConnectionLock acquires a semaphore lease used to limit concurrent
+// connections for the user across the cluster. It returns a nil lock if the
+// user's roles do not define max_connections.
+func (f *Forwarder) acquireConnectionLock(reqCtx context.Context, ctx *authContext, req *http.Request) (*services.SemaphoreLock, error) {
+	maxConnections := ctx.Checker.MaxConnections()
+	if maxConnections == 0 {
+		return nil, nil
+	}
+	user := ctx.User.GetName()
+	lock, err := services.AcquireSemaphoreLock(reqCtx, services.SemaphoreLockConfig{
+		Service: f.Client,
+		Expiry:  ctx.clusterConfig.GetSessionControlTimeout(),
+		Params: services.AcquireSemaphoreRequest{
+			SemaphoreKind: services.SemaphoreKindConnection,
+			SemaphoreName: user,
+			MaxLeases:     maxConnections,
+			Holder:        f.ServerID,
+		},
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), teleport.MaxLeases) {
+			// user has exceeded their max concurrent kubernetes connections.
+			if err := f.Client.EmitAuditEvent(f.Context, &events.SessionReject{
+				Metadata: events.Metadata{
+					Type: events.SessionRejectedEvent,
+					Code: events.SessionRejectedCode,
+				},
+				UserMetadata: events.UserMetadata{
+					User: user,
+				},
+				ConnectionMetadata: events.ConnectionMetadata{
+					Protocol:   events.EventProtocolKube,
+					RemoteAddr: req.RemoteAddr,
+				},
+				ServerMetadata: events.ServerMetadata{
+					ServerID:        f.ServerID,
+					ServerNamespace: f.Namespace,
+				},
+				Reason:  events.SessionRejectedReasonMaxConnections,
+				Maximum: maxConnections,
+			}); err != nil {
+				f.WithError(err).Warn("Failed to emit session reject event.")
+			}
+			err = trace.AccessDenied("too many concurrent kubernetes connections for user %q (max=%d)",
+				user, maxConnections)
+		}
+		return nil, trace.Wrap(err)
+	}
+	return lock, nil
+}
+
 // exec forwards all exec requests to the target server, captures
 // all output from the session
 func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Request, p httprouter.Params) (interface{}, error) {
 	f.Debugf("Exec %v.", req.URL.String())
+
+	// Apply concurrent session control limits, if the user's roles
+	// define any. The lease is shared with SSH connections, capping the
+	// user's total concurrent connections across the cluster.
+	lock, err := f.acquireConnectionLock(req.Context(), ctx, req)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if lock != nil {
+		defer lock.Stop()
+		go lock.KeepAlive(req.Context())
+	}
+
 	q := req.URL.Query()
 	request := remoteCommandRequest{
 		podNamespace:       p.ByName("podNamespace"),
@@ -484,12 +548,12 @@ func (f *Forwarder) exec(ctx *authContext, w http.ResponseWriter, req *http.Requ
 		httpResponseWriter: w,
 		context:            req.Context(),
 		pingPeriod:         f.PingPeriod,
+		idleTimeout:        keepAliveIdleTimeout(ctx.clusterConfig),
 	}
 
 	var recorder events.SessionRecorder
 	var emitter events.Emitter
 	sessionID := session.NewID()
-	var err error
 	if request.tty {
 		streamer, err := f.newStreamer(ctx)
 		if err != nil {
@@ -763,6 +827,7 @@ func (f *Forwarder) portForward(ctx *authContext, w http.ResponseWriter, req *ht
 		onPortForward:      onPortForward,
 		targetDialer:       dialer,
 		pingPeriod:         f.PingPeriod,
+		idleTimeout:        keepAliveIdleTimeout(ctx.clusterConfig),
 	}
 	f.Debugf("Starting %v.", request)
 	err = runPortForwarding(request)
@@ -773,6 +838,22 @@ func (f *Forwarder) portForward(ctx *authContext, w http.ResponseWriter, req *ht
 	return nil, nil
 }
 
+// keepAliveIdleTimeout returns the streaming connection idle timeout derived
+// from the cluster keep-alive settings, so dead connections are reaped once
+// the configured number of keep-alives is missed. Returns 0 if keep-alives
+// are not configured, callers fall back to the default idle timeout.
+func keepAliveIdleTimeout(clusterConfig services.ClusterConfig) time.Duration {
+	if clusterConfig == nil {
+		return 0
+	}
+	interval := clusterConfig.GetKeepAliveInterval()
+	count := clusterConfig.GetKeepAliveCountMax()
+	if interval <= 0 || count <= 0 {
+		return 0
+	}
+	return interval * time.Duration(count)
+}
+
 const (
 	// ImpersonateHeaderPrefix is K8s impersonation prefix for impersonation feature:
 	// https://kubernetes.io/docs/reference/access-authn-authz/authentication/#user-impersonation
@@ -50,6 +50,7 @@ type remoteCommandRequest struct {
 	onResize           resizeCallback
 	context            context.Context
 	pingPeriod         time.Duration
+	idleTimeout        time.Duration
 }
 
 func createRemoteCommandProxy(req remoteCommandRequest) (*remoteCommandProxy, error) {
@@ -77,7 +78,7 @@ func createRemoteCommandProxy(req remoteCommandRequest) (*remoteCommandProxy, er
 		return nil, trace.ConnectionProblem(trace.BadParameter("missing connection"), "missing connection")
 	}
 
-	conn.SetIdleTimeout(IdleTimeout)
+	conn.SetIdleTimeout(adjustIdleTimeout(req.idleTimeout))
 
 	var handler protocolHandler
 	switch protocol {
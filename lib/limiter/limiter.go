@@ -41,6 +41,14 @@ type Config struct {
 	MaxConnections int64
 	// MaxNumberOfUsers controls maximum number of simultaneously active users
 	MaxNumberOfUsers int
+	// Allowlist is a list of IP networks in CIDR notation that are
+	// exempt from rate limiting
+	Allowlist []string
+	// TrustedHeader is an optional HTTP header (e.g. "X-Forwarded-For")
+	// to read the client IP from instead of the connection remote address.
+	// Only set this when the listener is behind a load balancer that
+	// sanitizes the header, otherwise clients can spoof their IP.
+	TrustedHeader string
 	// Clock is an optional parameter, if not set, will use system time
 	Clock timetools.TimeProvider
 }
@@ -77,6 +85,6 @@ func (l *Limiter) RegisterRequest(token string) error {
 
 // Add limiter to the handle
 func (l *Limiter) WrapHandle(h http.Handler) {
-	l.rateLimiter.Wrap(h)
+	l.rateLimiter.WrapHandle(h)
 	l.ConnLimiter.Wrap(l.rateLimiter)
 }
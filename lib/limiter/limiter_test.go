@@ -16,6 +16,7 @@ limitations under the License.
 package limiter
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
@@ -95,6 +96,72 @@ func (s *LimiterSuite) TestConnectionsLimiter(c *C) {
 	}
 }
 
+func (s *LimiterSuite) TestAllowlist(c *C) {
+	clock := &timetools.FreezedTime{
+		CurrentTime: time.Date(2016, 6, 5, 4, 3, 2, 1, time.UTC),
+	}
+
+	limiter, err := NewLimiter(
+		Config{
+			Clock:     clock,
+			Allowlist: []string{"10.0.0.0/8", "127.0.0.1/32"},
+			Rates: []Rate{
+				Rate{
+					Period:  10 * time.Millisecond,
+					Average: 10,
+					Burst:   20,
+				},
+			},
+		})
+	c.Assert(err, IsNil)
+
+	// allowlisted clients are never throttled
+	for i := 0; i < 100; i++ {
+		c.Assert(limiter.RegisterRequest("10.1.2.3"), IsNil)
+		c.Assert(limiter.RegisterRequest("127.0.0.1"), IsNil)
+	}
+
+	// other clients are subject to the configured rates
+	for i := 0; i < 20; i++ {
+		c.Assert(limiter.RegisterRequest("192.168.1.1"), IsNil)
+	}
+	c.Assert(limiter.RegisterRequest("192.168.1.1"), NotNil)
+
+	// invalid CIDR notation is rejected
+	_, err = NewLimiter(Config{Allowlist: []string{"not-a-network"}})
+	c.Assert(err, NotNil)
+}
+
+func (s *LimiterSuite) TestTrustedHeader(c *C) {
+	limiter, err := NewRateLimiter(Config{
+		TrustedHeader: "X-Forwarded-For",
+	})
+	c.Assert(err, IsNil)
+
+	// with the trusted header set, the first entry wins
+	req, err := http.NewRequest("GET", "/", nil)
+	c.Assert(err, IsNil)
+	req.RemoteAddr = "127.0.0.1:45000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 10.0.0.1")
+	ip, _, err := limiter.extractClientIP(req)
+	c.Assert(err, IsNil)
+	c.Assert(ip, Equals, "198.51.100.1")
+
+	// without the header, fall back to the connection remote address
+	req.Header.Del("X-Forwarded-For")
+	ip, _, err = limiter.extractClientIP(req)
+	c.Assert(err, IsNil)
+	c.Assert(ip, Equals, "127.0.0.1")
+
+	// header is ignored unless configured
+	limiter, err = NewRateLimiter(Config{})
+	c.Assert(err, IsNil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	ip, _, err = limiter.extractClientIP(req)
+	c.Assert(err, IsNil)
+	c.Assert(ip, Equals, "127.0.0.1")
+}
+
 func (s *LimiterSuite) TestRateLimiter(c *C) {
 	// TODO: this test fails
 	clock := &timetools.FreezedTime{
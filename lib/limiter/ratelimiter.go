@@ -18,7 +18,9 @@ package limiter
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -35,8 +37,11 @@ type RateLimiter struct {
 	*ratelimit.TokenLimiter
 	rateLimits *ttlmap.TtlMap
 	*sync.Mutex
-	rates *ratelimit.RateSet
-	clock timetools.TimeProvider
+	rates         *ratelimit.RateSet
+	clock         timetools.TimeProvider
+	allowlist     []*net.IPNet
+	trustedHeader string
+	next          http.Handler
 }
 
 // Rate defines connection rate
@@ -49,13 +54,17 @@ type Rate struct {
 // NewRateLimiter returns new request rate controller
 func NewRateLimiter(config Config) (*RateLimiter, error) {
 	limiter := RateLimiter{
-		Mutex: &sync.Mutex{},
+		Mutex:         &sync.Mutex{},
+		trustedHeader: config.TrustedHeader,
 	}
 
-	ipExtractor, err := utils.NewExtractor("client.ip")
+	allowlist, err := ParseAllowlist(config.Allowlist)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	limiter.allowlist = allowlist
+
+	ipExtractor := utils.ExtractorFunc(limiter.extractClientIP)
 
 	limiter.rates = ratelimit.NewRateSet()
 	for _, rate := range config.Rates {
@@ -95,9 +104,60 @@ func NewRateLimiter(config Config) (*RateLimiter, error) {
 	return &limiter, nil
 }
 
+// ParseAllowlist parses a list of IP networks in CIDR notation
+func ParseAllowlist(entries []string) ([]*net.IPNet, error) {
+	var allowlist []*net.IPNet
+	for _, entry := range entries {
+		_, ipnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, trace.BadParameter("invalid allowlist entry %q: %v", entry, err)
+		}
+		allowlist = append(allowlist, ipnet)
+	}
+	return allowlist, nil
+}
+
+// isAllowlisted returns true if the token is an IP address covered
+// by one of the allowlisted networks
+func (l *RateLimiter) isAllowlisted(token string) bool {
+	ip := net.ParseIP(token)
+	if ip == nil {
+		return false
+	}
+	for _, ipnet := range l.allowlist {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractClientIP returns the client IP of the request, honoring the
+// trusted header (if configured) before falling back to the connection
+// remote address
+func (l *RateLimiter) extractClientIP(req *http.Request) (string, int64, error) {
+	if l.trustedHeader != "" {
+		if value := req.Header.Get(l.trustedHeader); value != "" {
+			// the header may contain a list of proxies, the first
+			// entry is the originating client
+			if ip := strings.TrimSpace(strings.Split(value, ",")[0]); ip != "" {
+				return ip, 1, nil
+			}
+		}
+	}
+	ip, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return "", 0, trace.Wrap(err)
+	}
+	return ip, 1, nil
+}
+
 // RegisterRequest increases number of requests for the provided token
 // Returns error if there are too many requests with the provided token
 func (l *RateLimiter) RegisterRequest(token string) error {
+	if l.isAllowlisted(token) {
+		return nil
+	}
 	l.Lock()
 	defer l.Unlock()
 
@@ -128,9 +188,22 @@ func (l *RateLimiter) RegisterRequest(token string) error {
 
 // Add rate limiter to the handle
 func (l *RateLimiter) WrapHandle(h http.Handler) {
+	l.next = h
 	l.TokenLimiter.Wrap(h)
 }
 
+// ServeHTTP rate limits the request by client IP, allowlisted clients
+// bypass the limiter
+func (l *RateLimiter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if len(l.allowlist) != 0 && l.next != nil {
+		if ip, _, err := l.extractClientIP(req); err == nil && l.isAllowlisted(ip) {
+			l.next.ServeHTTP(w, req)
+			return
+		}
+	}
+	l.TokenLimiter.ServeHTTP(w, req)
+}
+
 func (r *Rate) UnmarshalJSON(value []byte) error {
 	type rate struct {
 		Period  string
@@ -284,6 +284,7 @@ func (s *remoteSite) getLastConnInfo() (services.TunnelConnection, error) {
 }
 
 func (s *remoteSite) registerHeartbeat(t time.Time) {
+	remoteClusterHeartbeatStats.WithLabelValues(s.domainName).Set(float64(t.UTC().Unix()))
 	connInfo := s.copyConnInfo()
 	connInfo.SetLastHeartbeat(t)
 	connInfo.SetExpiry(s.clock.Now().Add(s.offlineThreshold))
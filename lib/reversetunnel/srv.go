@@ -57,12 +57,28 @@ var (
 		},
 		[]string{"cluster", "state"},
 	)
+	reverseTunnelsStats = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: teleport.MetricReverseTunnels,
+			Help: "Number of reverse tunnel connections per tunnel type",
+		},
+		[]string{teleport.TagType},
+	)
+	remoteClusterHeartbeatStats = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: teleport.MetricRemoteClusterLastHeartbeat,
+			Help: "Time of the last heartbeat received from a remote cluster",
+		},
+		[]string{"cluster"},
+	)
 )
 
 func init() {
 	// Metrics have to be registered to be exposed:
 	prometheus.MustRegister(remoteClustersStats)
 	prometheus.MustRegister(trustedClustersStats)
+	prometheus.MustRegister(reverseTunnelsStats)
+	prometheus.MustRegister(remoteClusterHeartbeatStats)
 }
 
 // server is a "reverse tunnel server". it exposes the cluster capabilities
@@ -386,6 +402,7 @@ func (s *server) fetchClusterPeers() error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	reportTunnelStats(conns)
 	newConns := make(map[string]services.TunnelConnection)
 	for i := range conns {
 		newConn := conns[i]
@@ -406,6 +423,21 @@ func (s *server) fetchClusterPeers() error {
 	return s.addClusterPeers(connsToAdd)
 }
 
+// reportTunnelStats updates the per-type reverse tunnel connection gauge
+// from the current list of tunnel connections
+func reportTunnelStats(conns []services.TunnelConnection) {
+	counts := map[services.TunnelType]int{
+		services.NodeTunnel:  0,
+		services.ProxyTunnel: 0,
+	}
+	for _, conn := range conns {
+		counts[conn.GetType()]++
+	}
+	for tunnelType, count := range counts {
+		reverseTunnelsStats.WithLabelValues(string(tunnelType)).Set(float64(count))
+	}
+}
+
 func (s *server) reportClusterStats() error {
 	clusters := s.GetSites()
 	for _, cluster := range clusters {
@@ -38,6 +38,7 @@ import (
 	"github.com/gravitational/teleport/lib/limiter"
 	"github.com/gravitational/teleport/lib/pam"
 	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/srv/regular"
 	"github.com/gravitational/teleport/lib/sshca"
 	"github.com/gravitational/teleport/lib/utils"
 
@@ -175,8 +176,8 @@ type Config struct {
 	// ShutdownTimeout is set to override default shutdown timeout.
 	ShutdownTimeout time.Duration
 
-	// CAPin is the SKPI hash of the CA used to verify the Auth Server.
-	CAPin string
+	// CAPins are the SKPI hashes of the CAs used to verify the Auth Server.
+	CAPins []string
 
 	// Clock is used to control time in tests.
 	Clock clockwork.Clock
@@ -314,6 +315,10 @@ type ProxyConfig struct {
 	// EnableProxyProtocol enables proxy protocol support
 	EnableProxyProtocol bool
 
+	// ClientCertAuth allows web API calls to authenticate with client TLS
+	// certificates issued by this cluster's user certificate authority
+	ClientCertAuth bool
+
 	// WebAddr is address for web portal of the proxy
 	WebAddr utils.NetAddr
 
@@ -345,6 +350,10 @@ type ProxyConfig struct {
 
 	// Kube specifies kubernetes proxy configuration
 	Kube KubeProxyConfig
+
+	// HostTemplates rewrite requested hostnames into a node search before
+	// the proxy routes a connection.
+	HostTemplates []regular.HostTemplate
 }
 
 func (c ProxyConfig) KubeAddr() (string, error) {
@@ -505,6 +514,43 @@ type AuthConfig struct {
 
 	// PublicAddrs affects the SSH host principals and DNS names added to the SSH and TLS certs.
 	PublicAddrs []utils.NetAddr
+
+	// WebhookURL, when set, is the endpoint matching audit events are
+	// delivered to as JSON, best-effort.
+	WebhookURL string
+
+	// WebhookEvents is an optional list of audit event types delivered to
+	// the webhook, all events are delivered when empty.
+	WebhookEvents []string
+
+	// WebhookFormat is the serialization format for webhook deliveries,
+	// one of "json" (default), "cef" or "leef".
+	WebhookFormat string
+
+	// AuditAlerts is a list of predicate rules over audit events,
+	// matching events are delivered to the webhook.
+	AuditAlerts []events.AlertRule
+
+	// AuditRedaction is a list of redaction rules applied to audit events
+	// before they are persisted or forwarded
+	AuditRedaction []events.RedactionRule
+
+	// DisallowRootLogins strips root and administrator principals from
+	// issued certificates regardless of role configuration.
+	DisallowRootLogins bool
+
+	// BPFOverrides is a list of enhanced recording overrides served to
+	// nodes whose static labels match the selector.
+	BPFOverrides []bpf.ConfigOverride
+
+	// MinClientVersion is the minimum teleport version clients and agents
+	// must run to connect to this cluster, empty means the compiled-in
+	// minimum supported version.
+	MinClientVersion string
+
+	// VersionCheckMode controls what happens to clients and agents older
+	// than the minimum version, one of "enforce" or "warn".
+	VersionCheckMode string
 }
 
 // SSHConfig configures SSH server node role
@@ -518,12 +564,22 @@ type SSHConfig struct {
 	CmdLabels             services.CommandLabels
 	PermitUserEnvironment bool
 
+	// DisconnectOnRoleChange terminates active connections when the user's
+	// roles no longer allow access to this node.
+	DisconnectOnRoleChange bool
+
 	// PAM holds PAM configuration for Teleport.
 	PAM *pam.Config
 
 	// PublicAddrs affects the SSH host principals and DNS names added to the SSH and TLS certs.
 	PublicAddrs []utils.NetAddr
 
+	// AdditionalPrincipals is a list of extra host principals (for example
+	// CNAMEs, load balancer names or cloud private DNS names) added to the
+	// SSH host certificate, so strict host key checking works for every
+	// name clients use to reach this node.
+	AdditionalPrincipals []string
+
 	// BPF holds BPF configuration for Teleport.
 	BPF *bpf.Config
 }
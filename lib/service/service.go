@@ -794,43 +794,65 @@ func initUploadHandler(auditConfig services.AuditConfig, dataDir string) (events
 		return nil, trace.Wrap(err)
 	}
 
+	var handler events.MultipartHandler
 	switch uri.Scheme {
 	case teleport.SchemeGCS:
 		config := gcssessions.Config{}
 		if err := config.SetFromURL(uri); err != nil {
 			return nil, trace.Wrap(err)
 		}
-		handler, err := gcssessions.DefaultNewHandler(config)
+		handler, err = gcssessions.DefaultNewHandler(config)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		return handler, nil
 	case teleport.SchemeS3:
 		config := s3sessions.Config{}
 		if err := config.SetFromURL(uri, auditConfig.Region); err != nil {
 			return nil, trace.Wrap(err)
 		}
-		handler, err := s3sessions.NewHandler(config)
+		handler, err = s3sessions.NewHandler(config)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		return handler, nil
 	case teleport.SchemeFile:
 		if err := os.MkdirAll(uri.Path, teleport.SharedDirMode); err != nil {
 			return nil, trace.ConvertSystemError(err)
 		}
-		handler, err := filesessions.NewHandler(filesessions.Config{
+		handler, err = filesessions.NewHandler(filesessions.Config{
 			Directory: uri.Path,
 		})
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		return handler, nil
 	default:
 		return nil, trace.BadParameter(
 			"unsupported scheme for audit_sesions_uri: %q, currently supported schemes are %q and %q",
 			uri.Scheme, teleport.SchemeS3, teleport.SchemeFile)
 	}
+	if !auditConfig.AuditSessionsDualWrite {
+		return handler, nil
+	}
+	// Dual-write mode keeps a local copy of every recording next to the
+	// external upload so sessions survive a misconfigured or unavailable
+	// external backend and can be reconciled with `tctl recordings sync`.
+	recordsDir := filepath.Join(dataDir, events.RecordsDir)
+	if err := os.MkdirAll(recordsDir, teleport.SharedDirMode); err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	backupHandler, err := filesessions.NewHandler(filesessions.Config{
+		Directory: recordsDir,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	teeHandler, err := events.NewTeeHandler(events.TeeHandlerConfig{
+		Primary: handler,
+		Backup:  backupHandler,
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return teeHandler, nil
 }
 
 // initExternalLog initializes external storage, if the storage is not
@@ -1033,8 +1055,44 @@ func (process *TeleportProcess) initAuthService() error {
 		}
 	}
 
+	emitters := []events.Emitter{events.NewLoggingEmitter(), emitter}
+	if cfg.Auth.WebhookURL != "" {
+		webhook, err := events.NewWebhookEmitter(events.WebhookEmitterConfig{
+			Context: process.ExitContext(),
+			URL:     cfg.Auth.WebhookURL,
+			Types:   cfg.Auth.WebhookEvents,
+			Format:  cfg.Auth.WebhookFormat,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if len(cfg.Auth.AuditAlerts) != 0 {
+			alerting, err := events.NewAlertingEmitter(events.AlertingEmitterConfig{
+				Inner: webhook,
+				Rules: cfg.Auth.AuditAlerts,
+			})
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			emitters = append(emitters, alerting)
+		} else {
+			emitters = append(emitters, webhook)
+		}
+	}
+	var combinedEmitter events.Emitter = events.NewMultiEmitter(emitters...)
+	if len(cfg.Auth.AuditRedaction) != 0 {
+		// redaction runs before logging, persistence and forwarding so
+		// secrets never leave the emitter pipeline
+		combinedEmitter, err = events.NewRedactingEmitter(events.RedactingEmitterConfig{
+			Inner: combinedEmitter,
+			Rules: cfg.Auth.AuditRedaction,
+		})
+		if err != nil {
+			return trace.Wrap(err)
+		}
+	}
 	checkingEmitter, err := events.NewCheckingEmitter(events.CheckingEmitterConfig{
-		Inner: events.NewMultiEmitter(events.NewLoggingEmitter(), emitter),
+		Inner: combinedEmitter,
 		Clock: process.Clock,
 	})
 	if err != nil {
@@ -1078,6 +1136,10 @@ func (process *TeleportProcess) initAuthService() error {
 		CASigningAlg:         cfg.CASignatureAlgorithm,
 		Emitter:              checkingEmitter,
 		Streamer:             events.NewReportingStreamer(checkingStreamer, process.Config.UploadEventsC),
+		MinClientVersion:     cfg.Auth.MinClientVersion,
+		VersionCheckMode:     cfg.Auth.VersionCheckMode,
+		DisallowRootLogins:   cfg.Auth.DisallowRootLogins,
+		BPFOverrides:         cfg.Auth.BPFOverrides,
 	})
 	if err != nil {
 		return trace.Wrap(err)
@@ -1518,6 +1580,28 @@ func (process *TeleportProcess) initSSH() error {
 			return trace.Wrap(err)
 		}
 
+		// Apply any enhanced recording overrides the auth server has
+		// configured for nodes with these labels on top of the file
+		// configuration. Best-effort: older auth servers do not serve them.
+		if override, err := conn.Client.GetBPFOverride(cfg.SSH.Labels); err == nil {
+			override.Apply(cfg.SSH.BPF)
+		} else if !trace.IsNotImplemented(err) && !trace.IsNotFound(err) {
+			log.Warningf("Failed to fetch enhanced recording overrides: %v.", err)
+		}
+
+		// Fetch the cluster-wide network restrictions applied to recorded
+		// sessions. Best-effort: the resource may not be set and older auth
+		// servers do not serve it.
+		if netRestricts, err := conn.Client.GetNetworkRestrictions(); err == nil {
+			restricted, err := bpf.ParseRestrictedNetworks(netRestricts.GetAllow(), netRestricts.GetDeny())
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			cfg.SSH.BPF.RestrictedNetworks = restricted
+		} else if !trace.IsNotImplemented(err) && !trace.IsNotFound(err) {
+			log.Warningf("Failed to fetch network restrictions: %v.", err)
+		}
+
 		// If session recording is disabled at the cluster level and the node is
 		// attempting to enabled enhanced session recording, show an error.
 		clusterConfig, err := authClient.GetClusterConfig()
@@ -1542,6 +1626,8 @@ func (process *TeleportProcess) initSSH() error {
 		// Start BPF programs. This is blocking and if the BPF programs fail to
 		// load, the node will not start. If BPF is not enabled, this will simply
 		// return a NOP struct that can be used to discard BPF data.
+		cfg.SSH.BPF.ServerID = cfg.HostUUID
+		cfg.SSH.BPF.AuditLog = conn.Client
 		ebpf, err = bpf.New(cfg.SSH.BPF)
 		if err != nil {
 			return trace.Wrap(err)
@@ -1606,6 +1692,7 @@ func (process *TeleportProcess) initSSH() error {
 			regular.SetLabels(cfg.SSH.Labels, cfg.SSH.CmdLabels),
 			regular.SetNamespace(namespace),
 			regular.SetPermitUserEnvironment(cfg.SSH.PermitUserEnvironment),
+			regular.SetDisconnectOnRoleChange(cfg.SSH.DisconnectOnRoleChange),
 			regular.SetCiphers(cfg.Ciphers),
 			regular.SetKEXAlgorithms(cfg.KEXAlgorithms),
 			regular.SetMACAlgorithms(cfg.MACAlgorithms),
@@ -1976,6 +2063,7 @@ func (process *TeleportProcess) getAdditionalPrincipals(role teleport.Role) ([]s
 		// to allow UUID based routing to function with older Auth Servers
 		// which don't automatically add UUID to the principal list.
 		principals = append(principals, process.Config.HostUUID)
+		principals = append(principals, process.Config.SSH.AdditionalPrincipals...)
 		addrs = process.Config.SSH.PublicAddrs
 		// If advertise IP is set, add it to the list of principals. Otherwise
 		// add in the default (0.0.0.0) which will be replaced by the Auth Server
@@ -2302,6 +2390,11 @@ func (process *TeleportProcess) initProxyEndpoint(conn *Connector) error {
 				ProxySettings: proxySettings,
 				CipherSuites:  cfg.CipherSuites,
 				FIPS:          cfg.FIPS,
+				// allow API calls authenticated with user CA issued certificates
+				ClientCertAuth: cfg.Proxy.ClientCertAuth,
+				// only set when this process also runs the auth service,
+				// standalone proxies advertise the compiled-in minimum
+				MinClientVersion: cfg.Auth.MinClientVersion,
 			})
 		if err != nil {
 			return trace.Wrap(err)
@@ -2313,6 +2406,31 @@ func (process *TeleportProcess) initProxyEndpoint(conn *Connector) error {
 			if err != nil {
 				return trace.Wrap(err)
 			}
+			if cfg.Proxy.ClientCertAuth {
+				// Request, but do not require, client certificates: browser
+				// clients keep authenticating with session cookies. The user
+				// CA pool is reloaded on every connection to pick up
+				// certificate authority rotations.
+				tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+				tlsConfig.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+					ca, err := accessPoint.GetCertAuthority(services.CertAuthID{
+						Type:       services.UserCA,
+						DomainName: conn.ServerIdentity.ClusterName,
+					}, false)
+					if err != nil {
+						log.Errorf("Failed to retrieve user certificate authority: %v.", err)
+						return nil, nil
+					}
+					pool, err := services.CertPool(ca)
+					if err != nil {
+						log.Errorf("Failed to build client certificate pool: %v.", err)
+						return nil, nil
+					}
+					tlsClone := tlsConfig.Clone()
+					tlsClone.ClientCAs = pool
+					return tlsClone, nil
+				}
+			}
 			listeners.web = tls.NewListener(listeners.web, tlsConfig)
 		}
 		webServer = &http.Server{
@@ -2368,6 +2486,7 @@ func (process *TeleportProcess) initProxyEndpoint(conn *Connector) error {
 		}),
 		regular.SetEmitter(&events.StreamerAndEmitter{Emitter: emitter, Streamer: streamer}),
 		regular.SetKubernetesClusters(kubeClusterNames),
+		regular.SetHostTemplates(cfg.Proxy.HostTemplates),
 	)
 	if err != nil {
 		return trace.Wrap(err)
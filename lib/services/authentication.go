@@ -31,6 +31,7 @@ import (
 	"github.com/gravitational/teleport/lib/utils"
 
 	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
 	"github.com/pquerna/otp/totp"
 	"github.com/tstranex/u2f"
 )
@@ -40,15 +41,8 @@ import (
 // AuthPreference is a configuration resource, never create more than one instance
 // of it.
 type AuthPreference interface {
-	// Expiry returns object expiry setting
-	Expiry() time.Time
-	// SetExpiry sets object expiry
-	SetExpiry(time.Time)
-
-	// GetResourceID returns resource ID
-	GetResourceID() int64
-	// SetResourceID sets resource ID
-	SetResourceID(int64)
+	// Resource provides common resource properties.
+	Resource
 
 	// GetType gets the type of authentication: local, saml, or oidc.
 	GetType() string
@@ -121,6 +115,31 @@ func (s *AuthPreferenceV2) Expiry() time.Time {
 	return s.Metadata.Expiry()
 }
 
+// SetTTL sets Expires header using current clock
+func (s *AuthPreferenceV2) SetTTL(clock clockwork.Clock, ttl time.Duration) {
+	s.Metadata.SetTTL(clock, ttl)
+}
+
+// GetVersion returns resource version
+func (c *AuthPreferenceV2) GetVersion() string {
+	return c.Version
+}
+
+// GetName returns the name of the resource
+func (c *AuthPreferenceV2) GetName() string {
+	return c.Metadata.Name
+}
+
+// SetName sets the name of the resource
+func (c *AuthPreferenceV2) SetName(e string) {
+	c.Metadata.Name = e
+}
+
+// GetMetadata returns object metadata
+func (c *AuthPreferenceV2) GetMetadata() Metadata {
+	return c.Metadata
+}
+
 // GetResourceID returns resource ID
 func (c *AuthPreferenceV2) GetResourceID() int64 {
 	return c.Metadata.ID
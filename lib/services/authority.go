@@ -124,6 +124,10 @@ type UserCertParams struct {
 	// ActiveRequests tracks privilege escalation requests applied during
 	// certificate construction.
 	ActiveRequests RequestIDs
+	// SourceAddresses is a list of CIDR blocks the certificate is
+	// restricted to via the source-address critical option, empty means
+	// no restriction.
+	SourceAddresses []string
 }
 
 func (c UserCertParams) Check() error {
@@ -471,6 +471,9 @@ const ClusterConfigSpecSchemaTemplate = `{
         "audit_sessions_uri": {
           "type": "string"
         },
+        "audit_sessions_dual_write": {
+          "type": "boolean"
+        },
         "audit_table_name": {
           "type": "string"
         }
@@ -99,6 +99,10 @@ func (f *Fanout) Emit(events ...Event) {
 				emitEvent = fullEvent
 			}
 			if err := entry.watcher.emit(emitEvent); err != nil {
+				// record the reason the watcher is being closed (e.g.
+				// buffer overflow) so slow consumers can distinguish
+				// it from a clean close and re-initialize.
+				entry.watcher.setError(err)
 				remove = append(remove, entry.watcher)
 				continue Inner
 			}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gravitational/teleport/lib/backend"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFanoutOverflow verifies that a watcher that cannot keep up with the
+// event stream is closed with an error, so the consumer can distinguish an
+// overflow from a clean close and re-initialize.
+func TestFanoutOverflow(t *testing.T) {
+	ctx := context.Background()
+	f := NewFanout()
+
+	w, err := f.NewWatcher(ctx, Watch{
+		Name:      "test",
+		QueueSize: 1,
+		Kinds:     []WatchKind{{Kind: KindNamespace}},
+	})
+	assert.NoError(t, err)
+	defer w.Close()
+
+	event := Event{
+		Type:     backend.OpPut,
+		Resource: &Namespace{Kind: KindNamespace, Metadata: Metadata{Name: "default"}},
+	}
+	// queue size is 1 and the init event has not been consumed,
+	// the emit overflows the watcher buffer
+	f.Emit(event)
+
+	select {
+	case <-w.Done():
+	default:
+		t.Fatalf("watcher should be closed after buffer overflow")
+	}
+	assert.Error(t, w.Error())
+
+	// the overflown watcher is removed, emitting more events does not panic
+	f.Emit(event)
+}
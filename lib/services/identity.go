@@ -127,6 +127,19 @@ type Identity interface {
 	// UpsertPassword upserts new password and OTP token
 	UpsertPassword(user string, password []byte) error
 
+	// UpsertRecoveryCodes replaces the recovery codes stored for a user
+	UpsertRecoveryCodes(user string, codes RecoveryCodes) error
+
+	// GetRecoveryCodes returns the recovery codes stored for a user
+	GetRecoveryCodes(user string) (*RecoveryCodes, error)
+
+	// GetUserPreferences returns the web UI preferences for a user,
+	// defaults if none have been stored
+	GetUserPreferences(user string) (*UserPreferences, error)
+
+	// UpsertUserPreferences updates the web UI preferences for a user
+	UpsertUserPreferences(user string, prefs UserPreferences) error
+
 	// UpsertU2FRegisterChallenge upserts a U2F challenge for a new user corresponding to the token
 	UpsertU2FRegisterChallenge(token string, u2fChallenge *u2f.Challenge) error
 
@@ -101,6 +101,39 @@ func (s *AccessService) UpsertRole(ctx context.Context, role services.Role) erro
 	return nil
 }
 
+// CompareAndSwapRole updates the role value if the existing value matches
+// existing parameter, returns nil if succeeds, trace.CompareFailed otherwise.
+func (s *AccessService) CompareAndSwapRole(ctx context.Context, new services.Role, existing services.Role) error {
+	newValue, err := services.GetRoleMarshaler().MarshalRole(new)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	newItem := backend.Item{
+		Key:     backend.Key(rolesPrefix, new.GetName(), paramsPrefix),
+		Value:   newValue,
+		Expires: new.Expiry(),
+	}
+
+	existingValue, err := services.GetRoleMarshaler().MarshalRole(existing)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	existingItem := backend.Item{
+		Key:     backend.Key(rolesPrefix, existing.GetName(), paramsPrefix),
+		Value:   existingValue,
+		Expires: existing.Expiry(),
+	}
+
+	_, err = s.CompareAndSwap(ctx, existingItem, newItem)
+	if err != nil {
+		if trace.IsCompareFailed(err) {
+			return trace.CompareFailed("role %v has been updated by another client, try again", new.GetName())
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 // GetRole returns a role by name
 func (s *AccessService) GetRole(name string) (services.Role, error) {
 	if name == "" {
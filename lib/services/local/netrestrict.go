@@ -0,0 +1,88 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+
+	"github.com/gravitational/teleport/lib/backend"
+	"github.com/gravitational/teleport/lib/services"
+
+	"github.com/gravitational/trace"
+)
+
+// RestrictionsService manages the cluster-wide network restrictions resource.
+type RestrictionsService struct {
+	backend.Backend
+}
+
+// NewRestrictionsService returns a new RestrictionsService.
+func NewRestrictionsService(backend backend.Backend) *RestrictionsService {
+	return &RestrictionsService{
+		Backend: backend,
+	}
+}
+
+// GetNetworkRestrictions returns the network restrictions resource from the backend.
+func (s *RestrictionsService) GetNetworkRestrictions(opts ...services.MarshalOption) (services.NetworkRestrictions, error) {
+	item, err := s.Get(context.TODO(), backend.Key(restrictionsPrefix, networkPrefix))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("network restrictions not found")
+		}
+		return nil, trace.Wrap(err)
+	}
+	return services.GetNetworkRestrictionsMarshaler().Unmarshal(item.Value,
+		services.AddOptions(opts, services.WithResourceID(item.ID))...)
+}
+
+// SetNetworkRestrictions upserts the network restrictions resource in the backend.
+func (s *RestrictionsService) SetNetworkRestrictions(nr services.NetworkRestrictions) error {
+	value, err := services.GetNetworkRestrictionsMarshaler().Marshal(nr)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	_, err = s.Put(context.TODO(), backend.Item{
+		Key:     backend.Key(restrictionsPrefix, networkPrefix),
+		Value:   value,
+		Expires: nr.Expiry(),
+		ID:      nr.GetResourceID(),
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	return nil
+}
+
+// DeleteNetworkRestrictions deletes the network restrictions resource from the backend.
+func (s *RestrictionsService) DeleteNetworkRestrictions() error {
+	err := s.Delete(context.TODO(), backend.Key(restrictionsPrefix, networkPrefix))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return trace.NotFound("network restrictions not found")
+		}
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+const (
+	restrictionsPrefix = "restrictions"
+	networkPrefix      = "network"
+)
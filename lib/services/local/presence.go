@@ -230,18 +230,67 @@ func (s *PresenceService) GetNodes(namespace string, opts ...services.MarshalOpt
 	return servers, nil
 }
 
+// ListNodes returns a page of node resources sorted by name, starting at
+// the node named startKey (inclusive), along with the name to start the
+// next page at. Unlike GetNodes, memory use is bounded by the page size
+// rather than the cluster size.
+func (s *PresenceService) ListNodes(namespace string, limit int, startKey string, opts ...services.MarshalOption) ([]services.Server, string, error) {
+	if namespace == "" {
+		return nil, "", trace.BadParameter("missing namespace value")
+	}
+	if limit <= 0 {
+		return nil, "", trace.BadParameter("missing positive limit value")
+	}
+
+	// Get one item over the limit to find out where the next page starts.
+	rangeStart := backend.Key(nodesPrefix, namespace, startKey)
+	rangeEnd := backend.RangeEnd(backend.Key(nodesPrefix, namespace))
+	result, err := s.GetRange(context.TODO(), rangeStart, rangeEnd, limit+1)
+	if err != nil {
+		return nil, "", trace.Wrap(err)
+	}
+
+	servers := make([]services.Server, len(result.Items))
+	for i, item := range result.Items {
+		server, err := services.GetServerMarshaler().UnmarshalServer(
+			item.Value,
+			services.KindNode,
+			services.AddOptions(opts,
+				services.WithResourceID(item.ID),
+				services.WithExpires(item.Expires))...)
+		if err != nil {
+			return nil, "", trace.Wrap(err)
+		}
+		servers[i] = server
+	}
+
+	var nextKey string
+	if len(servers) > limit {
+		nextKey = servers[limit].GetName()
+		servers = servers[:limit]
+	}
+	return servers, nextKey, nil
+}
+
 // UpsertNode registers node presence, permanently if TTL is 0 or for the
 // specified duration with second resolution if it's >= 1 second.
 func (s *PresenceService) UpsertNode(server services.Server) (*services.KeepAlive, error) {
 	if server.GetNamespace() == "" {
 		return nil, trace.BadParameter("missing node namespace")
 	}
+	key := backend.Key(nodesPrefix, server.GetNamespace(), server.GetName())
+	// most heartbeats do not carry any changes, in which case it is enough
+	// to extend the TTL of the stored resource instead of rewriting it,
+	// this reduces backend write load in clusters with many nodes
+	if lease := s.keepAliveUnchangedNode(key, server); lease != nil {
+		return lease, nil
+	}
 	value, err := services.GetServerMarshaler().MarshalServer(server)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
 	lease, err := s.Put(context.TODO(), backend.Item{
-		Key:     backend.Key(nodesPrefix, server.GetNamespace(), server.GetName()),
+		Key:     key,
 		Value:   value,
 		Expires: server.Expiry(),
 		ID:      server.GetResourceID(),
@@ -255,6 +304,36 @@ func (s *PresenceService) UpsertNode(server services.Server) (*services.KeepAliv
 	return &services.KeepAlive{LeaseID: lease.ID, ServerName: server.GetName()}, nil
 }
 
+// keepAliveUnchangedNode extends the TTL of the stored node resource if
+// it matches the announced one, and returns the keep alive to use. A nil
+// return value means the node has changed (or is missing) and a full
+// write is required.
+func (s *PresenceService) keepAliveUnchangedNode(key []byte, server services.Server) *services.KeepAlive {
+	if server.Expiry().IsZero() {
+		return nil
+	}
+	item, err := s.Get(context.TODO(), key)
+	if err != nil {
+		return nil
+	}
+	existing, err := services.GetServerMarshaler().UnmarshalServer(
+		item.Value, services.KindNode, services.SkipValidation())
+	if err != nil {
+		return nil
+	}
+	if services.CompareServers(existing, server) == services.Different {
+		return nil
+	}
+	err = s.KeepAlive(context.TODO(), backend.Lease{
+		ID:  item.ID,
+		Key: key,
+	}, server.Expiry())
+	if err != nil {
+		return nil
+	}
+	return &services.KeepAlive{LeaseID: item.ID, ServerName: server.GetName()}
+}
+
 // KeepAliveNode updates node expiry
 func (s *PresenceService) KeepAliveNode(ctx context.Context, h services.KeepAlive) error {
 	if err := h.CheckAndSetDefaults(); err != nil {
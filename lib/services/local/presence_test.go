@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/gravitational/teleport/lib/backend"
 	"github.com/gravitational/teleport/lib/backend/lite"
+	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/utils"
 
@@ -110,3 +112,48 @@ func (s *PresenceSuite) TestTrustedClusterCRUD(c *check.C) {
 	c.Assert(err, check.NotNil)
 	c.Assert(trace.IsNotFound(err), check.Equals, true)
 }
+
+func (s *PresenceSuite) TestUpsertNodeUnchanged(c *check.C) {
+	presenceBackend := NewPresenceService(s.bk)
+
+	node := &services.ServerV2{
+		Kind:    services.KindNode,
+		Version: services.V2,
+		Metadata: services.Metadata{
+			Name:      "node1",
+			Namespace: defaults.Namespace,
+		},
+		Spec: services.ServerSpecV2{
+			Addr: "127.0.0.1:2022",
+		},
+	}
+	node.SetExpiry(time.Now().UTC().Add(10 * time.Minute))
+
+	_, err := presenceBackend.UpsertNode(node)
+	c.Assert(err, check.IsNil)
+	out, err := presenceBackend.GetNodes(defaults.Namespace)
+	c.Assert(err, check.IsNil)
+	c.Assert(out, check.HasLen, 1)
+	originalID := out[0].GetResourceID()
+
+	// re-announcing an identical node only extends the TTL of the stored
+	// resource instead of rewriting it
+	node.SetExpiry(time.Now().UTC().Add(20 * time.Minute))
+	_, err = presenceBackend.UpsertNode(node)
+	c.Assert(err, check.IsNil)
+	out, err = presenceBackend.GetNodes(defaults.Namespace)
+	c.Assert(err, check.IsNil)
+	c.Assert(out, check.HasLen, 1)
+	c.Assert(out[0].GetResourceID(), check.Equals, originalID)
+	c.Assert(out[0].Expiry(), check.Equals, node.Expiry())
+
+	// changing the node spec forces a full rewrite
+	node.Spec.Hostname = "node1.example.com"
+	_, err = presenceBackend.UpsertNode(node)
+	c.Assert(err, check.IsNil)
+	out, err = presenceBackend.GetNodes(defaults.Namespace)
+	c.Assert(err, check.IsNil)
+	c.Assert(out, check.HasLen, 1)
+	c.Assert(out[0].GetResourceID(), check.Not(check.Equals), originalID)
+	c.Assert(out[0].GetHostname(), check.Equals, "node1.example.com")
+}
@@ -125,6 +125,10 @@ func (s *ServicesSuite) TestWebSessionCRUD(c *check.C) {
 	s.suite.WebSessionCRUD(c)
 }
 
+func (s *ServicesSuite) TestUserPreferencesCRUD(c *check.C) {
+	s.suite.UserPreferencesCRUD(c)
+}
+
 func (s *ServicesSuite) TestToken(c *check.C) {
 	s.suite.TokenCRUD(c)
 }
@@ -636,6 +636,88 @@ func (s *IdentityService) UpsertPassword(user string, password []byte) error {
 	return nil
 }
 
+// UpsertRecoveryCodes replaces the recovery codes stored for a user
+func (s *IdentityService) UpsertRecoveryCodes(user string, codes services.RecoveryCodes) error {
+	if user == "" {
+		return trace.BadParameter("missing username")
+	}
+	value, err := json.Marshal(codes)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	item := backend.Item{
+		Key:   backend.Key(webPrefix, usersPrefix, user, recoveryCodesPrefix),
+		Value: value,
+	}
+	_, err = s.Put(context.TODO(), item)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// GetRecoveryCodes returns the recovery codes stored for a user
+func (s *IdentityService) GetRecoveryCodes(user string) (*services.RecoveryCodes, error) {
+	if user == "" {
+		return nil, trace.BadParameter("missing username")
+	}
+	item, err := s.Get(context.TODO(), backend.Key(webPrefix, usersPrefix, user, recoveryCodesPrefix))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return nil, trace.NotFound("no recovery codes found for user %q", user)
+		}
+		return nil, trace.Wrap(err)
+	}
+	var codes services.RecoveryCodes
+	if err := json.Unmarshal(item.Value, &codes); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &codes, nil
+}
+
+// GetUserPreferences returns the web UI preferences for a user, defaults
+// if none have been stored
+func (s *IdentityService) GetUserPreferences(user string) (*services.UserPreferences, error) {
+	if user == "" {
+		return nil, trace.BadParameter("missing username")
+	}
+	item, err := s.Get(context.TODO(), backend.Key(webPrefix, usersPrefix, user, preferencesPrefix))
+	if err != nil {
+		if trace.IsNotFound(err) {
+			return &services.UserPreferences{}, nil
+		}
+		return nil, trace.Wrap(err)
+	}
+	var prefs services.UserPreferences
+	if err := json.Unmarshal(item.Value, &prefs); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &prefs, nil
+}
+
+// UpsertUserPreferences updates the web UI preferences for a user
+func (s *IdentityService) UpsertUserPreferences(user string, prefs services.UserPreferences) error {
+	if user == "" {
+		return trace.BadParameter("missing username")
+	}
+	if err := prefs.Check(); err != nil {
+		return trace.Wrap(err)
+	}
+	value, err := json.Marshal(prefs)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	item := backend.Item{
+		Key:   backend.Key(webPrefix, usersPrefix, user, preferencesPrefix),
+		Value: value,
+	}
+	_, err = s.Put(context.TODO(), item)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
 func (s *IdentityService) UpsertU2FRegisterChallenge(token string, u2fChallenge *u2f.Challenge) error {
 	if token == "" {
 		return trace.BadParameter("missing parmeter token")
@@ -1220,6 +1302,8 @@ const (
 	requestsPrefix               = "requests"
 	u2fRegChalPrefix             = "adduseru2fchallenges"
 	usedTOTPPrefix               = "used_totp"
+	preferencesPrefix            = "preferences"
+	recoveryCodesPrefix          = "recoverycodes"
 	usedTOTPTTL                  = 30 * time.Second
 	u2fRegistrationPrefix        = "u2fregistration"
 	u2fRegistrationCounterPrefix = "u2fregistrationcounter"
@@ -0,0 +1,320 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+	"github.com/jonboulle/clockwork"
+)
+
+// NetworkRestrictions defines cluster-wide network restrictions applied to
+// processes running inside recorded sessions: a deny list of CIDR blocks
+// evaluated first, and an optional allow list restricting everything else.
+// This is a configuration resource, never create more than one instance
+// of it.
+type NetworkRestrictions interface {
+	// Resource provides common resource properties.
+	Resource
+
+	// GetAllow returns the allowed CIDR blocks; all destinations are
+	// allowed when the list is empty.
+	GetAllow() []string
+
+	// SetAllow sets the allowed CIDR blocks.
+	SetAllow([]string)
+
+	// GetDeny returns the denied CIDR blocks, evaluated before the
+	// allow list.
+	GetDeny() []string
+
+	// SetDeny sets the denied CIDR blocks.
+	SetDeny([]string)
+
+	// CheckAndSetDefaults checks and set default values for missing fields.
+	CheckAndSetDefaults() error
+}
+
+// NewNetworkRestrictions is a convenience wrapper to create a
+// NetworkRestrictions resource.
+func NewNetworkRestrictions(spec NetworkRestrictionsSpecV1) (NetworkRestrictions, error) {
+	nr := NetworkRestrictionsV1{
+		Kind:    KindNetworkRestrictions,
+		Version: V1,
+		Metadata: Metadata{
+			Name:      MetaNameNetworkRestrictions,
+			Namespace: defaults.Namespace,
+		},
+		Spec: spec,
+	}
+	if err := nr.CheckAndSetDefaults(); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return &nr, nil
+}
+
+// NetworkRestrictionsV1 implements the NetworkRestrictions interface.
+type NetworkRestrictionsV1 struct {
+	// Kind is the resource kind.
+	Kind string `json:"kind"`
+	// SubKind is an optional resource sub kind.
+	SubKind string `json:"sub_kind,omitempty"`
+	// Version is the resource version.
+	Version string `json:"version"`
+	// Metadata is resource metadata.
+	Metadata Metadata `json:"metadata"`
+	// Spec is the network restrictions spec.
+	Spec NetworkRestrictionsSpecV1 `json:"spec"`
+}
+
+// NetworkRestrictionsSpecV1 is the network restrictions spec.
+type NetworkRestrictionsSpecV1 struct {
+	// Allow is the list of CIDR blocks session processes may connect to;
+	// all destinations are allowed when the list is empty.
+	Allow []string `json:"allow,omitempty"`
+	// Deny is the list of CIDR blocks session processes may never connect
+	// to, evaluated before the allow list.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// GetVersion returns resource version
+func (nr *NetworkRestrictionsV1) GetVersion() string {
+	return nr.Version
+}
+
+// GetKind returns resource kind
+func (nr *NetworkRestrictionsV1) GetKind() string {
+	return nr.Kind
+}
+
+// GetSubKind returns resource sub kind
+func (nr *NetworkRestrictionsV1) GetSubKind() string {
+	return nr.SubKind
+}
+
+// SetSubKind sets resource subkind
+func (nr *NetworkRestrictionsV1) SetSubKind(sk string) {
+	nr.SubKind = sk
+}
+
+// GetResourceID returns resource ID
+func (nr *NetworkRestrictionsV1) GetResourceID() int64 {
+	return nr.Metadata.ID
+}
+
+// SetResourceID sets resource ID
+func (nr *NetworkRestrictionsV1) SetResourceID(id int64) {
+	nr.Metadata.ID = id
+}
+
+// GetName returns the name of the resource
+func (nr *NetworkRestrictionsV1) GetName() string {
+	return nr.Metadata.Name
+}
+
+// SetName sets the name of the resource
+func (nr *NetworkRestrictionsV1) SetName(name string) {
+	nr.Metadata.Name = name
+}
+
+// Expiry returns object expiry setting
+func (nr *NetworkRestrictionsV1) Expiry() time.Time {
+	return nr.Metadata.Expiry()
+}
+
+// SetExpiry sets expiry time for the object
+func (nr *NetworkRestrictionsV1) SetExpiry(expires time.Time) {
+	nr.Metadata.SetExpiry(expires)
+}
+
+// SetTTL sets Expires header using realtime clock
+func (nr *NetworkRestrictionsV1) SetTTL(clock clockwork.Clock, ttl time.Duration) {
+	nr.Metadata.SetTTL(clock, ttl)
+}
+
+// GetMetadata returns object metadata
+func (nr *NetworkRestrictionsV1) GetMetadata() Metadata {
+	return nr.Metadata
+}
+
+// GetAllow returns the allowed CIDR blocks
+func (nr *NetworkRestrictionsV1) GetAllow() []string {
+	return nr.Spec.Allow
+}
+
+// SetAllow sets the allowed CIDR blocks
+func (nr *NetworkRestrictionsV1) SetAllow(allow []string) {
+	nr.Spec.Allow = allow
+}
+
+// GetDeny returns the denied CIDR blocks
+func (nr *NetworkRestrictionsV1) GetDeny() []string {
+	return nr.Spec.Deny
+}
+
+// SetDeny sets the denied CIDR blocks
+func (nr *NetworkRestrictionsV1) SetDeny(deny []string) {
+	nr.Spec.Deny = deny
+}
+
+// CheckAndSetDefaults checks validity of all parameters and sets defaults.
+func (nr *NetworkRestrictionsV1) CheckAndSetDefaults() error {
+	if nr.Kind == "" {
+		nr.Kind = KindNetworkRestrictions
+	}
+	if nr.Version == "" {
+		nr.Version = V1
+	}
+	if nr.Metadata.Name == "" {
+		nr.Metadata.Name = MetaNameNetworkRestrictions
+	}
+	if err := nr.Metadata.CheckAndSetDefaults(); err != nil {
+		return trace.Wrap(err)
+	}
+	for _, blocks := range [][]string{nr.Spec.Allow, nr.Spec.Deny} {
+		for _, block := range blocks {
+			if _, _, err := net.ParseCIDR(block); err != nil {
+				return trace.BadParameter("invalid CIDR block %q: %v", block, err)
+			}
+		}
+	}
+	return nil
+}
+
+// NetworkRestrictionsSpecSchema is the JSON schema for the network
+// restrictions spec.
+const NetworkRestrictionsSpecSchema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "allow": {
+      "type": "array",
+      "items": { "type": "string" }
+    },
+    "deny": {
+      "type": "array",
+      "items": { "type": "string" }
+    }
+  }
+}`
+
+// GetNetworkRestrictionsSchema returns the full network restrictions schema.
+func GetNetworkRestrictionsSchema() string {
+	return fmt.Sprintf(V2SchemaTemplate, MetadataSchema, NetworkRestrictionsSpecSchema, DefaultDefinitions)
+}
+
+// NetworkRestrictionsMarshaler implements marshal/unmarshal of
+// NetworkRestrictions implementations
+type NetworkRestrictionsMarshaler interface {
+	Marshal(nr NetworkRestrictions, opts ...MarshalOption) ([]byte, error)
+	Unmarshal(bytes []byte, opts ...MarshalOption) (NetworkRestrictions, error)
+}
+
+var networkRestrictionsMarshaler NetworkRestrictionsMarshaler = &TeleportNetworkRestrictionsMarshaler{}
+
+// SetNetworkRestrictionsMarshaler sets the marshaler.
+func SetNetworkRestrictionsMarshaler(m NetworkRestrictionsMarshaler) {
+	marshalerMutex.Lock()
+	defer marshalerMutex.Unlock()
+	networkRestrictionsMarshaler = m
+}
+
+// GetNetworkRestrictionsMarshaler gets the marshaler.
+func GetNetworkRestrictionsMarshaler() NetworkRestrictionsMarshaler {
+	marshalerMutex.Lock()
+	defer marshalerMutex.Unlock()
+	return networkRestrictionsMarshaler
+}
+
+// TeleportNetworkRestrictionsMarshaler is used to marshal and unmarshal
+// NetworkRestrictions.
+type TeleportNetworkRestrictionsMarshaler struct{}
+
+// Unmarshal unmarshals NetworkRestrictions from JSON.
+func (t *TeleportNetworkRestrictionsMarshaler) Unmarshal(bytes []byte, opts ...MarshalOption) (NetworkRestrictions, error) {
+	var nr NetworkRestrictionsV1
+
+	if len(bytes) == 0 {
+		return nil, trace.BadParameter("missing resource data")
+	}
+
+	cfg, err := collectOptions(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if cfg.SkipValidation {
+		if err := utils.FastUnmarshal(bytes, &nr); err != nil {
+			return nil, trace.BadParameter(err.Error())
+		}
+	} else {
+		err = utils.UnmarshalWithSchema(GetNetworkRestrictionsSchema(), &nr, bytes)
+		if err != nil {
+			return nil, trace.BadParameter(err.Error())
+		}
+	}
+
+	err = nr.CheckAndSetDefaults()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	if cfg.ID != 0 {
+		nr.SetResourceID(cfg.ID)
+	}
+	if !cfg.Expires.IsZero() {
+		nr.SetExpiry(cfg.Expires)
+	}
+	return &nr, nil
+}
+
+// Marshal marshals NetworkRestrictions to JSON.
+func (t *TeleportNetworkRestrictionsMarshaler) Marshal(nr NetworkRestrictions, opts ...MarshalOption) ([]byte, error) {
+	cfg, err := collectOptions(opts)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	switch resource := nr.(type) {
+	case *NetworkRestrictionsV1:
+		if !cfg.PreserveResourceID {
+			// avoid modifying the original object
+			// to prevent unexpected data races
+			copy := *resource
+			copy.SetResourceID(0)
+			resource = &copy
+		}
+		return utils.FastMarshal(resource)
+	default:
+		return nil, trace.BadParameter("unrecognized network restrictions version %T", nr)
+	}
+}
+
+// Restrictions is a service managing the network restrictions resource.
+type Restrictions interface {
+	// GetNetworkRestrictions returns the network restrictions resource.
+	GetNetworkRestrictions(opts ...MarshalOption) (NetworkRestrictions, error)
+	// SetNetworkRestrictions upserts the network restrictions resource.
+	SetNetworkRestrictions(NetworkRestrictions) error
+	// DeleteNetworkRestrictions deletes the network restrictions resource.
+	DeleteNetworkRestrictions() error
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNetworkRestrictions makes sure the network restrictions resource
+// validates its CIDR blocks and fills in the singleton defaults.
+func TestNetworkRestrictions(t *testing.T) {
+	// Invalid CIDR blocks are rejected.
+	_, err := NewNetworkRestrictions(NetworkRestrictionsSpecV1{
+		Deny: []string{"not-a-cidr"},
+	})
+	require.Error(t, err)
+	_, err = NewNetworkRestrictions(NetworkRestrictionsSpecV1{
+		Allow: []string{"10.0.0.1"},
+	})
+	require.Error(t, err)
+
+	nr, err := NewNetworkRestrictions(NetworkRestrictionsSpecV1{
+		Allow: []string{"10.0.0.0/8"},
+		Deny:  []string{"10.2.0.0/16"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, KindNetworkRestrictions, nr.GetKind())
+	require.Equal(t, MetaNameNetworkRestrictions, nr.GetName())
+	require.Equal(t, []string{"10.0.0.0/8"}, nr.GetAllow())
+	require.Equal(t, []string{"10.2.0.0/16"}, nr.GetDeny())
+
+	// CheckAndSetDefaults fills in the kind, version, and name on a bare
+	// unmarshaled resource.
+	bare := &NetworkRestrictionsV1{}
+	require.NoError(t, bare.CheckAndSetDefaults())
+	require.Equal(t, KindNetworkRestrictions, bare.GetKind())
+	require.Equal(t, V1, bare.GetVersion())
+	require.Equal(t, MetaNameNetworkRestrictions, bare.GetName())
+}
@@ -46,6 +46,12 @@ type Presence interface {
 	// skipped to improve performance.
 	GetNodes(namespace string, opts ...MarshalOption) ([]Server, error)
 
+	// ListNodes returns a page of registered servers sorted by name,
+	// starting at the node named startKey (inclusive), along with the name
+	// to start the next page at. The next page start key is empty when
+	// there are no more nodes.
+	ListNodes(namespace string, limit int, startKey string, opts ...MarshalOption) ([]Server, string, error)
+
 	// DeleteAllNodes deletes all nodes in a namespace.
 	DeleteAllNodes(namespace string) error
 
@@ -0,0 +1,39 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"time"
+)
+
+// RecoveryCode is a single one-time recovery code. Only the hash of the
+// code is stored; the plaintext is shown to the user once at generation.
+type RecoveryCode struct {
+	// HashedCode is the bcrypt hash of the code.
+	HashedCode []byte `json:"hashed_code"`
+	// IsUsed is true once the code has been spent.
+	IsUsed bool `json:"is_used"`
+}
+
+// RecoveryCodes is the set of recovery codes issued to a user. Generating
+// new codes replaces the whole set.
+type RecoveryCodes struct {
+	// Codes is the list of codes issued in this set.
+	Codes []RecoveryCode `json:"codes"`
+	// Created is the time this set was generated.
+	Created time.Time `json:"created"`
+}
@@ -108,6 +108,10 @@ const (
 	// KindNode is node resource
 	KindNode = "node"
 
+	// KindKubeCluster is a kubernetes cluster advertised by a proxy,
+	// used in unified resource listings
+	KindKubeCluster = "kube_cluster"
+
 	// KindToken is a provisioning token resource
 	KindToken = "token"
 
@@ -144,6 +148,14 @@ const (
 	// MetaNameClusterConfig is the exact name of the cluster config singleton resource.
 	MetaNameClusterConfig = "cluster-config"
 
+	// KindNetworkRestrictions is the resource that holds cluster-wide
+	// network restrictions for recorded sessions.
+	KindNetworkRestrictions = "network_restrictions"
+
+	// MetaNameNetworkRestrictions is the exact name of the network
+	// restrictions singleton resource.
+	MetaNameNetworkRestrictions = "network-restrictions"
+
 	// KindClusterName is a type of configuration resource that contains the cluster name.
 	KindClusterName = "cluster_name"
 
@@ -400,6 +412,25 @@ func init() {
 		return rsc, nil
 	})
 
+	RegisterResourceMarshaler(KindRole, func(r Resource, opts ...MarshalOption) ([]byte, error) {
+		rsc, ok := r.(Role)
+		if !ok {
+			return nil, trace.BadParameter("expected Role, got %T", r)
+		}
+		raw, err := GetRoleMarshaler().MarshalRole(rsc, opts...)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return raw, nil
+	})
+	RegisterResourceUnmarshaler(KindRole, func(b []byte, opts ...MarshalOption) (Resource, error) {
+		rsc, err := GetRoleMarshaler().UnmarshalRole(b, opts...)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return rsc, nil
+	})
+
 	RegisterResourceMarshaler(KindCertAuthority, func(r Resource, opts ...MarshalOption) ([]byte, error) {
 		rsc, ok := r.(CertAuthority)
 		if !ok {
@@ -20,6 +20,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -51,6 +53,7 @@ var AdminUserRules = []Rule{
 	NewRule(KindSession, RO()),
 	NewRule(KindTrustedCluster, RW()),
 	NewRule(KindEvent, RO()),
+	NewRule(KindNetworkRestrictions, RW()),
 }
 
 // DefaultImplicitRules provides access to the default set of implicit rules
@@ -211,6 +214,11 @@ type Access interface {
 	// UpsertRole creates or updates role
 	UpsertRole(ctx context.Context, role Role) error
 
+	// CompareAndSwapRole updates the role value if the existing
+	// value matches the existing parameter, returns nil if succeeds,
+	// trace.CompareFailed otherwise.
+	CompareAndSwapRole(ctx context.Context, new Role, existing Role) error
+
 	// DeleteAllRoles deletes all roles
 	DeleteAllRoles() error
 
@@ -706,6 +714,41 @@ func (r *RoleV3) CheckAndSetDefaults() error {
 			return trace.BadParameter("wildcard matcher is not allowed in logins")
 		}
 	}
+	// source addresses end up in certificate critical options, make sure
+	// they are valid CIDR blocks or plain IP addresses
+	for _, addr := range r.Spec.Options.SourceAddresses {
+		if _, _, err := net.ParseCIDR(addr); err != nil {
+			if net.ParseIP(addr) == nil {
+				return trace.BadParameter("invalid source address %q, expected a CIDR block or an IP address", addr)
+			}
+		}
+	}
+
+	// blocked write paths are matched as absolute path prefixes
+	for _, path := range r.Spec.Options.BlockedWritePaths {
+		if !strings.HasPrefix(path, "/") {
+			return trace.BadParameter("invalid blocked write path %q, expected an absolute path prefix", path)
+		}
+	}
+
+	if r.Spec.Options.SessionMemoryLimit < 0 {
+		return trace.BadParameter("session memory limit can not be negative")
+	}
+	if r.Spec.Options.SessionCPULimit < 0 {
+		return trace.BadParameter("session cpu limit can not be negative")
+	}
+	if r.Spec.Options.TransferRateLimit < 0 {
+		return trace.BadParameter("transfer rate limit can not be negative")
+	}
+
+	// deny logins support anchored regular expressions, make sure they compile
+	for _, login := range r.Spec.Deny.Logins {
+		if strings.HasPrefix(login, "^") && strings.HasSuffix(login, "$") {
+			if _, err := regexp.Compile(login); err != nil {
+				return trace.BadParameter("invalid deny login pattern %q: %v", login, err)
+			}
+		}
+	}
 	for key, val := range r.Spec.Allow.NodeLabels {
 		if key == Wildcard && !(len(val) == 1 && val[0] == Wildcard) {
 			return trace.BadParameter("selector *:<val> is not supported")
@@ -752,7 +795,12 @@ func (o RoleOptions) Equals(other RoleOptions) bool {
 		o.CertificateFormat == other.CertificateFormat &&
 		o.ClientIdleTimeout.Value() == other.ClientIdleTimeout.Value() &&
 		o.DisconnectExpiredCert.Value() == other.DisconnectExpiredCert.Value() &&
-		utils.StringSlicesEqual(o.BPF, other.BPF))
+		utils.StringSlicesEqual(o.BPF, other.BPF) &&
+		utils.StringSlicesEqual(o.SourceAddresses, other.SourceAddresses) &&
+		utils.StringSlicesEqual(o.BlockedWritePaths, other.BlockedWritePaths) &&
+		o.SessionMemoryLimit == other.SessionMemoryLimit &&
+		o.SessionCPULimit == other.SessionCPULimit &&
+		o.TransferRateLimit == other.TransferRateLimit)
 }
 
 // Equals returns true if the role conditions (logins, namespaces, labels,
@@ -1345,6 +1393,10 @@ type AccessChecker interface {
 	// for this role set, otherwise it returns ttl unchanged
 	AdjustSessionTTL(ttl time.Duration) time.Duration
 
+	// MaxConnections returns the maximum number of concurrent connections
+	// allowed. Zero means no maximum was defined.
+	MaxConnections() int64
+
 	// AdjustClientIdleTimeout adjusts requested idle timeout
 	// to the lowest max allowed timeout, the most restrictive
 	// option will be picked
@@ -1375,6 +1427,10 @@ type AccessChecker interface {
 	// EnhancedRecordingSet returns a set of events that will be recorded
 	// for enhanced session recording.
 	EnhancedRecordingSet() map[string]bool
+
+	// CertSourceAddresses returns the combined list of CIDR blocks issued
+	// certificates are restricted to, empty means no restriction.
+	CertSourceAddresses() []string
 }
 
 // FromSpec returns new RoleSet created from spec
@@ -1574,6 +1630,36 @@ func MatchLogin(selectors []string, login string) (bool, string) {
 	return false, fmt.Sprintf("no match, role selectors %v, login: %v", selectors, login)
 }
 
+// MatchDeniedLogin returns true if attempted login matches any of the deny
+// login selectors. Unlike allow logins, which are literal principals placed
+// in certificates, deny selectors additionally support glob-style wildcards
+// and anchored regular expressions, e.g. "admin-*" or "^(root|toor)$".
+func MatchDeniedLogin(selectors []string, login string) (bool, string) {
+	for _, l := range selectors {
+		// fast path: exact match, the overwhelmingly common case
+		if l == login {
+			return true, "matched"
+		}
+		if !strings.ContainsAny(l, "*^$") {
+			continue
+		}
+		expression := l
+		if !strings.HasPrefix(expression, "^") || !strings.HasSuffix(expression, "$") {
+			expression = "^" + utils.GlobToRegexp(expression) + "$"
+		}
+		expr, err := regexp.Compile(expression)
+		if err != nil {
+			// invalid patterns never match, they are reported when the
+			// role is created or updated
+			continue
+		}
+		if expr.MatchString(login) {
+			return true, "matched"
+		}
+	}
+	return false, fmt.Sprintf("no match, role selectors %v, login: %v", selectors, login)
+}
+
 // MatchLabels matches selector against target. Empty selector matches
 // nothing, wildcard matches everything.
 func MatchLabels(selector Labels, target map[string]string) (bool, string, error) {
@@ -1771,6 +1857,18 @@ func (set RoleSet) CheckLoginDuration(ttl time.Duration) ([]string, error) {
 	if len(logins) == 0 {
 		return nil, trace.AccessDenied("this user cannot create SSH sessions, has no allowed logins")
 	}
+	// strip logins matching a deny selector in any role in the set, deny
+	// selectors apply regardless of the session TTL
+	for _, role := range set {
+		for login := range logins {
+			if match, _ := MatchDeniedLogin(role.GetLogins(Deny), login); match {
+				delete(logins, login)
+			}
+		}
+	}
+	if len(logins) == 0 {
+		return nil, trace.AccessDenied("this user cannot create SSH sessions, all allowed logins are denied by role deny rules")
+	}
 	out := make([]string, 0, len(logins))
 	for login := range logins {
 		out = append(out, login)
@@ -1796,7 +1894,7 @@ func (set RoleSet) CheckAccessToServer(login string, s Server) error {
 		if err != nil {
 			return trace.Wrap(err)
 		}
-		matchLogin, loginMessage := MatchLogin(role.GetLogins(Deny), login)
+		matchLogin, loginMessage := MatchDeniedLogin(role.GetLogins(Deny), login)
 		if matchNamespace && (matchLabels || matchLogin) {
 			if log.GetLevel() == log.DebugLevel {
 				log.WithFields(log.Fields{
@@ -1865,6 +1963,86 @@ func (set RoleSet) PermitX11Forwarding() bool {
 	return false
 }
 
+// CertSourceAddresses returns the combined list of CIDR blocks issued
+// certificates are restricted to. If any role in the set restricts source
+// addresses, the certificate is restricted to the union of the blocks of
+// the restricting roles, otherwise the empty list is returned and the
+// certificate is usable from anywhere.
+func (set RoleSet) CertSourceAddresses() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, role := range set {
+		for _, addr := range role.GetOptions().SourceAddresses {
+			if seen[addr] {
+				continue
+			}
+			seen[addr] = true
+			out = append(out, addr)
+		}
+	}
+	return out
+}
+
+// BlockedWritePaths returns the union of path prefixes writes to which are
+// restricted by any role in the set: a single role blocking a prefix is
+// enough to restrict it for the session.
+func (set RoleSet) BlockedWritePaths() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, role := range set {
+		for _, path := range role.GetOptions().BlockedWritePaths {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// SessionMemoryLimit returns the lowest non-zero session memory limit (in
+// megabytes) in a RoleSet: the most restrictive role wins. Zero means no
+// limit.
+func (set RoleSet) SessionMemoryLimit() int64 {
+	var limit int64
+	for _, role := range set {
+		roleLimit := role.GetOptions().SessionMemoryLimit
+		if roleLimit != 0 && (limit == 0 || roleLimit < limit) {
+			limit = roleLimit
+		}
+	}
+	return limit
+}
+
+// SessionCPULimit returns the lowest non-zero session CPU limit (in percent
+// of a single core) in a RoleSet: the most restrictive role wins. Zero
+// means no limit.
+func (set RoleSet) SessionCPULimit() int64 {
+	var limit int64
+	for _, role := range set {
+		roleLimit := role.GetOptions().SessionCPULimit
+		if roleLimit != 0 && (limit == 0 || roleLimit < limit) {
+			limit = roleLimit
+		}
+	}
+	return limit
+}
+
+// TransferRateLimit returns the lowest non-zero transfer rate limit (in
+// kilobits per second) in a RoleSet: the most restrictive role wins. Zero
+// means no limit.
+func (set RoleSet) TransferRateLimit() int64 {
+	var limit int64
+	for _, role := range set {
+		roleLimit := role.GetOptions().TransferRateLimit
+		if roleLimit != 0 && (limit == 0 || roleLimit < limit) {
+			limit = roleLimit
+		}
+	}
+	return limit
+}
+
 // CertificateFormat returns the most permissive certificate format in a
 // RoleSet.
 func (set RoleSet) CertificateFormat() string {
@@ -2346,7 +2524,18 @@ const RoleSpecV3SchemaTemplate = `{
           "items": { "type": "string" }
         },
         "max_connections": { "type": "number" },
-        "max_sessions": {"type": "number"}
+        "max_sessions": {"type": "number"},
+        "source_addresses": {
+          "type": "array",
+          "items": { "type": "string" }
+        },
+        "blocked_write_paths": {
+          "type": "array",
+          "items": { "type": "string" }
+        },
+        "session_memory_limit": { "type": "number" },
+        "session_cpu_limit": { "type": "number" },
+        "transfer_rate_limit": { "type": "number" }
       }
     },
     "allow": { "$ref": "#/definitions/role_condition" },
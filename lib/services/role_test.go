@@ -33,6 +33,7 @@ import (
 	"github.com/gravitational/teleport/lib/utils"
 	"github.com/gravitational/teleport/lib/wrappers"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/gravitational/trace"
 	"github.com/pborman/uuid"
 	. "gopkg.in/check.v1"
@@ -48,6 +49,25 @@ func (s *RoleSuite) SetUpSuite(c *C) {
 	utils.InitLoggerForTests()
 }
 
+// TestRoleOptionsWireRoundTrip makes sure role options survive proto
+// marshaling, which is how roles replicate to node caches over gRPC.
+func (s *RoleSuite) TestRoleOptionsWireRoundTrip(c *C) {
+	in := RoleOptions{
+		MaxConnections:     5,
+		MaxSessions:        3,
+		SourceAddresses:    []string{"10.0.0.0/8", "1.2.3.4"},
+		BlockedWritePaths:  []string{"/etc", "/usr/local"},
+		SessionMemoryLimit: 512,
+		SessionCPULimit:    50,
+		TransferRateLimit:  10240,
+	}
+	data, err := proto.Marshal(&in)
+	c.Assert(err, IsNil)
+	var out RoleOptions
+	c.Assert(proto.Unmarshal(data, &out), IsNil)
+	c.Assert(out, DeepEquals, in)
+}
+
 // TestConnAndSessLimits verifies that role sets correctly calculate
 // a user's MaxConnections and MaxSessions values from multiple
 // roles with different individual values.  These are tested together since
@@ -87,8 +107,9 @@ func (s *RoleSuite) TestConnAndSessLimits(c *C) {
 				},
 				Spec: RoleSpecV3{
 					Options: RoleOptions{
-						MaxConnections: val,
-						MaxSessions:    val,
+						MaxConnections:    val,
+						MaxSessions:       val,
+						TransferRateLimit: val,
 					},
 				},
 			}
@@ -97,6 +118,7 @@ func (s *RoleSuite) TestConnAndSessLimits(c *C) {
 		}
 		c.Assert(set.MaxConnections(), Equals, tt.want, cmt)
 		c.Assert(set.MaxSessions(), Equals, tt.want, cmt)
+		c.Assert(set.TransferRateLimit(), Equals, tt.want, cmt)
 	}
 }
 
@@ -510,6 +532,31 @@ func (s *RoleSuite) TestLabelCompatibility(c *C) {
 	c.Assert(out, DeepEquals, map[string]string{"key": "val"})
 }
 
+func (s *RoleSuite) TestMatchDeniedLogin(c *C) {
+	testCases := []struct {
+		selectors []string
+		login     string
+		match     bool
+	}{
+		// exact match
+		{selectors: []string{"root"}, login: "root", match: true},
+		{selectors: []string{"root"}, login: "admin", match: false},
+		// glob-style wildcards
+		{selectors: []string{"admin-*"}, login: "admin-db", match: true},
+		{selectors: []string{"admin-*"}, login: "admin", match: false},
+		// anchored regular expressions
+		{selectors: []string{"^(root|toor)$"}, login: "toor", match: true},
+		{selectors: []string{"^(root|toor)$"}, login: "rootless", match: false},
+		// invalid patterns never match
+		{selectors: []string{"^($"}, login: "anything", match: false},
+	}
+	for i, tc := range testCases {
+		comment := Commentf("test case %v: %v", i, tc)
+		match, _ := MatchDeniedLogin(tc.selectors, tc.login)
+		c.Assert(match, Equals, tc.match, comment)
+	}
+}
+
 func (s *RoleSuite) TestCheckAccess(c *C) {
 	type check struct {
 		server    Server
@@ -1727,22 +1774,21 @@ func (s *RoleSuite) TestBoolOptions(c *C) {
 //
 // To run benchmark:
 //
-//    go test -bench=.
+//	go test -bench=.
 //
 // To run benchmark and obtain CPU and memory profiling:
 //
-//    go test -bench=. -cpuprofile=cpu.prof -memprofile=mem.prof
+//	go test -bench=. -cpuprofile=cpu.prof -memprofile=mem.prof
 //
 // To use the command line tool to read the profile:
 //
-//   go tool pprof cpu.prof
-//   go tool pprof cpu.prof
+//	go tool pprof cpu.prof
+//	go tool pprof cpu.prof
 //
 // To generate a graph:
 //
-//   go tool pprof --pdf cpu.prof > cpu.pdf
-//   go tool pprof --pdf mem.prof > mem.pdf
-//
+//	go tool pprof --pdf cpu.prof > cpu.pdf
+//	go tool pprof --pdf mem.prof > mem.pdf
 func BenchmarkCheckAccessToServer(b *testing.B) {
 	servers := make([]*ServerV2, 0, 4000)
 
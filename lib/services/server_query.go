@@ -0,0 +1,160 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/gravitational/trace"
+)
+
+// MatchServerQuery returns true if the server matches the given predicate
+// expression, e.g.:
+//
+//	labels.team == "db" && name.contains("etcd")
+//
+// The expression language supports the `labels.<key>` and `name` identifiers,
+// the ==, !=, &&, || and ! operators and the `<identifier>.contains("...")`
+// helper.
+func MatchServerQuery(server Server, expr string) (bool, error) {
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return false, trace.BadParameter("failed to parse query %q: %v", expr, err)
+	}
+	match, err := evalServerExpr(server, node)
+	if err != nil {
+		return false, trace.BadParameter("failed to evaluate query %q: %v", expr, err)
+	}
+	return match, nil
+}
+
+// evalServerExpr evaluates a boolean expression against the server.
+func evalServerExpr(server Server, node ast.Expr) (bool, error) {
+	switch n := node.(type) {
+	case *ast.ParenExpr:
+		return evalServerExpr(server, n.X)
+	case *ast.UnaryExpr:
+		if n.Op != token.NOT {
+			return false, trace.BadParameter("unsupported operator %q", n.Op)
+		}
+		match, err := evalServerExpr(server, n.X)
+		if err != nil {
+			return false, trace.Wrap(err)
+		}
+		return !match, nil
+	case *ast.BinaryExpr:
+		switch n.Op {
+		case token.LAND, token.LOR:
+			left, err := evalServerExpr(server, n.X)
+			if err != nil {
+				return false, trace.Wrap(err)
+			}
+			right, err := evalServerExpr(server, n.Y)
+			if err != nil {
+				return false, trace.Wrap(err)
+			}
+			if n.Op == token.LAND {
+				return left && right, nil
+			}
+			return left || right, nil
+		case token.EQL, token.NEQ:
+			left, err := evalServerValue(server, n.X)
+			if err != nil {
+				return false, trace.Wrap(err)
+			}
+			right, err := evalServerValue(server, n.Y)
+			if err != nil {
+				return false, trace.Wrap(err)
+			}
+			if n.Op == token.EQL {
+				return left == right, nil
+			}
+			return left != right, nil
+		}
+		return false, trace.BadParameter("unsupported operator %q", n.Op)
+	case *ast.CallExpr:
+		// <identifier>.contains("substring")
+		sel, ok := n.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "contains" {
+			return false, trace.BadParameter("unsupported function call")
+		}
+		value, err := evalServerValue(server, sel.X)
+		if err != nil {
+			return false, trace.Wrap(err)
+		}
+		if len(n.Args) != 1 {
+			return false, trace.BadParameter("contains expects a single argument")
+		}
+		arg, err := evalServerValue(server, n.Args[0])
+		if err != nil {
+			return false, trace.Wrap(err)
+		}
+		return strings.Contains(value, arg), nil
+	}
+	return false, trace.BadParameter("unsupported expression %T", node)
+}
+
+// evalServerValue evaluates a string-valued expression against the server:
+// either a string literal, the `name` identifier or a `labels.<key>`
+// selector.
+func evalServerValue(server Server, node ast.Expr) (string, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.STRING {
+			return "", trace.BadParameter("unsupported literal %v", n.Value)
+		}
+		value, err := strconv.Unquote(n.Value)
+		if err != nil {
+			return "", trace.Wrap(err)
+		}
+		return value, nil
+	case *ast.Ident:
+		if n.Name != "name" {
+			return "", trace.NotFound("%v is not defined", n.Name)
+		}
+		return server.GetHostname(), nil
+	case *ast.SelectorExpr:
+		ident, ok := n.X.(*ast.Ident)
+		if !ok || ident.Name != "labels" {
+			return "", trace.BadParameter("unsupported identifier")
+		}
+		return server.GetAllLabels()[n.Sel.Name], nil
+	}
+	return "", trace.BadParameter("unsupported expression %T", node)
+}
+
+// MatchServerSearch returns true if the server matches all of the given
+// fuzzy search keywords. Keywords are matched case-insensitively against the
+// node name, hostname, address and labels.
+func MatchServerSearch(server Server, keywords []string) bool {
+	var fields []string
+	fields = append(fields, server.GetName(), server.GetHostname(), server.GetAddr())
+	for key, value := range server.GetAllLabels() {
+		fields = append(fields, key, value)
+	}
+	haystack := strings.ToLower(strings.Join(fields, " "))
+	for _, keyword := range keywords {
+		if !strings.Contains(haystack, strings.ToLower(keyword)) {
+			return false
+		}
+	}
+	return true
+}
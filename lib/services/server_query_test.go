@@ -0,0 +1,84 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/gravitational/teleport/lib/defaults"
+
+	"gopkg.in/check.v1"
+)
+
+type ServerQuerySuite struct {
+}
+
+var _ = check.Suite(&ServerQuerySuite{})
+
+func (s *ServerQuerySuite) newServer(c *check.C) Server {
+	return &ServerV2{
+		Kind:    KindNode,
+		Version: V2,
+		Metadata: Metadata{
+			Name:      "44a54d40-591a-47cc-9d1c-bcc1a2b38a07",
+			Namespace: defaults.Namespace,
+			Labels:    map[string]string{"team": "db", "env": "prod"},
+		},
+		Spec: ServerSpecV2{
+			Hostname: "etcd-0.example.com",
+			Addr:     "10.0.0.1:3022",
+		},
+	}
+}
+
+// TestMatchServerQuery verifies matching servers against predicate
+// expressions.
+func (s *ServerQuerySuite) TestMatchServerQuery(c *check.C) {
+	node := s.newServer(c)
+
+	testCases := []struct {
+		expr  string
+		match bool
+	}{
+		{expr: `labels.team == "db"`, match: true},
+		{expr: `labels.team != "db"`, match: false},
+		{expr: `labels.team == "db" && labels.env == "prod"`, match: true},
+		{expr: `labels.team == "db" && labels.env != "prod"`, match: false},
+		{expr: `labels.missing == ""`, match: true},
+		{expr: `name.contains("etcd")`, match: true},
+		{expr: `name.contains("mongo")`, match: false},
+		{expr: `labels.team == "db" || labels.team == "web"`, match: true},
+	}
+	for _, tc := range testCases {
+		match, err := MatchServerQuery(node, tc.expr)
+		c.Assert(err, check.IsNil, check.Commentf("expr=%q", tc.expr))
+		c.Assert(match, check.Equals, tc.match, check.Commentf("expr=%q", tc.expr))
+	}
+
+	// invalid expressions should return errors
+	_, err := MatchServerQuery(node, `env ==`)
+	c.Assert(err, check.NotNil)
+}
+
+// TestMatchServerSearch verifies fuzzy matching servers against search
+// keywords.
+func (s *ServerQuerySuite) TestMatchServerSearch(c *check.C) {
+	node := s.newServer(c)
+
+	c.Assert(MatchServerSearch(node, []string{"etcd"}), check.Equals, true)
+	c.Assert(MatchServerSearch(node, []string{"ETCD", "prod"}), check.Equals, true)
+	c.Assert(MatchServerSearch(node, []string{"etcd", "staging"}), check.Equals, false)
+	c.Assert(MatchServerSearch(node, []string{"10.0.0.1"}), check.Equals, true)
+}
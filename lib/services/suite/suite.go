@@ -317,6 +317,26 @@ func (s *ServicesTestSuite) ServerCRUD(c *check.C) {
 	srv.SetResourceID(out[0].GetResourceID())
 	fixtures.DeepCompare(c, out, []services.Server{srv})
 
+	// list nodes one at a time to exercise pagination
+	srv2 := NewServer(services.KindNode, "srv2", "127.0.0.1:2023", defaults.Namespace)
+	_, err = s.PresenceS.UpsertNode(srv2)
+	c.Assert(err, check.IsNil)
+
+	page, nextKey, err := s.PresenceS.ListNodes(defaults.Namespace, 1, "")
+	c.Assert(err, check.IsNil)
+	c.Assert(page, check.HasLen, 1)
+	c.Assert(page[0].GetName(), check.Equals, srv.GetName())
+	c.Assert(nextKey, check.Equals, srv2.GetName())
+
+	page, nextKey, err = s.PresenceS.ListNodes(defaults.Namespace, 1, nextKey)
+	c.Assert(err, check.IsNil)
+	c.Assert(page, check.HasLen, 1)
+	c.Assert(page[0].GetName(), check.Equals, srv2.GetName())
+	c.Assert(nextKey, check.Equals, "")
+
+	err = s.PresenceS.DeleteNode(srv2.Metadata.Namespace, srv2.GetName())
+	c.Assert(err, check.IsNil)
+
 	err = s.PresenceS.DeleteNode(srv.Metadata.Namespace, srv.GetName())
 	c.Assert(err, check.IsNil)
 
@@ -423,6 +443,28 @@ func (s *ServicesTestSuite) PasswordHashCRUD(c *check.C) {
 	c.Assert(hash, check.DeepEquals, []byte("hello321"))
 }
 
+func (s *ServicesTestSuite) UserPreferencesCRUD(c *check.C) {
+	// defaults are returned before anything has been stored
+	prefs, err := s.WebS.GetUserPreferences("user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(prefs, check.DeepEquals, &services.UserPreferences{})
+
+	expected := services.UserPreferences{
+		Theme:           services.ThemeDark,
+		DefaultCluster:  "moon",
+		PinnedResources: []string{"node1", "node2"},
+	}
+	err = s.WebS.UpsertUserPreferences("user1", expected)
+	c.Assert(err, check.IsNil)
+
+	prefs, err = s.WebS.GetUserPreferences("user1")
+	c.Assert(err, check.IsNil)
+	c.Assert(prefs, check.DeepEquals, &expected)
+
+	err = s.WebS.UpsertUserPreferences("user1", services.UserPreferences{Theme: "neon"})
+	fixtures.ExpectBadParameter(c, err)
+}
+
 func (s *ServicesTestSuite) WebSessionCRUD(c *check.C) {
 	_, err := s.WebS.GetWebSession("user1", "sid1")
 	c.Assert(trace.IsNotFound(err), check.Equals, true, check.Commentf("%#v", err))
@@ -571,6 +613,16 @@ func (s *ServicesTestSuite) RolesCRUD(c *check.C) {
 	role.SetResourceID(rout.GetResourceID())
 	c.Assert(rout, check.DeepEquals, &role)
 
+	// compare-and-swap succeeds when the existing value matches the backend
+	updated := role
+	updated.Spec.Allow.Logins = []string{"alice"}
+	err = s.Access.CompareAndSwapRole(ctx, &updated, rout)
+	c.Assert(err, check.IsNil)
+
+	// a second swap against the now stale existing value fails
+	err = s.Access.CompareAndSwapRole(ctx, &role, rout)
+	fixtures.ExpectCompareFailed(c, err)
+
 	err = s.Access.DeleteRole(ctx, role.Metadata.Name)
 	c.Assert(err, check.IsNil)
 
@@ -1167,8 +1167,11 @@ type AuditConfig struct {
 	// AuditTableName is a DB table name used for audits
 	// Deprecated in favor of AuditEventsURI
 	// DELETE IN (3.1.0)
-	AuditTableName       string   `protobuf:"bytes,5,opt,name=AuditTableName,proto3" json:"audit_table_name,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	AuditTableName string `protobuf:"bytes,5,opt,name=AuditTableName,proto3" json:"audit_table_name,omitempty"`
+	// AuditSessionsDualWrite enables writing session recordings to local
+	// disk in addition to the external handler set by AuditSessionsURI
+	AuditSessionsDualWrite bool     `protobuf:"varint,6,opt,name=AuditSessionsDualWrite,proto3" json:"audit_sessions_dual_write,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
@@ -1986,7 +1989,27 @@ type RoleOptions struct {
 	MaxConnections int64 `protobuf:"varint,9,opt,name=MaxConnections,proto3" json:"max_connections,omitempty"`
 	// MaxSessions defines the maximum number of
 	// concurrent sessions per connection.
-	MaxSessions          int64    `protobuf:"varint,10,opt,name=MaxSessions,proto3" json:"max_sessions,omitempty"`
+	MaxSessions int64 `protobuf:"varint,10,opt,name=MaxSessions,proto3" json:"max_sessions,omitempty"`
+	// SourceAddresses is a list of CIDR blocks or IP addresses, when set,
+	// issued SSH certificates carry them in the source-address critical
+	// option and can only be used from these networks.
+	SourceAddresses []string `protobuf:"bytes,11,rep,name=SourceAddresses" json:"source_addresses,omitempty"`
+	// BlockedWritePaths is a list of absolute path prefixes writes to which
+	// are restricted for session processes, enforced by the enhanced
+	// recording subsystem.
+	BlockedWritePaths []string `protobuf:"bytes,12,rep,name=BlockedWritePaths" json:"blocked_write_paths,omitempty"`
+	// SessionMemoryLimit is the number of megabytes of memory processes in
+	// a session may consume before the kernel starts reclaiming and OOM
+	// killing them, applied to the session cgroup. Zero means no limit.
+	SessionMemoryLimit int64 `protobuf:"varint,13,opt,name=SessionMemoryLimit,proto3" json:"session_memory_limit,omitempty"`
+	// SessionCPULimit is the percentage of a single CPU core processes in
+	// a session may consume, applied to the session cgroup. Zero means no
+	// limit.
+	SessionCPULimit int64 `protobuf:"varint,14,opt,name=SessionCPULimit,proto3" json:"session_cpu_limit,omitempty"`
+	// TransferRateLimit caps the combined throughput of file transfers and
+	// forwarded connections in a session, in kilobits per second. Zero
+	// means no limit.
+	TransferRateLimit    int64    `protobuf:"varint,15,opt,name=TransferRateLimit,proto3" json:"transfer_rate_limit,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -4422,6 +4445,16 @@ func (m *AuditConfig) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintTypes(dAtA, i, uint64(len(m.AuditTableName)))
 		i += copy(dAtA[i:], m.AuditTableName)
 	}
+	if m.AuditSessionsDualWrite {
+		dAtA[i] = 0x30
+		i++
+		if m.AuditSessionsDualWrite {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -5302,6 +5335,51 @@ func (m *RoleOptions) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintTypes(dAtA, i, uint64(m.MaxSessions))
 	}
+	if len(m.SourceAddresses) > 0 {
+		for _, s := range m.SourceAddresses {
+			dAtA[i] = 0x5a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if len(m.BlockedWritePaths) > 0 {
+		for _, s := range m.BlockedWritePaths {
+			dAtA[i] = 0x62
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.SessionMemoryLimit != 0 {
+		dAtA[i] = 0x68
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(m.SessionMemoryLimit))
+	}
+	if m.SessionCPULimit != 0 {
+		dAtA[i] = 0x70
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(m.SessionCPULimit))
+	}
+	if m.TransferRateLimit != 0 {
+		dAtA[i] = 0x78
+		i++
+		i = encodeVarintTypes(dAtA, i, uint64(m.TransferRateLimit))
+	}
 	if m.XXX_unrecognized != nil {
 		i += copy(dAtA[i:], m.XXX_unrecognized)
 	}
@@ -7051,6 +7129,9 @@ func (m *AuditConfig) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovTypes(uint64(l))
 	}
+	if m.AuditSessionsDualWrite {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -7450,6 +7531,27 @@ func (m *RoleOptions) Size() (n int) {
 	if m.MaxSessions != 0 {
 		n += 1 + sovTypes(uint64(m.MaxSessions))
 	}
+	if len(m.SourceAddresses) > 0 {
+		for _, s := range m.SourceAddresses {
+			l = len(s)
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if len(m.BlockedWritePaths) > 0 {
+		for _, s := range m.BlockedWritePaths {
+			l = len(s)
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if m.SessionMemoryLimit != 0 {
+		n += 1 + sovTypes(uint64(m.SessionMemoryLimit))
+	}
+	if m.SessionCPULimit != 0 {
+		n += 1 + sovTypes(uint64(m.SessionCPULimit))
+	}
+	if m.TransferRateLimit != 0 {
+		n += 1 + sovTypes(uint64(m.TransferRateLimit))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -12104,6 +12206,26 @@ func (m *AuditConfig) Unmarshal(dAtA []byte) error {
 			}
 			m.AuditTableName = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuditSessionsDualWrite", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.AuditSessionsDualWrite = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -15211,6 +15333,121 @@ func (m *RoleOptions) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SourceAddresses", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SourceAddresses = append(m.SourceAddresses, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockedWritePaths", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlockedWritePaths = append(m.BlockedWritePaths, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SessionMemoryLimit", wireType)
+			}
+			m.SessionMemoryLimit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SessionMemoryLimit |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SessionCPULimit", wireType)
+			}
+			m.SessionCPULimit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SessionCPULimit |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TransferRateLimit", wireType)
+			}
+			m.TransferRateLimit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TransferRateLimit |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"sort"
+	"time"
+)
+
+// UnifiedResource is a flattened summary of a single resource a user can
+// access, used by listings that mix several resource kinds in one response
+// (the web UI landing page, `tsh ls --all`).
+type UnifiedResource struct {
+	// Kind is the resource kind, e.g. KindNode or KindKubeCluster.
+	Kind string `json:"kind"`
+	// Name is the unique resource name within its kind.
+	Name string `json:"name"`
+	// Hostname is set for nodes.
+	Hostname string `json:"hostname,omitempty"`
+	// Addr is the resource address, when it has one.
+	Addr string `json:"addr,omitempty"`
+	// Labels combines static and dynamic labels.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Health reflects the freshness of the last heartbeat,
+	// either "online" or "offline".
+	Health string `json:"health"`
+}
+
+// Health values for UnifiedResource.
+const (
+	// UnifiedResourceOnline means the resource heartbeat has not expired.
+	UnifiedResourceOnline = "online"
+	// UnifiedResourceOffline means the resource heartbeat has expired.
+	UnifiedResourceOffline = "offline"
+)
+
+// UnifiedResourcePage is one page of a unified resource listing.
+type UnifiedResourcePage struct {
+	// Resources is the page contents, sorted by kind then name.
+	Resources []UnifiedResource `json:"resources"`
+	// NextKey is the key to pass as the start key of the next request,
+	// empty when there are no more pages.
+	NextKey string `json:"next_key,omitempty"`
+}
+
+// MaxUnifiedResourcePageSize caps how many resources one page can hold.
+const MaxUnifiedResourcePageSize = 1000
+
+// unifiedResourceKey builds the sort/pagination key for a resource.
+func unifiedResourceKey(r UnifiedResource) string {
+	return r.Kind + "/" + r.Name
+}
+
+// serverHealth derives a health value from a server heartbeat expiry.
+func serverHealth(expires time.Time) string {
+	if expires.IsZero() || expires.After(time.Now()) {
+		return UnifiedResourceOnline
+	}
+	return UnifiedResourceOffline
+}
+
+// BuildUnifiedResourcePage flattens nodes and the kubernetes clusters
+// advertised by proxies into a single sorted page. Pagination is keyed on
+// "kind/name": pass an empty startKey for the first page and the returned
+// NextKey for subsequent ones. A limit of zero or above
+// MaxUnifiedResourcePageSize is clamped to MaxUnifiedResourcePageSize.
+func BuildUnifiedResourcePage(nodes []Server, proxies []Server, startKey string, limit int) UnifiedResourcePage {
+	var all []UnifiedResource
+	for _, node := range nodes {
+		all = append(all, UnifiedResource{
+			Kind:     KindNode,
+			Name:     node.GetName(),
+			Hostname: node.GetHostname(),
+			Addr:     node.GetAddr(),
+			Labels:   node.GetAllLabels(),
+			Health:   serverHealth(node.Expiry()),
+		})
+	}
+	// The same kubernetes cluster can be served by multiple proxies,
+	// keep the healthiest advertisement for each.
+	clusters := make(map[string]string)
+	for _, proxy := range proxies {
+		health := serverHealth(proxy.Expiry())
+		for _, cluster := range proxy.GetKubernetesClusters() {
+			if clusters[cluster] != UnifiedResourceOnline {
+				clusters[cluster] = health
+			}
+		}
+	}
+	for cluster, health := range clusters {
+		all = append(all, UnifiedResource{
+			Kind:   KindKubeCluster,
+			Name:   cluster,
+			Health: health,
+		})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return unifiedResourceKey(all[i]) < unifiedResourceKey(all[j])
+	})
+
+	if limit <= 0 || limit > MaxUnifiedResourcePageSize {
+		limit = MaxUnifiedResourcePageSize
+	}
+	var page UnifiedResourcePage
+	for _, r := range all {
+		if startKey != "" && unifiedResourceKey(r) <= startKey {
+			continue
+		}
+		if len(page.Resources) == limit {
+			page.NextKey = unifiedResourceKey(page.Resources[limit-1])
+			break
+		}
+		page.Resources = append(page.Resources, r)
+	}
+	return page
+}
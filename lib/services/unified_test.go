@@ -0,0 +1,75 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func unifiedTestServer(name, hostname string, kubeClusters ...string) Server {
+	return &ServerV2{
+		Kind:    KindNode,
+		Version: V2,
+		Metadata: Metadata{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: ServerSpecV2{
+			Hostname:           hostname,
+			KubernetesClusters: kubeClusters,
+		},
+	}
+}
+
+// TestBuildUnifiedResourcePage verifies that nodes and proxy-advertised
+// kubernetes clusters are flattened into one sorted listing and that
+// pagination resumes from the returned next key without duplicates.
+func TestBuildUnifiedResourcePage(t *testing.T) {
+	nodes := []Server{
+		unifiedTestServer("node-b", "beta"),
+		unifiedTestServer("node-a", "alpha"),
+	}
+	proxies := []Server{
+		unifiedTestServer("proxy-1", "proxy-1", "kube-west", "kube-east"),
+		unifiedTestServer("proxy-2", "proxy-2", "kube-east"),
+	}
+
+	page := BuildUnifiedResourcePage(nodes, proxies, "", 0)
+	assert.Empty(t, page.NextKey)
+	var keys []string
+	for _, r := range page.Resources {
+		keys = append(keys, r.Kind+"/"+r.Name)
+	}
+	assert.Equal(t, []string{
+		"kube_cluster/kube-east",
+		"kube_cluster/kube-west",
+		"node/node-a",
+		"node/node-b",
+	}, keys)
+
+	// Page through with a limit of 3 and make sure the second page picks
+	// up exactly where the first one left off.
+	first := BuildUnifiedResourcePage(nodes, proxies, "", 3)
+	assert.Len(t, first.Resources, 3)
+	assert.Equal(t, "node/node-a", first.NextKey)
+	second := BuildUnifiedResourcePage(nodes, proxies, first.NextKey, 3)
+	assert.Empty(t, second.NextKey)
+	assert.Len(t, second.Resources, 1)
+	assert.Equal(t, "node-b", second.Resources[0].Name)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"github.com/gravitational/trace"
+)
+
+const (
+	// ThemeLight is the light web UI theme.
+	ThemeLight = "light"
+	// ThemeDark is the dark web UI theme.
+	ThemeDark = "dark"
+)
+
+// UserPreferences are per-user web UI settings stored on the auth server,
+// so they follow the user across browsers and machines.
+type UserPreferences struct {
+	// Theme is the web UI theme, one of "light" or "dark". An empty value
+	// means the UI default.
+	Theme string `json:"theme,omitempty"`
+	// DefaultCluster is the name of the cluster the web UI opens first.
+	DefaultCluster string `json:"default_cluster,omitempty"`
+	// PinnedResources are names of resources the user pinned in the web UI.
+	PinnedResources []string `json:"pinned_resources,omitempty"`
+}
+
+// Check checks the preferences for validity
+func (p *UserPreferences) Check() error {
+	switch p.Theme {
+	case "", ThemeLight, ThemeDark:
+	default:
+		return trace.BadParameter("unsupported theme %q, expected %q or %q", p.Theme, ThemeLight, ThemeDark)
+	}
+	return nil
+}
@@ -84,7 +84,7 @@ func (h *AuthHandlers) CreateIdentityContext(sconn *ssh.ServerConn) (IdentityCon
 	}
 	identity.CertAuthority = certAuthority
 
-	roleSet, err := h.fetchRoleSet(certificate, certAuthority, identity.TeleportUser, clusterName.GetClusterName())
+	roleSet, err := fetchRoleSet(h.AccessPoint, certificate, certAuthority, identity.TeleportUser, clusterName.GetClusterName())
 	if err != nil {
 		return IdentityContext{}, trace.Wrap(err)
 	}
@@ -332,7 +332,7 @@ func (h *AuthHandlers) canLoginWithRBAC(cert *ssh.Certificate, clusterName strin
 	}
 
 	// get roles assigned to this user
-	roles, err := h.fetchRoleSet(cert, ca, teleportUser, clusterName)
+	roles, err := fetchRoleSet(h.AccessPoint, cert, ca, teleportUser, clusterName)
 	if err != nil {
 		return trace.Wrap(err)
 	}
@@ -347,17 +347,17 @@ func (h *AuthHandlers) canLoginWithRBAC(cert *ssh.Certificate, clusterName strin
 }
 
 // fetchRoleSet fetches the services.RoleSet assigned to a Teleport user.
-func (h *AuthHandlers) fetchRoleSet(cert *ssh.Certificate, ca services.CertAuthority, teleportUser string, clusterName string) (services.RoleSet, error) {
+func fetchRoleSet(point auth.AccessPoint, cert *ssh.Certificate, ca services.CertAuthority, teleportUser string, clusterName string) (services.RoleSet, error) {
 	// for local users, go and check their individual permissions
 	var roleset services.RoleSet
 	if clusterName == ca.GetClusterName() {
 		// Extract roles and traits either from the certificate or from
 		// services.User and create a services.RoleSet with all runtime roles.
-		roles, traits, err := services.ExtractFromCertificate(h.AccessPoint, cert)
+		roles, traits, err := services.ExtractFromCertificate(point, cert)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
-		roleset, err = services.FetchRoles(roles, h.AccessPoint, traits)
+		roleset, err = services.FetchRoles(roles, point, traits)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
@@ -375,7 +375,7 @@ func (h *AuthHandlers) fetchRoleSet(cert *ssh.Certificate, ca services.CertAutho
 		traits := map[string][]string{
 			teleport.TraitLogins: cert.ValidPrincipals,
 		}
-		roleset, err = services.FetchRoles(roleNames, h.AccessPoint, traits)
+		roleset, err = services.FetchRoles(roleNames, point, traits)
 		if err != nil {
 			return nil, trace.Wrap(err)
 		}
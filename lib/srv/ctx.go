@@ -93,6 +93,10 @@ type Server interface {
 	// startup is allowed.
 	PermitUserEnvironment() bool
 
+	// DisconnectOnRoleChange returns if connections should be terminated
+	// when the user's roles no longer allow access to this server.
+	DisconnectOnRoleChange() bool
+
 	// GetAccessPoint returns an auth.AccessPoint for this cluster.
 	GetAccessPoint() auth.AccessPoint
 
@@ -233,6 +237,11 @@ type ServerContext struct {
 	// clientLastActive records the last time there was activity from the client
 	clientLastActive time.Time
 
+	// trackingChan is set when the channel serviced by this context is
+	// wrapped with TrackActivity. It keeps per-channel byte counts used
+	// to report data usage for exec, scp and port forwarding requests.
+	trackingChan *trackingChannel
+
 	// disconnectExpiredCert is set to time when/if the certificate should
 	// be disconnected, set to empty if no disconect is necessary
 	disconnectExpiredCert time.Time
@@ -331,26 +340,30 @@ func NewServerContext(ctx context.Context, parent *sshutils.ConnectionContext, s
 		trace.ComponentFields: fields,
 	})
 
-	if !child.disconnectExpiredCert.IsZero() || child.clientIdleTimeout != 0 {
-		mon, err := NewMonitor(MonitorConfig{
-			DisconnectExpiredCert: child.disconnectExpiredCert,
-			ClientIdleTimeout:     child.clientIdleTimeout,
-			Clock:                 child.srv.GetClock(),
-			Tracker:               child,
-			Conn:                  child.ServerConn,
-			Context:               cancelContext,
-			TeleportUser:          child.Identity.TeleportUser,
-			Login:                 child.Identity.Login,
-			ServerID:              child.srv.ID(),
-			Entry:                 child.Entry,
-			Emitter:               child.srv,
-		})
-		if err != nil {
-			child.Close()
-			return nil, nil, trace.Wrap(err)
-		}
-		go mon.Start()
+	// The monitor always runs: in addition to the optional expired
+	// certificate and idle timeout enforcement, it re-evaluates access when
+	// roles or the user change so changes are not deferred to next login.
+	mon, err := NewMonitor(MonitorConfig{
+		DisconnectExpiredCert:  child.disconnectExpiredCert,
+		ClientIdleTimeout:      child.clientIdleTimeout,
+		Clock:                  child.srv.GetClock(),
+		Tracker:                child,
+		Conn:                   child.ServerConn,
+		Context:                cancelContext,
+		TeleportUser:           child.Identity.TeleportUser,
+		Login:                  child.Identity.Login,
+		ServerID:               child.srv.ID(),
+		Entry:                  child.Entry,
+		Emitter:                child.srv,
+		AccessPoint:            srv.GetAccessPoint(),
+		RecheckAccess:          child.recheckAccess,
+		DisconnectOnRoleChange: srv.DisconnectOnRoleChange(),
+	})
+	if err != nil {
+		child.Close()
+		return nil, nil, trace.Wrap(err)
 	}
+	go mon.Start()
 
 	// Create pipe used to send command to child process.
 	child.cmdr, child.cmdw, err = os.Pipe()
@@ -395,6 +408,30 @@ func (c *ServerContext) GetServer() Server {
 	return c.srv
 }
 
+// recheckAccess re-evaluates whether the user's current roles still allow
+// the connection. It is called by the connection monitor when a role or the
+// user changes, so revoked access does not wait until next login.
+func (c *ServerContext) recheckAccess() error {
+	cert, err := c.Identity.GetCertificate()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	clusterName, err := c.srv.GetAccessPoint().GetClusterName()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roles, err := fetchRoleSet(c.srv.GetAccessPoint(), cert, c.Identity.CertAuthority,
+		c.Identity.TeleportUser, clusterName.GetClusterName())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := roles.CheckAccessToServer(c.Identity.Login, c.srv.GetInfo()); err != nil {
+		return trace.AccessDenied("user %v is no longer authorized to login as %v: %v",
+			c.Identity.TeleportUser, c.Identity.Login, err)
+	}
+	return nil
+}
+
 // CreateOrJoinSession will look in the SessionRegistry for the session ID. If
 // no session is found, a new one is created. If one is found, it is returned.
 func (c *ServerContext) CreateOrJoinSession(reg *SessionRegistry) error {
@@ -430,7 +467,19 @@ func (c *ServerContext) CreateOrJoinSession(reg *SessionRegistry) error {
 // TrackActivity keeps track of all activity on ssh.Channel. The caller should
 // use the returned ssh.Channel instead of the original one.
 func (c *ServerContext) TrackActivity(ch ssh.Channel) ssh.Channel {
-	return newTrackingChannel(ch, c)
+	tch := newTrackingChannel(ch, c)
+	c.Lock()
+	c.trackingChan = tch
+	c.Unlock()
+	return tch
+}
+
+// trackingChannel returns the tracked channel serviced by this context, if
+// any.
+func (c *ServerContext) trackingChannel() *trackingChannel {
+	c.RLock()
+	defer c.RUnlock()
+	return c.trackingChan
 }
 
 // GetClientLastActive returns time when client was last active
@@ -513,8 +562,9 @@ func (c *ServerContext) takeClosers() []io.Closer {
 	return closers
 }
 
-// When the ServerContext (connection) is closed, emit "session.data" event
-// containing how much data was transmitted and received over the net.Conn.
+// When the ServerContext is closed, emit "session.data" event containing
+// how much data was transmitted and received over the channel (or net.Conn
+// for older callers that do not track individual channels).
 func (c *ServerContext) reportStats(conn utils.Stater) {
 	// Never emit session data events for the proxy or from a Teleport node if
 	// sessions are being recorded at the proxy (this would result in double
@@ -570,9 +620,12 @@ func (c *ServerContext) reportStats(conn utils.Stater) {
 }
 
 func (c *ServerContext) Close() error {
-	// If the underlying connection is holding tracking information, report that
-	// to the audit log at close.
-	if stats, ok := c.NetConn.(*utils.TrackingConn); ok {
+	// Report data usage to the audit log at close. Prefer the per-channel
+	// counters (exec, scp and port forwarding requests) and fall back to the
+	// connection level counters when the channel was not tracked.
+	if tch := c.trackingChannel(); tch != nil {
+		defer c.reportStats(tch)
+	} else if stats, ok := c.NetConn.(*utils.TrackingConn); ok {
 		defer c.reportStats(stats)
 	}
 
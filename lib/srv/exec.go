@@ -148,9 +148,12 @@ func (e *localExec) Start(channel ssh.Channel) (*ExecResult, error) {
 	}
 
 	// Connect stdout and stderr to the channel so the user can interact with
-	// the command.
+	// the command. If the roles of the user cap transfer throughput, wrap the
+	// channel in a token bucket so bulk copies (like scp) can not saturate
+	// the uplink.
+	throttled := utils.NewThrottledReadWriter(channel, e.Ctx.Identity.RoleSet.TransferRateLimit())
 	e.Cmd.Stderr = channel.Stderr()
-	e.Cmd.Stdout = channel
+	e.Cmd.Stdout = throttled
 
 	// Copy from the channel (client input) into stdin of the process.
 	inputWriter, err := e.Cmd.StdinPipe()
@@ -158,7 +161,7 @@ func (e *localExec) Start(channel ssh.Channel) (*ExecResult, error) {
 		return nil, trace.Wrap(err)
 	}
 	go func() {
-		if _, err := io.Copy(inputWriter, channel); err != nil {
+		if _, err := io.Copy(inputWriter, throttled); err != nil {
 			e.Ctx.Warningf("Failed to forward data from SSH channel to local command %q stdin: %v", e.GetCommand(), err)
 		}
 		inputWriter.Close()
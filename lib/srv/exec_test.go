@@ -444,6 +444,10 @@ func (f *fakeServer) PermitUserEnvironment() bool {
 	return true
 }
 
+func (f *fakeServer) DisconnectOnRoleChange() bool {
+	return false
+}
+
 func (f *fakeServer) GetAccessPoint() auth.AccessPoint {
 	return f.accessPoint
 }
@@ -492,6 +496,14 @@ func (a *fakeLog) UploadSessionRecording(r events.SessionRecording) error {
 	return trace.NotImplemented("not implemented")
 }
 
+func (a *fakeLog) DownloadSessionRecording(namespace string, sid rsession.ID, writer io.Writer) error {
+	return trace.NotImplemented("not implemented")
+}
+
+func (a *fakeLog) DeleteSessionRecording(namespace string, sid rsession.ID) error {
+	return trace.NotImplemented("not implemented")
+}
+
 func (a *fakeLog) GetSessionChunk(namespace string, sid rsession.ID, offsetBytes int, maxBytes int) ([]byte, error) {
 	return nil, trace.NotFound("")
 }
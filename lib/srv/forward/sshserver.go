@@ -357,6 +357,12 @@ func (s *Server) PermitUserEnvironment() bool {
 	return false
 }
 
+// DisconnectOnRoleChange is always false because it's up to the remote host
+// to decide if connections are terminated when roles change.
+func (s *Server) DisconnectOnRoleChange() bool {
+	return false
+}
+
 // GetAccessPoint returns an auth.AccessPoint for this cluster.
 func (s *Server) GetAccessPoint() auth.AccessPoint {
 	return s.authService
@@ -481,9 +487,17 @@ func (s *Server) Serve() {
 		return
 	}
 
+	// Try and fetch the name of this cluster. If the name can not be fetched,
+	// the keep-alive loop still runs, round-trip latency is just not recorded.
+	var clusterName string
+	if cn, err := s.GetAccessPoint().GetClusterName(); err == nil {
+		clusterName = cn.GetClusterName()
+	}
+
 	// The keep-alive loop will keep pinging the remote server and after it has
 	// missed a certain number of keep-alive requests it will cancel the
-	// closeContext which signals the server to shutdown.
+	// closeContext which signals the server to shutdown. Successful round
+	// trips double as timing probes and are recorded as session latency.
 	go srv.StartKeepAliveLoop(srv.KeepAliveParams{
 		Conns: []srv.RequestSender{
 			s.sconn,
@@ -493,6 +507,8 @@ func (s *Server) Serve() {
 		MaxCount:     clusterConfig.GetKeepAliveCountMax(),
 		CloseContext: ctx,
 		CloseCancel:  func() { s.connectionContext.Close() },
+		ClusterName:  clusterName,
+		ServerID:     s.hostUUID,
 	})
 
 	go s.handleConnection(ctx, chans, reqs)
@@ -719,12 +735,16 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ch ssh.Channel, r
 		scx.WithError(err).Warn("Failed to emit port forward event.")
 	}
 
+	// If the roles of the user cap transfer throughput, wrap the channel in
+	// a token bucket so bulk copies can not saturate the uplink.
+	throttled := utils.NewThrottledReadWriter(ch, s.identityContext.RoleSet.TransferRateLimit())
+
 	var wg sync.WaitGroup
 	wch := make(chan struct{})
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if _, err := io.Copy(ch, conn); err != nil {
+		if _, err := io.Copy(throttled, conn); err != nil {
 			scx.Warningf("failed proxying data for port forwarding connection: %v", err)
 		}
 		ch.Close()
@@ -732,7 +752,7 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ch ssh.Channel, r
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if _, err := io.Copy(conn, ch); err != nil {
+		if _, err := io.Copy(conn, throttled); err != nil {
 			scx.Warningf("failed proxying data for port forwarding connection: %v", err)
 		}
 		conn.Close()
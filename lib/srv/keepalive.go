@@ -25,9 +25,25 @@ import (
 
 	"github.com/gravitational/trace"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	sessionLatencies = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    teleport.MetricSessionLatency,
+			Help:    "Round-trip latency of timing probes sent over session connections",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 15),
+		},
+		[]string{"cluster", "server_id"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(sessionLatencies)
+}
+
 // RequestSender is an interface that implements SendRequest. It is used so
 // server and client connections can be passed to functions to send requests.
 type RequestSender interface {
@@ -53,6 +69,16 @@ type KeepAliveParams struct {
 
 	// CloseCancel is used by the keep-alive loop to notify the server to stop.
 	CloseCancel context.CancelFunc
+
+	// ClusterName is the name of the cluster the connections belong to. When
+	// set along with ServerID, keep-alive requests double as timing probes:
+	// they travel over the same connection as the interactive traffic, so
+	// their round-trip time approximates keystroke echo latency and is
+	// recorded in a Prometheus histogram under these labels.
+	ClusterName string
+
+	// ServerID is the UUID of the server the connections belong to.
+	ServerID string
 }
 
 // StartKeepAliveLoop starts the keep-alive loop.
@@ -75,9 +101,15 @@ func StartKeepAliveLoop(p KeepAliveParams) {
 			// Send a keep alive message on all connections and make sure a response
 			// was received on all.
 			for _, conn := range p.Conns {
+				start := time.Now()
 				ok := sendKeepAliveWithTimeout(p.CloseContext, conn, defaults.ReadHeadersTimeout)
 				if ok {
 					sentCount++
+
+					// Record the round-trip time of the probe as session latency.
+					if p.ClusterName != "" && p.ServerID != "" {
+						sessionLatencies.WithLabelValues(p.ClusterName, p.ServerID).Observe(time.Since(start).Seconds())
+					}
 				}
 			}
 			if sentCount == len(p.Conns) {
@@ -20,9 +20,14 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/backend"
 	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/services"
 	"golang.org/x/crypto/ssh"
 
 	"github.com/gravitational/trace"
@@ -76,6 +81,17 @@ type MonitorConfig struct {
 	Emitter events.Emitter
 	// Entry is a logging entry
 	Entry *log.Entry
+	// AccessPoint is used to watch for role and user changes. If nil,
+	// access is not re-evaluated for the lifetime of the connection.
+	AccessPoint auth.AccessPoint
+	// RecheckAccess re-evaluates whether the connection is still allowed
+	// by the user's current roles. It is called whenever a role or the
+	// user resource changes.
+	RecheckAccess func() error
+	// DisconnectOnRoleChange closes the connection when RecheckAccess
+	// fails. When unset, a failed re-evaluation only logs a warning and
+	// changes apply at next login.
+	DisconnectOnRoleChange bool
 }
 
 // CheckAndSetDefaults checks values and sets defaults
@@ -83,8 +99,8 @@ func (m *MonitorConfig) CheckAndSetDefaults() error {
 	if m.Context == nil {
 		return trace.BadParameter("missing parameter Context")
 	}
-	if m.DisconnectExpiredCert.IsZero() && m.ClientIdleTimeout == 0 {
-		return trace.BadParameter("either DisconnectExpiredCert or ClientIdleTimeout should be set")
+	if m.DisconnectExpiredCert.IsZero() && m.ClientIdleTimeout == 0 && m.RecheckAccess == nil {
+		return trace.BadParameter("either DisconnectExpiredCert, ClientIdleTimeout or RecheckAccess should be set")
 	}
 	if m.Conn == nil {
 		return trace.BadParameter("missing parameter Conn")
@@ -138,6 +154,28 @@ func (w *Monitor) Start() {
 		idleTime = idleTimer.C
 	}
 
+	// Watch for role and user changes so the connection can be re-evaluated
+	// against the user's current roles instead of waiting for next login.
+	var accessEvents <-chan services.Event
+	// Not every access point supports watching for events, fall back to
+	// applying changes at next login when this one does not.
+	accessWatch, capable := w.AccessPoint.(services.Events)
+	if w.RecheckAccess != nil && capable {
+		watcher, err := accessWatch.NewWatcher(w.Context, services.Watch{
+			Name: teleport.ComponentSession,
+			Kinds: []services.WatchKind{
+				{Kind: services.KindRole},
+				{Kind: services.KindUser, Name: w.TeleportUser},
+			},
+		})
+		if err != nil {
+			w.Entry.WithError(err).Warn("Failed to watch for role changes, access will not be re-evaluated.")
+		} else {
+			defer watcher.Close()
+			accessEvents = watcher.Events()
+		}
+	}
+
 	for {
 		select {
 		// certificate has expired, disconnect
@@ -203,6 +241,48 @@ func (w *Monitor) Start() {
 			w.Entry.Debugf("Next check in %v", w.ClientIdleTimeout-now.Sub(clientLastActive))
 			idleTimer = time.NewTimer(w.ClientIdleTimeout - now.Sub(clientLastActive))
 			idleTime = idleTimer.C
+		// a role or the user has changed, re-evaluate access
+		case event, ok := <-accessEvents:
+			if !ok {
+				w.Entry.Debug("Role change watcher has closed, access will no longer be re-evaluated.")
+				accessEvents = nil
+				continue
+			}
+			if event.Type != backend.OpPut && event.Type != backend.OpDelete {
+				continue
+			}
+			err := w.RecheckAccess()
+			if err == nil {
+				continue
+			}
+			if !w.DisconnectOnRoleChange {
+				w.Entry.Warnf("Access revoked by role change, connection will remain open until next login: %v.", err)
+				continue
+			}
+			disconnectEvent := &events.ClientDisconnect{
+				Metadata: events.Metadata{
+					Type: events.ClientDisconnectEvent,
+					Code: events.ClientDisconnectCode,
+				},
+				UserMetadata: events.UserMetadata{
+					Login: w.Login,
+					User:  w.TeleportUser,
+				},
+				ConnectionMetadata: events.ConnectionMetadata{
+					LocalAddr:  w.Conn.LocalAddr().String(),
+					RemoteAddr: w.Conn.RemoteAddr().String(),
+				},
+				ServerMetadata: events.ServerMetadata{
+					ServerID: w.ServerID,
+				},
+				Reason: fmt.Sprintf("access revoked by role change: %v", err),
+			}
+			if err := w.Emitter.EmitAuditEvent(w.Context, disconnectEvent); err != nil {
+				w.Entry.WithError(err).Warn("Failed to emit audit event.")
+			}
+			w.Entry.Debugf("Disconnecting client: %v", disconnectEvent.Reason)
+			w.Conn.Close()
+			return
 		case <-w.Context.Done():
 			w.Entry.Debugf("Releasing associated resources - context has been closed.")
 			return
@@ -213,23 +293,37 @@ func (w *Monitor) Start() {
 type trackingChannel struct {
 	ssh.Channel
 	t ActivityTracker
+
+	// txBytes keeps track of how many bytes were written to the channel.
+	txBytes uint64
+
+	// rxBytes keeps track of how many bytes were read from the channel.
+	rxBytes uint64
 }
 
-func newTrackingChannel(ch ssh.Channel, t ActivityTracker) ssh.Channel {
-	return trackingChannel{
+func newTrackingChannel(ch ssh.Channel, t ActivityTracker) *trackingChannel {
+	return &trackingChannel{
 		Channel: ch,
 		t:       t,
 	}
 }
 
-func (ch trackingChannel) Read(buf []byte) (int, error) {
+// Stat returns the transmitted (TX) and received (RX) bytes over the channel,
+// from the perspective of the server. Implements utils.Stater.
+func (ch *trackingChannel) Stat() (uint64, uint64) {
+	return atomic.LoadUint64(&ch.txBytes), atomic.LoadUint64(&ch.rxBytes)
+}
+
+func (ch *trackingChannel) Read(buf []byte) (int, error) {
 	n, err := ch.Channel.Read(buf)
+	atomic.AddUint64(&ch.rxBytes, uint64(n))
 	ch.t.UpdateClientActivity()
 	return n, err
 }
 
-func (ch trackingChannel) Write(buf []byte) (int, error) {
+func (ch *trackingChannel) Write(buf []byte) (int, error) {
 	n, err := ch.Channel.Write(buf)
+	atomic.AddUint64(&ch.txBytes, uint64(n))
 	ch.t.UpdateClientActivity()
 	return n, err
 }
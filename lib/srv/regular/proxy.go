@@ -133,6 +133,7 @@ func parseProxySubsys(request string, srv *Server, ctx *srv.ServerContext) (*pro
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	srv.rewriteHost(&req)
 	subsys, err := newProxySubsys(ctx, srv, req)
 	if err != nil {
 		return nil, trace.Wrap(err)
@@ -146,6 +147,9 @@ type proxySubsysRequest struct {
 	host        string
 	port        string
 	clusterName string
+	// labels, when set by a host template, select the target node by
+	// labels instead of hostname.
+	labels map[string]string
 }
 
 func (p *proxySubsysRequest) String() string {
@@ -330,10 +334,28 @@ func (t *proxySubsys) proxyToHost(
 	// by node ID over node hostname.
 	hostIsUUID := uuid.Parse(t.host) != nil
 
+	// a host template translated the requested hostname into a label search,
+	// build the label selector used to match nodes below
+	var selector services.Labels
+	if len(t.labels) != 0 {
+		selector = make(services.Labels, len(t.labels))
+		for key, value := range t.labels {
+			selector[key] = utils.Strings{value}
+		}
+	}
+
 	// enumerate and try to find a server with self-registered with a matching name/IP:
 	var server services.Server
 	matches := 0
 	for i := range servers {
+		// match by labels only when a host template requested it
+		if selector != nil {
+			if ok, _, _ := services.MatchLabels(selector, servers[i].GetAllLabels()); ok {
+				server = servers[i]
+				matches++
+			}
+			continue
+		}
 		// If the host parameter is a UUID and it matches the Node ID,
 		// treat this as an unambiguous match.
 		if hostIsUUID && servers[i].GetName() == t.host {
@@ -381,6 +403,17 @@ func (t *proxySubsys) proxyToHost(
 		return trace.NotFound("unable to locate node matching uuid-like target %s", t.host)
 	}
 
+	// label searches have no hostname to fall back to, a registered node has
+	// to match
+	if selector != nil {
+		if matches < 1 {
+			return trace.NotFound("unable to locate node matching labels %v", t.labels)
+		}
+		if t.host == "" {
+			t.host = server.GetHostname()
+		}
+	}
+
 	// Create a slice of principals that will be added into the host certificate.
 	// Here t.host is either an IP address or a DNS name as the user requested.
 	principals := []string{t.host}
@@ -17,6 +17,8 @@ limitations under the License.
 package regular
 
 import (
+	"regexp"
+
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/srv"
 
@@ -86,6 +88,44 @@ func (s *ProxyTestSuite) TestParseProxyRequest(c *check.C) {
 	}
 }
 
+func (s *ProxyTestSuite) TestHostTemplates(c *check.C) {
+	srv := &Server{
+		hostTemplates: []HostTemplate{
+			{
+				Template: regexp.MustCompile(`^(\w+)\.(\w+)$`),
+				Host:     "$1",
+				Cluster:  "$2",
+			},
+			{
+				Template: regexp.MustCompile(`^db-(\w+)$`),
+				Labels:   map[string]string{"db": "$1"},
+			},
+		},
+	}
+
+	// the hostname matches the first template, host and cluster are rewritten
+	req, err := parseProxySubsysRequest("proxy:node1.moon:22")
+	c.Assert(err, check.IsNil)
+	srv.rewriteHost(&req)
+	c.Assert(req.host, check.Equals, "node1")
+	c.Assert(req.clusterName, check.Equals, "moon")
+
+	// the hostname matches the second template, the node is found by labels
+	req, err = parseProxySubsysRequest("proxy:db-main:22")
+	c.Assert(err, check.IsNil)
+	srv.rewriteHost(&req)
+	c.Assert(req.host, check.Equals, "")
+	c.Assert(req.labels, check.DeepEquals, map[string]string{"db": "main"})
+
+	// no template matches, the request is left intact
+	req, err = parseProxySubsysRequest("proxy:plainhost:22@moon")
+	c.Assert(err, check.IsNil)
+	srv.rewriteHost(&req)
+	c.Assert(req.host, check.Equals, "plainhost")
+	c.Assert(req.clusterName, check.Equals, "moon")
+	c.Assert(req.labels, check.IsNil)
+}
+
 func (s *ProxyTestSuite) TestParseBadRequests(c *check.C) {
 	ctx := &srv.ServerContext{}
 
@@ -112,6 +112,14 @@ type Server struct {
 	// before creating a new session.
 	permitUserEnvironment bool
 
+	// disconnectOnRoleChange controls if connections are terminated when
+	// the user's roles no longer allow access to this server.
+	disconnectOnRoleChange bool
+
+	// hostTemplates rewrite requested hostnames into a node search when
+	// this server runs in proxy mode.
+	hostTemplates []HostTemplate
+
 	// ciphers is a list of ciphers that the server supports. If omitted,
 	// the defaults will be used.
 	ciphers []string
@@ -402,6 +410,24 @@ func SetPermitUserEnvironment(permitUserEnvironment bool) ServerOption {
 	}
 }
 
+// SetDisconnectOnRoleChange sets if connections are terminated when the
+// user's roles no longer allow access to this server.
+func SetDisconnectOnRoleChange(disconnect bool) ServerOption {
+	return func(s *Server) error {
+		s.disconnectOnRoleChange = disconnect
+		return nil
+	}
+}
+
+// SetHostTemplates sets the host templates used to rewrite requested
+// hostnames when this server runs in proxy mode.
+func SetHostTemplates(templates []HostTemplate) ServerOption {
+	return func(s *Server) error {
+		s.hostTemplates = templates
+		return nil
+	}
+}
+
 func SetCiphers(ciphers []string) ServerOption {
 	return func(s *Server) error {
 		s.ciphers = ciphers
@@ -644,6 +670,12 @@ func (s *Server) PermitUserEnvironment() bool {
 	return s.permitUserEnvironment
 }
 
+// DisconnectOnRoleChange returns if connections are terminated when the
+// user's roles no longer allow access to this server.
+func (s *Server) DisconnectOnRoleChange() bool {
+	return s.disconnectOnRoleChange
+}
+
 func (s *Server) setAdvertiseAddr(addr *utils.NetAddr) {
 	s.Lock()
 	defer s.Unlock()
@@ -1124,14 +1156,16 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ccx *sshutils.Con
 	}
 
 	// Start copy routines that copy from channel to stdin pipe and from stdout
-	// pipe to channel.
+	// pipe to channel. If the roles of the user cap transfer throughput, wrap
+	// the channel in a token bucket so bulk copies can not saturate the uplink.
+	throttled := utils.NewThrottledReadWriter(channel, scx.Identity.RoleSet.TransferRateLimit())
 	errorCh := make(chan error, 2)
 	go func() {
 		defer channel.Close()
 		defer pw.Close()
 		defer pr.Close()
 
-		_, err := io.Copy(pw, channel)
+		_, err := io.Copy(pw, throttled)
 		errorCh <- err
 	}()
 	go func() {
@@ -1139,7 +1173,7 @@ func (s *Server) handleDirectTCPIPRequest(ctx context.Context, ccx *sshutils.Con
 		defer pw.Close()
 		defer pr.Close()
 
-		_, err := io.Copy(channel, pr)
+		_, err := io.Copy(throttled, pr)
 		errorCh <- err
 	}()
 
@@ -1212,9 +1246,17 @@ func (s *Server) handleSessionRequests(ctx context.Context, ccx *sshutils.Connec
 		return
 	}
 
+	// Try and fetch the name of this cluster. If the name can not be fetched,
+	// the keep-alive loop still runs, round-trip latency is just not recorded.
+	var clusterName string
+	if cn, err := s.GetAccessPoint().GetClusterName(); err == nil {
+		clusterName = cn.GetClusterName()
+	}
+
 	// The keep-alive loop will keep pinging the remote server and after it has
 	// missed a certain number of keep-alive requests it will cancel the
-	// closeContext which signals the server to shutdown.
+	// closeContext which signals the server to shutdown. Successful round
+	// trips double as timing probes and are recorded as session latency.
 	go srv.StartKeepAliveLoop(srv.KeepAliveParams{
 		Conns: []srv.RequestSender{
 			scx.ServerConn,
@@ -1223,6 +1265,8 @@ func (s *Server) handleSessionRequests(ctx context.Context, ccx *sshutils.Connec
 		MaxCount:     clusterConfig.GetKeepAliveCountMax(),
 		CloseContext: ctx,
 		CloseCancel:  scx.CancelFunc(),
+		ClusterName:  clusterName,
+		ServerID:     s.uuid,
 	})
 
 	for {
@@ -1539,9 +1583,17 @@ func (s *Server) handleProxyJump(ctx context.Context, ccx *sshutils.ConnectionCo
 		}
 	}
 
+	// Try and fetch the name of this cluster. If the name can not be fetched,
+	// the keep-alive loop still runs, round-trip latency is just not recorded.
+	var clusterName string
+	if cn, err := s.GetAccessPoint().GetClusterName(); err == nil {
+		clusterName = cn.GetClusterName()
+	}
+
 	// The keep-alive loop will keep pinging the remote server and after it has
 	// missed a certain number of keep-alive requests it will cancel the
-	// closeContext which signals the server to shutdown.
+	// closeContext which signals the server to shutdown. Successful round
+	// trips double as timing probes and are recorded as session latency.
 	go srv.StartKeepAliveLoop(srv.KeepAliveParams{
 		Conns: []srv.RequestSender{
 			scx.ServerConn,
@@ -1550,6 +1602,8 @@ func (s *Server) handleProxyJump(ctx context.Context, ccx *sshutils.ConnectionCo
 		MaxCount:     clusterConfig.GetKeepAliveCountMax(),
 		CloseContext: ctx,
 		CloseCancel:  scx.CancelFunc(),
+		ClusterName:  clusterName,
+		ServerID:     s.uuid,
 	})
 
 	subsys, err := newProxySubsys(scx, s, proxySubsysRequest{
@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package regular
+
+import (
+	"regexp"
+)
+
+// HostTemplate rewrites hostnames requested through the proxy into a node
+// search before routing, so existing ssh_config hostname conventions (like
+// "db1.us-east") keep working when hosts move behind Teleport.
+type HostTemplate struct {
+	// Template is a regular expression matched against the hostname the
+	// client requested.
+	Template *regexp.Regexp
+
+	// Host is the hostname to look up instead. Capture group references
+	// like $1 are expanded from Template.
+	Host string
+
+	// Cluster is the cluster to route to. Capture group references are
+	// expanded from Template.
+	Cluster string
+
+	// Labels are labels the target node has to match instead of matching
+	// by hostname. Label values support capture group references.
+	Labels map[string]string
+}
+
+// rewriteHost applies the first matching host template (if any) to the
+// requested host, rewriting the target host, cluster or label search.
+func (s *Server) rewriteHost(req *proxySubsysRequest) {
+	for _, tpl := range s.hostTemplates {
+		match := tpl.Template.FindStringSubmatchIndex(req.host)
+		if match == nil {
+			continue
+		}
+		originalHost := req.host
+		expand := func(replacement string) string {
+			return string(tpl.Template.ExpandString(nil, replacement, originalHost, match))
+		}
+		if len(tpl.Labels) != 0 {
+			// The node is found by labels, not by the requested name.
+			req.labels = make(map[string]string, len(tpl.Labels))
+			for key, value := range tpl.Labels {
+				req.labels[key] = expand(value)
+			}
+			req.host = ""
+		}
+		if tpl.Host != "" {
+			req.host = expand(tpl.Host)
+		}
+		if tpl.Cluster != "" {
+			req.clusterName = expand(tpl.Cluster)
+		}
+		log.Debugf("Host template %q rewrote host %q to %v.", tpl.Template, originalHost, req)
+		return
+	}
+}
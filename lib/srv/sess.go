@@ -722,6 +722,11 @@ func (s *session) startInteractive(ch ssh.Channel, ctx *ServerContext) error {
 		Login:     ctx.Identity.Login,
 		User:      ctx.Identity.TeleportUser,
 		Events:    ctx.Identity.RoleSet.EnhancedRecordingSet(),
+		Summary:   bpf.NewSummary(),
+
+		BlockedWritePaths: ctx.Identity.RoleSet.BlockedWritePaths(),
+		MemoryLimit:       ctx.Identity.RoleSet.SessionMemoryLimit(),
+		CPULimit:          ctx.Identity.RoleSet.SessionCPULimit(),
 	}
 	cgroupID, err := ctx.srv.GetBPF().OpenSession(sessionContext)
 	if err != nil {
@@ -821,6 +826,9 @@ func (s *session) startInteractive(ch ssh.Channel, ctx *ServerContext) error {
 		if err != nil {
 			ctx.Errorf("Failed to close enhanced recording (interactive) session: %v: %v.", s.id, err)
 		}
+		if !sessionContext.Summary.Empty() {
+			ctx.Infof("Session %v summary: %v.", s.id, sessionContext.Summary)
+		}
 
 		if result != nil {
 			if err := s.registry.broadcastResult(s.id, *result); err != nil {
@@ -930,6 +938,11 @@ func (s *session) startExec(channel ssh.Channel, ctx *ServerContext) error {
 		Login:     ctx.Identity.Login,
 		User:      ctx.Identity.TeleportUser,
 		Events:    ctx.Identity.RoleSet.EnhancedRecordingSet(),
+		Summary:   bpf.NewSummary(),
+
+		BlockedWritePaths: ctx.Identity.RoleSet.BlockedWritePaths(),
+		MemoryLimit:       ctx.Identity.RoleSet.SessionMemoryLimit(),
+		CPULimit:          ctx.Identity.RoleSet.SessionCPULimit(),
 	}
 	cgroupID, err := ctx.srv.GetBPF().OpenSession(sessionContext)
 	if err != nil {
@@ -962,6 +975,9 @@ func (s *session) startExec(channel ssh.Channel, ctx *ServerContext) error {
 		if err != nil {
 			ctx.Errorf("Failed to close enhanced recording (exec) session: %v: %v.", s.id, err)
 		}
+		if !sessionContext.Summary.Empty() {
+			ctx.Infof("Session %v summary: %v.", s.id, sessionContext.Summary)
+		}
 
 		// Remove the session from the in-memory map.
 		s.registry.removeSession(s)
@@ -1170,6 +1186,17 @@ func (s *session) heartbeat(ctx *ServerContext) {
 				Parties:   &partyList,
 			})
 			if err != nil {
+				// If the session record is gone, the session was forcibly
+				// terminated (e.g. "tctl sessions kill"): notify the
+				// parties and tear the session down.
+				if trace.IsNotFound(err) {
+					s.log.Infof("Session %v was terminated, closing.", s.id)
+					s.writer.Write([]byte("\r\nThis session has been terminated by a cluster administrator.\r\n"))
+					if err := s.Close(); err != nil {
+						s.log.Warnf("Failed to close terminated session %v: %v.", s.id, err)
+					}
+					return
+				}
 				s.log.Warnf("Unable to update session %v as active: %v", s.id, err)
 			}
 		case <-s.closeC:
@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/gravitational/teleport/lib/events"
 	"github.com/gravitational/teleport/lib/httplib"
@@ -156,6 +157,12 @@ func (l *httpFileSystem) SetChmod(path string, mode int) error {
 	return nil
 }
 
+// Chtimes sets file access and modification times. It does nothing as
+// file times make no sense while processing HTTP downloads
+func (l *httpFileSystem) Chtimes(path string, atime, mtime time.Time) error {
+	return nil
+}
+
 // MkDir creates a directory. This method is not implemented as creating directories
 // is not supported during HTTP downloads.
 func (l *httpFileSystem) MkDir(path string, mode int) error {
@@ -243,6 +250,12 @@ func (l *httpFileInfo) GetModePerm() os.FileMode {
 	return httpUploadFileMode
 }
 
+// GetModTime returns the time of the transfer as HTTP file
+// transfers do not carry the original modification time
+func (l *httpFileInfo) GetModTime() time.Time {
+	return time.Now()
+}
+
 type nopWriteCloser struct {
 	io.Writer
 }
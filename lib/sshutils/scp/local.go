@@ -20,6 +20,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/gravitational/teleport/lib/utils"
 	"github.com/gravitational/trace"
@@ -40,6 +41,15 @@ func (l *localFileSystem) SetChmod(path string, mode int) error {
 	return nil
 }
 
+// Chtimes sets file access and modification times
+func (l *localFileSystem) Chtimes(path string, atime, mtime time.Time) error {
+	if err := os.Chtimes(path, atime, mtime); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+
+	return nil
+}
+
 // MkDir creates a directory
 func (l *localFileSystem) MkDir(path string, mode int) error {
 	fileMode := os.FileMode(mode & int(os.ModePerm))
@@ -152,3 +162,8 @@ func (l *localFileInfo) ReadDir() ([]FileInfo, error) {
 func (l *localFileInfo) GetModePerm() os.FileMode {
 	return l.fileInfo.Mode() & os.ModePerm
 }
+
+// GetModTime returns file modification time
+func (l *localFileInfo) GetModTime() time.Time {
+	return l.fileInfo.ModTime()
+}
@@ -62,6 +62,12 @@ type Flags struct {
 	LocalAddr string
 	// DirectoryMode indicates that a directory is being sent.
 	DirectoryMode bool
+	// PreserveAttrs preserves modification and access times
+	// of transferred files
+	PreserveAttrs bool
+	// LimitKbits limits the used bandwidth, in Kbit/s,
+	// zero means no limit
+	LimitKbits int64
 }
 
 // Config describes Command configuration settings
@@ -107,6 +113,8 @@ type FileSystem interface {
 	CreateFile(filePath string, length uint64) (io.WriteCloser, error)
 	// SetChmod sets file permissions
 	SetChmod(path string, mode int) error
+	// Chtimes sets file access and modification times
+	Chtimes(path string, atime, mtime time.Time) error
 }
 
 // FileInfo is an API that describes methods that provide file information
@@ -121,6 +129,8 @@ type FileInfo interface {
 	GetPath() string
 	// GetModePerm returns file permissions
 	GetModePerm() os.FileMode
+	// GetModTime returns file modification time
+	GetModTime() time.Time
 	// GetSize returns file size
 	GetSize() int64
 }
@@ -200,6 +210,11 @@ type command struct {
 // Execute implements SSH file copy (SCP). It is called on both tsh (client)
 // and teleport (server) side.
 func (cmd *command) Execute(ch io.ReadWriter) (err error) {
+	// the client end throttles the transfer on both uploads and
+	// downloads, the server side stays oblivious to the limit
+	if cmd.Flags.LimitKbits > 0 && !cmd.RunOnServer {
+		ch = utils.NewThrottledReadWriter(ch, cmd.Flags.LimitKbits)
+	}
 	if cmd.Flags.Source {
 		err = cmd.serveSource(ch)
 	} else {
@@ -228,6 +243,9 @@ func (cmd *command) GetRemoteShellCmd() (string, error) {
 	if cmd.Flags.DirectoryMode {
 		shellCmd += " -d"
 	}
+	if cmd.Flags.PreserveAttrs {
+		shellCmd += " -p"
+	}
 	shellCmd += (" " + cmd.RemoteLocation)
 
 	return shellCmd, nil
@@ -280,7 +298,24 @@ func (cmd *command) serveSource(ch io.ReadWriter) (retErr error) {
 	return nil
 }
 
+// sendMtime sends the 'T' command communicating modification and access
+// times of the file or directory that follows
+func (cmd *command) sendMtime(r *reader, ch io.ReadWriter, fileInfo FileInfo) error {
+	mtime := fileInfo.GetModTime().Unix()
+	out := fmt.Sprintf("T%d 0 %d 0\n", mtime, mtime)
+	cmd.log.Debugf("sendMtime: %v", out)
+	if _, err := io.WriteString(ch, out); err != nil {
+		return trace.Wrap(err)
+	}
+	return trace.Wrap(r.read())
+}
+
 func (cmd *command) sendDir(r *reader, ch io.ReadWriter, fileInfo FileInfo) error {
+	if cmd.Flags.PreserveAttrs {
+		if err := cmd.sendMtime(r, ch, fileInfo); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 	out := fmt.Sprintf("D%04o 0 %s\n", fileInfo.GetModePerm(), fileInfo.GetName())
 	cmd.log.Debugf("sendDir: %v", out)
 	_, err := io.WriteString(ch, out)
@@ -319,6 +354,11 @@ func (cmd *command) sendDir(r *reader, ch io.ReadWriter, fileInfo FileInfo) erro
 }
 
 func (cmd *command) sendFile(r *reader, ch io.ReadWriter, fileInfo FileInfo) error {
+	if cmd.Flags.PreserveAttrs {
+		if err := cmd.sendMtime(r, ch, fileInfo); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 	reader, err := cmd.FileSystem.OpenFile(fileInfo.GetPath())
 	if err != nil {
 		return trace.Wrap(err)
@@ -449,10 +489,14 @@ func (cmd *command) processCommand(ch io.ReadWriter, st *state, b byte, line str
 	case 'E':
 		return st.pop()
 	case 'T':
-		_, err := parseMtime(line)
+		mtime, err := parseMtime(line)
 		if err != nil {
 			return trace.Wrap(err)
 		}
+		// remember the times, they apply to the file or directory
+		// that follows
+		st.stat = mtime
+		return nil
 	}
 	return trace.Errorf("got unrecognized command: %v", string(b))
 }
@@ -499,11 +543,22 @@ func (cmd *command) receiveFile(st *state, fc newFileCmd, ch io.ReadWriter) erro
 		return trace.Wrap(err)
 	}
 
+	if stat := st.stat; stat != nil {
+		st.stat = nil
+		if err := cmd.FileSystem.Chtimes(path, stat.Atime, stat.Mtime); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
 	cmd.log.Debugf("file %v(%v) copied to %v", fc.Name, fc.Length, path)
 	return nil
 }
 
 func (cmd *command) receiveDir(st *state, fc newFileCmd, ch io.ReadWriter) error {
+	// directory times can not be preserved reliably: writing the
+	// directory contents updates the modification time again
+	st.stat = nil
+
 	targetDir := cmd.Flags.Target[0]
 
 	// copying into an existing directory? append to it:
@@ -587,6 +642,9 @@ func sendOK(ch io.ReadWriter) error {
 type state struct {
 	path     []string
 	finished bool
+	// stat carries the times sent by the last 'T' command, they apply
+	// to the next file or directory command
+	stat *mtimeCmd
 }
 
 func (st *state) push(dir string) {
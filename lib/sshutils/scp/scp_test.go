@@ -176,6 +176,59 @@ func (s *SCPSuite) TestReceiveFile(c *C) {
 	c.Assert(string(bytes), Equals, string(contents))
 }
 
+func (s *SCPSuite) TestPreserveAttrs(c *C) {
+	dir := c.MkDir()
+	source := filepath.Join(dir, "target")
+
+	contents := []byte("hello, mtime!")
+	err := ioutil.WriteFile(source, contents, 0666)
+	c.Assert(err, IsNil)
+
+	// scp transfers second-granularity timestamps
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	c.Assert(os.Chtimes(source, mtime, mtime), IsNil)
+
+	// sending: the source announces the times and the system scp
+	// applies them
+	cmd, err := CreateCommand(Config{
+		User: "test-user",
+		Flags: Flags{
+			Source:        true,
+			Target:        []string{source},
+			PreserveAttrs: true,
+		},
+	})
+	c.Assert(err, IsNil)
+
+	outDir := c.MkDir()
+	err = runSCP(cmd, "scp", "-v", "-p", "-t", outDir)
+	c.Assert(err, IsNil)
+
+	fi, err := os.Stat(filepath.Join(outDir, "target"))
+	c.Assert(err, IsNil)
+	c.Assert(fi.ModTime().Equal(mtime), Equals, true)
+
+	// receiving: the system scp announces the times and the sink
+	// applies them
+	outDir = c.MkDir() + "/"
+	cmd, err = CreateCommand(Config{
+		User: "test-user",
+		Flags: Flags{
+			Sink:          true,
+			Target:        []string{outDir},
+			PreserveAttrs: true,
+		},
+	})
+	c.Assert(err, IsNil)
+
+	err = runSCP(cmd, "scp", "-v", "-p", "-f", source)
+	c.Assert(err, IsNil)
+
+	fi, err = os.Stat(filepath.Join(outDir, "target"))
+	c.Assert(err, IsNil)
+	c.Assert(fi.ModTime().Equal(mtime), Equals, true)
+}
+
 func (s *SCPSuite) TestSendDir(c *C) {
 	dir := filepath.Join(c.MkDir(), "target_dir")
 
@@ -32,24 +32,33 @@ func CalculateSPKI(cert *x509.Certificate) string {
 	return "sha256:" + hex.EncodeToString(sum[:])
 }
 
-// CheckSPKI the passed in pin against the calculated value from a certificate.
-func CheckSPKI(pin string, cert *x509.Certificate) error {
-	// Check that the format of the pin is valid.
-	parts := strings.Split(pin, ":")
-	if len(parts) != 2 {
-		return trace.BadParameter("invalid format for certificate pin, expected algorithm:pin")
+// CheckSPKI checks the passed in pins against the calculated value from
+// a certificate. The check passes if any pin matches, so both the current
+// and the next pin can be listed during CA rotation.
+func CheckSPKI(pins []string, cert *x509.Certificate) error {
+	if len(pins) == 0 {
+		return trace.BadParameter("no certificate pins provided")
 	}
-	if parts[0] != "sha256" {
-		return trace.BadParameter("sha256 only supported hashing algorithm for certificate pin")
+
+	// Check that the format of each pin is valid.
+	for _, pin := range pins {
+		parts := strings.Split(pin, ":")
+		if len(parts) != 2 {
+			return trace.BadParameter("invalid format for certificate pin, expected algorithm:pin")
+		}
+		if parts[0] != "sha256" {
+			return trace.BadParameter("sha256 only supported hashing algorithm for certificate pin")
+		}
 	}
 
-	// Check that that pin itself matches that value calculated from the passed
+	// Check that a pin matches the value calculated from the passed
 	// in certificate.
-	if subtle.ConstantTimeCompare([]byte(CalculateSPKI(cert)), []byte(pin)) != 1 {
-		return trace.BadParameter(errorMessage)
+	for _, pin := range pins {
+		if subtle.ConstantTimeCompare([]byte(CalculateSPKI(cert)), []byte(pin)) == 1 {
+			return nil
+		}
 	}
-
-	return nil
+	return trace.BadParameter(errorMessage)
 }
 
 var errorMessage string = "provided certificate pin does not match cluster pin. " +
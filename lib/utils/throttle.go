@@ -0,0 +1,86 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// NewThrottledReadWriter wraps rw so that the combined read and write
+// throughput stays below the limit given in Kbit/s. A limit of zero
+// returns rw unchanged.
+func NewThrottledReadWriter(rw io.ReadWriter, limitKbits int64) io.ReadWriter {
+	if limitKbits == 0 {
+		return rw
+	}
+	bytesPerSecond := limitKbits * 1024 / 8
+	return &throttledReadWriter{
+		rw: rw,
+		// allow a burst of one second worth of traffic so that small
+		// protocol messages are never delayed
+		limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), int(bytesPerSecond)),
+	}
+}
+
+type throttledReadWriter struct {
+	rw      io.ReadWriter
+	limiter *rate.Limiter
+}
+
+// Read reads from the wrapped stream and charges the consumed bytes
+// against the limit
+func (t *throttledReadWriter) Read(p []byte) (int, error) {
+	if burst := t.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := t.rw.Read(p)
+	if n > 0 {
+		if werr := t.wait(n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
+
+// Write writes to the wrapped stream in limit-sized chunks, waiting for
+// the limiter between chunks
+func (t *throttledReadWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p
+		if burst := t.limiter.Burst(); len(chunk) > burst {
+			chunk = chunk[:burst]
+		}
+		if err := t.wait(len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := t.rw.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (t *throttledReadWriter) wait(n int) error {
+	return t.limiter.WaitN(context.Background(), n)
+}
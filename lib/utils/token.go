@@ -18,15 +18,26 @@ package utils
 
 import (
 	"io/ioutil"
+	"os"
 	"strings"
 
 	"github.com/gravitational/trace"
 )
 
 // ReadToken is a utility function to read the token
-// from the disk if it looks like a path,
-// otherwise, treat it as a value
+// from the disk if it looks like a path, from the environment
+// if it has the "env:" prefix, otherwise, treat it as a value
 func ReadToken(token string) (string, error) {
+	if strings.HasPrefix(token, "env:") {
+		// pull it out of the environment, so the value never has to be
+		// written to a configuration file
+		name := strings.TrimPrefix(token, "env:")
+		out, ok := os.LookupEnv(name)
+		if !ok {
+			return "", trace.BadParameter("environment variable %q is not set", name)
+		}
+		return strings.TrimSpace(out), nil
+	}
 	if !strings.HasPrefix(token, "/") {
 		return token, nil
 	}
@@ -112,6 +112,15 @@ type Config struct {
 	// FIPS mode means Teleport started in a FedRAMP/FIPS 140-2 compliant
 	// configuration.
 	FIPS bool
+
+	// MinClientVersion is the cluster-configured minimum client version
+	// advertised to clients, empty means the compiled-in minimum.
+	MinClientVersion string
+
+	// ClientCertAuth allows API calls to authenticate with a client TLS
+	// certificate issued by this cluster's user certificate authority
+	// instead of a session cookie.
+	ClientCertAuth bool
 }
 
 type RewritingHandler struct {
@@ -167,6 +176,11 @@ func NewHandler(cfg Config, opts ...HandlerOption) (*RewritingHandler, error) {
 	h.PUT("/webapi/users/password", h.WithAuth(h.changePassword))
 	h.POST("/webapi/users/password/token", h.WithAuth(h.createResetPasswordToken))
 
+	// Account recovery with one-time recovery codes. Starting recovery is
+	// authenticated by the code itself, regenerating codes requires a session.
+	h.POST("/webapi/recovery/start", httplib.MakeHandler(h.startAccountRecovery))
+	h.POST("/webapi/recovery/codes", h.WithAuth(h.generateRecoveryCodes))
+
 	// Issues SSH temp certificates based on 2FA access creds
 	h.POST("/webapi/ssh/certs", httplib.MakeHandler(h.createSSHCert))
 
@@ -181,6 +195,9 @@ func NewHandler(cfg Config, opts ...HandlerOption) (*RewritingHandler, error) {
 	// get nodes
 	h.GET("/webapi/sites/:site/namespaces/:namespace/nodes", h.WithClusterAuth(h.siteNodesGet))
 
+	// unified resource listing for the landing page
+	h.GET("/webapi/sites/:site/resources", h.WithClusterAuth(h.siteResourcesGet))
+
 	// active sessions handlers
 	h.GET("/webapi/sites/:site/namespaces/:namespace/connect", h.WithClusterAuth(h.siteNodeConnect))       // connect to an active session (via websocket)
 	h.GET("/webapi/sites/:site/namespaces/:namespace/sessions", h.WithClusterAuth(h.siteSessionsGet))      // get active list of sessions
@@ -228,6 +245,10 @@ func NewHandler(cfg Config, opts ...HandlerOption) (*RewritingHandler, error) {
 	// User Status (used by client to check if user session is valid)
 	h.GET("/webapi/user/status", h.WithAuth(h.getUserStatus))
 
+	// Per-user UI preferences (theme, default cluster, pinned resources)
+	h.GET("/webapi/user/preferences", h.WithAuth(h.getUserPreferences))
+	h.PUT("/webapi/user/preferences", h.WithAuth(h.updateUserPreferences))
+
 	// Issue host credentials.
 	h.POST("/webapi/host/credentials", httplib.MakeHandler(h.hostCredentials))
 
@@ -347,6 +368,41 @@ func (h *Handler) getUserStatus(w http.ResponseWriter, r *http.Request, _ httpro
 	return ok(), nil
 }
 
+// getUserPreferences returns the UI preferences of the logged in user
+//
+// GET /webapi/user/preferences
+//
+func (h *Handler) getUserPreferences(w http.ResponseWriter, r *http.Request, _ httprouter.Params, c *SessionContext) (interface{}, error) {
+	clt, err := c.GetClient()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	prefs, err := clt.GetUserPreferences(c.GetUser())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return prefs, nil
+}
+
+// updateUserPreferences updates the UI preferences of the logged in user
+//
+// PUT /webapi/user/preferences
+//
+func (h *Handler) updateUserPreferences(w http.ResponseWriter, r *http.Request, _ httprouter.Params, c *SessionContext) (interface{}, error) {
+	var prefs services.UserPreferences
+	if err := httplib.ReadJSON(r, &prefs); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	clt, err := c.GetClient()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if err := clt.UpsertUserPreferences(c.GetUser(), prefs); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return ok(), nil
+}
+
 // getUserContext returns user context
 //
 // GET /webapi/user/context
@@ -531,15 +587,24 @@ func (h *Handler) ping(w http.ResponseWriter, r *http.Request, p httprouter.Para
 		Auth:             defaultSettings,
 		Proxy:            h.cfg.ProxySettings,
 		ServerVersion:    teleport.Version,
-		MinClientVersion: teleport.MinClientVersion,
+		MinClientVersion: h.minClientVersion(),
 	}, nil
 }
 
+// minClientVersion returns the minimum client version advertised to clients,
+// the cluster-configured one when set, the compiled-in default otherwise.
+func (h *Handler) minClientVersion() string {
+	if h.cfg.MinClientVersion != "" {
+		return h.cfg.MinClientVersion
+	}
+	return teleport.MinClientVersion
+}
+
 func (h *Handler) find(w http.ResponseWriter, r *http.Request, p httprouter.Params) (interface{}, error) {
 	return client.PingResponse{
 		Proxy:            h.cfg.ProxySettings,
 		ServerVersion:    teleport.Version,
-		MinClientVersion: teleport.MinClientVersion,
+		MinClientVersion: h.minClientVersion(),
 	}, nil
 }
 
@@ -1187,6 +1252,55 @@ func (h *Handler) changePasswordWithToken(w http.ResponseWriter, r *http.Request
 	return NewSessionResponse(ctx)
 }
 
+type startAccountRecoveryReq struct {
+	// User is the name of the user regaining access.
+	User string `json:"user"`
+	// RecoveryCode is one of the user's one-time recovery codes.
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// startAccountRecovery spends a recovery code and returns a reset token so
+// the user can set a new password and re-register a second factor.
+//
+// POST /webapi/recovery/start
+//
+func (h *Handler) startAccountRecovery(w http.ResponseWriter, r *http.Request, p httprouter.Params) (interface{}, error) {
+	var req startAccountRecoveryReq
+	if err := httplib.ReadJSON(r, &req); err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	token, err := h.auth.proxyClient.UseRecoveryCode(r.Context(), req.User, req.RecoveryCode)
+	if err != nil {
+		log.Warnf("Failed account recovery attempt: %v.", err)
+		// We hide the error from the remote user to avoid giving any hints.
+		return nil, trace.AccessDenied("invalid username or recovery code")
+	}
+
+	return ui.ResetPasswordToken{
+		TokenID: token.GetName(),
+		Expiry:  token.Expiry(),
+		User:    token.GetUser(),
+	}, nil
+}
+
+// generateRecoveryCodes issues a fresh set of one-time recovery codes for
+// the logged in user, invalidating any previously issued set.
+//
+// POST /webapi/recovery/codes
+//
+func (h *Handler) generateRecoveryCodes(w http.ResponseWriter, r *http.Request, _ httprouter.Params, c *SessionContext) (interface{}, error) {
+	clt, err := c.GetClient()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	codes, err := clt.GenerateRecoveryCodes(r.Context(), c.GetUser())
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return codes, nil
+}
+
 // createResetPasswordToken allows a UI user to reset a user's password.
 // This handler is also required for after creating new users.
 func (h *Handler) createResetPasswordToken(w http.ResponseWriter, r *http.Request, _ httprouter.Params, ctx *SessionContext) (interface{}, error) {
@@ -1392,6 +1506,29 @@ func (h *Handler) siteNodesGet(w http.ResponseWriter, r *http.Request, p httprou
 	return makeResponse(uiServers)
 }
 
+// siteResourcesGet returns one page of the unified resource listing for the
+// site: nodes plus kubernetes clusters, with labels and health
+//
+// GET /v1/webapi/sites/:site/resources?startKey=<key>&limit=<limit>
+func (h *Handler) siteResourcesGet(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *SessionContext, site reversetunnel.RemoteSite) (interface{}, error) {
+	clt, err := ctx.GetUserClient(site)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, trace.BadParameter("invalid limit %q", v)
+		}
+	}
+	page, err := clt.ListUnifiedResources(r.URL.Query().Get("startKey"), limit)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return page, nil
+}
+
 // siteNodeConnect connect to the site node
 //
 // GET /v1/webapi/sites/:site/namespaces/:namespace/connect?access_token=bearer_token&params=<urlencoded json-structure>
@@ -1658,7 +1795,11 @@ func (h *Handler) clusterSearchSessionEvents(w http.ResponseWriter, r *http.Requ
 //   "to"     : date range to, encoded as RFC3339
 //   "include": optional semicolon-separated list of event names to return e.g.
 //              include=session.start;session.end, all are returned if empty
+//   "user"   : optional teleport user name to filter events by
+//   "search" : optional free-text filter, matches events containing the text
 //   "limit"  : optional maximum number of events to return
+//   "offset" : optional number of matching events to skip, used together with
+//              limit to paginate through large result sets
 //
 func (h *Handler) clusterSearchEvents(w http.ResponseWriter, r *http.Request, p httprouter.Params, ctx *SessionContext, site reversetunnel.RemoteSite) (interface{}, error) {
 	values := r.URL.Query()
@@ -1674,18 +1815,38 @@ func (h *Handler) clusterSearchEvents(w http.ResponseWriter, r *http.Request, p
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	offset, err := queryLimit(values, "offset", 0)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	if offset < 0 {
+		return nil, trace.BadParameter("offset can not be negative")
+	}
 	query := url.Values{}
 	if include := values.Get("include"); include != "" {
 		query[events.EventType] = strings.Split(include, ";")
 	}
+	if user := values.Get("user"); user != "" {
+		query.Set(events.EventUser, user)
+	}
+	if search := values.Get("search"); search != "" {
+		query.Set(events.EventSearch, search)
+	}
 	clt, err := ctx.GetUserClient(site)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
-	fields, err := clt.SearchEvents(from, to, query.Encode(), limit)
+	// The audit log does not support skipping matches, fetch the whole page
+	// including the skipped events and cut the offset off here.
+	fields, err := clt.SearchEvents(from, to, query.Encode(), limit+offset)
 	if err != nil {
 		return nil, trace.Wrap(err)
 	}
+	if offset >= len(fields) {
+		fields = nil
+	} else {
+		fields = fields[offset:]
+	}
 	return eventsListGetResponse{Events: fields}, nil
 }
 
@@ -2065,6 +2226,18 @@ func (h *Handler) AuthenticateRequest(w http.ResponseWriter, r *http.Request, ch
 	logger := log.WithFields(log.Fields{
 		"request": fmt.Sprintf("%v %v", r.Method, r.URL.Path),
 	})
+	// If the proxy is configured to accept client certificates and the TLS
+	// layer verified one against the user certificate authority, authenticate
+	// the request with the certificate identity instead of a session cookie.
+	// Certificate-authenticated clients do not carry bearer tokens.
+	if h.cfg.ClientCertAuth && r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		ctx, err := h.auth.validateCertSession(r.TLS.PeerCertificates[0])
+		if err != nil {
+			logger.Warningf("Failed to authenticate client certificate: %v.", err)
+			return nil, trace.AccessDenied("need auth")
+		}
+		return ctx, nil
+	}
 	cookie, err := r.Cookie("session")
 	if err != nil || (cookie != nil && cookie.Value == "") {
 		if err != nil {
@@ -18,6 +18,7 @@ package web
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"io"
@@ -329,6 +330,7 @@ func newSessionCache(proxyClient auth.ClientI, servers []utils.NetAddr, cipherSu
 		authServers:  servers,
 		closer:       utils.NewCloseBroadcaster(),
 		cipherSuites: cipherSuites,
+		certSessions: make(map[[sha256.Size]byte]certSession),
 	}
 	// periodically close expired and unused sessions
 	go cache.expireSessions()
@@ -347,6 +349,19 @@ type sessionCache struct {
 
 	// cipherSuites is the list of supported TLS cipher suites.
 	cipherSuites []uint16
+
+	// certSessions maps fingerprints of client certificates to the IDs of
+	// web sessions minted for them, so repeated API calls with the same
+	// certificate reuse one session.
+	certSessions map[[sha256.Size]byte]certSession
+}
+
+// certSession is a certSessions cache entry: the ID of the web session
+// minted for a client certificate and the certificate's expiry time,
+// after which the entry is swept from the cache.
+type certSession struct {
+	sessionID string
+	expires   time.Time
 }
 
 // Close closes all allocated resources and stops goroutines
@@ -392,6 +407,14 @@ func (s *sessionCache) clearExpiredSessions() {
 	if expired != 0 {
 		log.Infof("[WEB] removed %v expired sessions", expired)
 	}
+	// certificates are reissued on every login, so sweep cache entries
+	// for certificates that have expired and will never be seen again
+	now := time.Now()
+	for fingerprint, session := range s.certSessions {
+		if now.After(session.expires) {
+			delete(s.certSessions, fingerprint)
+		}
+	}
 }
 
 func (s *sessionCache) AuthWithOTP(user, pass string, otpToken string) (services.WebSession, error) {
@@ -608,6 +631,49 @@ func (s *sessionCache) ValidateSession(user, sid string) (*SessionContext, error
 	return out, nil
 }
 
+// validateCertSession returns a session context for a request authenticated
+// with a client TLS certificate. A web session is minted for the certificate
+// identity on first use and reused by subsequent requests presenting the
+// same certificate.
+func (s *sessionCache) validateCertSession(cert *x509.Certificate) (*SessionContext, error) {
+	identity, err := tlsca.FromSubject(cert.Subject, cert.NotAfter)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	s.Lock()
+	session, ok := s.certSessions[fingerprint]
+	s.Unlock()
+	if ok {
+		ctx, err := s.ValidateSession(identity.Username, session.sessionID)
+		if err == nil {
+			return ctx, nil
+		}
+		// The cached session has expired, evict it and mint a new one below.
+		s.Lock()
+		delete(s.certSessions, fingerprint)
+		s.Unlock()
+	}
+
+	sess, err := s.proxyClient.AuthenticateWebUser(auth.AuthenticateUserRequest{
+		Username: identity.Username,
+		ClientCert: &auth.ClientCertCreds{
+			Cert: cert.Raw,
+		},
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	s.Lock()
+	s.certSessions[fingerprint] = certSession{
+		sessionID: sess.GetName(),
+		expires:   cert.NotAfter,
+	}
+	s.Unlock()
+	return s.ValidateSession(identity.Username, sess.GetName())
+}
+
 func (s *sessionCache) SetSession(w http.ResponseWriter, user, sid string) error {
 	d, err := EncodeCookie(user, sid)
 	if err != nil {
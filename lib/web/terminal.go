@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -125,6 +126,9 @@ func NewTerminal(req TerminalRequest, authProvider AuthProvider, ctx *SessionCon
 		authProvider: authProvider,
 		encoder:      unicode.UTF8.NewEncoder(),
 		decoder:      unicode.UTF8.NewDecoder(),
+		// channel is buffered, a pending resize is coalesced with the
+		// most recent geometry in queueWindowChange
+		windowChangeC: make(chan *session.TerminalParams, 1),
 	}, nil
 }
 
@@ -152,9 +156,17 @@ type TerminalHandler struct {
 	// hostUUID is the UUID of the server.
 	hostUUID string
 
+	// mu protects sshSession which is set once the shell has been
+	// created and is accessed from multiple goroutines.
+	mu sync.Mutex
+
 	// sshSession holds the "shell" SSH channel to the node.
 	sshSession *ssh.Session
 
+	// windowChangeC carries window resize requests from the websocket
+	// read loop to the resize loop, so they are applied in order.
+	windowChangeC chan *session.TerminalParams
+
 	// terminalContext is used to signal when the terminal sesson is closing.
 	terminalContext context.Context
 
@@ -203,8 +215,11 @@ func (t *TerminalHandler) Close() error {
 	}
 
 	// Close the SSH connection to the remote node.
-	if t.sshSession != nil {
-		t.sshSession.Close()
+	t.mu.Lock()
+	sshSession := t.sshSession
+	t.mu.Unlock()
+	if sshSession != nil {
+		sshSession.Close()
 	}
 
 	// If the terminal handler was closed (most likely due to the *SessionContext
@@ -239,6 +254,9 @@ func (t *TerminalHandler) handler(ws *websocket.Conn) {
 	// Start sending ping frames through websocket to client.
 	go t.startPingLoop(ws)
 
+	// Apply window change requests in the order they were received.
+	go t.startWindowChangeLoop()
+
 	// Pump raw terminal in/out and audit events into the websocket.
 	go t.streamTerminal(ws, tc)
 	go t.streamEvents(ws, tc)
@@ -290,7 +308,9 @@ func (t *TerminalHandler) makeClient(ws *websocket.Conn) (*client.TeleportClient
 	// used to update all other parties window size to that of the web client and
 	// to allow future window changes.
 	tc.OnShellCreated = func(s *ssh.Session, c *ssh.Client, _ io.ReadWriteCloser) (bool, error) {
+		t.mu.Lock()
 		t.sshSession = s
+		t.mu.Unlock()
 		t.windowChange(&t.params.Term)
 
 		return false, nil
@@ -362,8 +382,11 @@ func (t *TerminalHandler) streamTerminal(ws *websocket.Conn, tc *client.Teleport
 	}
 
 	// Check if remote process exited with error code, eg: RemoteCommandFailure (255).
-	if t.sshSession != nil {
-		if err := t.sshSession.Wait(); err != nil {
+	t.mu.Lock()
+	sshSession := t.sshSession
+	t.mu.Unlock()
+	if sshSession != nil {
+		if err := sshSession.Wait(); err != nil {
 			if exitErr, ok := err.(*ssh.ExitError); ok {
 				t.log.Warnf("Remote shell exited with error code: %v", exitErr.ExitStatus())
 				return
@@ -436,14 +459,48 @@ func (t *TerminalHandler) streamEvents(ws *websocket.Conn, tc *client.TeleportCl
 	}
 }
 
+// startWindowChangeLoop applies queued window change requests to the SSH
+// session one at a time. Applying them from a single goroutine keeps the
+// requests in the order the browser sent them.
+func (t *TerminalHandler) startWindowChangeLoop() {
+	for {
+		select {
+		case params := <-t.windowChangeC:
+			t.windowChange(params)
+		case <-t.terminalContext.Done():
+			return
+		}
+	}
+}
+
+// queueWindowChange queues a window change request without blocking the
+// websocket read loop. If the previous request has not been applied yet it
+// is replaced with the most recent geometry, only the latest size matters.
+func (t *TerminalHandler) queueWindowChange(params *session.TerminalParams) {
+	for {
+		select {
+		case t.windowChangeC <- params:
+			return
+		default:
+		}
+		select {
+		case <-t.windowChangeC:
+		default:
+		}
+	}
+}
+
 // windowChange is called when the browser window is resized. It sends a
 // "window-change" channel request to the server.
 func (t *TerminalHandler) windowChange(params *session.TerminalParams) {
-	if t.sshSession == nil {
+	t.mu.Lock()
+	sshSession := t.sshSession
+	t.mu.Unlock()
+	if sshSession == nil {
 		return
 	}
 
-	_, err := t.sshSession.SendRequest(
+	_, err := sshSession.SendRequest(
 		sshutils.WindowChangeRequest,
 		false,
 		ssh.Marshal(sshutils.WinChangeReqParams{
@@ -584,9 +641,9 @@ func (t *TerminalHandler) read(out []byte, ws *websocket.Conn) (n int, err error
 			return 0, trace.Wrap(err)
 		}
 
-		// Send the window change request in a goroutine so reads are not blocked
-		// by network connectivity issues.
-		go t.windowChange(params)
+		// Queue the window change request so reads are not blocked by
+		// network connectivity issues and resizes are applied in order.
+		t.queueWindowChange(params)
 
 		return 0, nil
 	default:
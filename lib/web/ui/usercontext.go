@@ -77,7 +77,7 @@ func getLogins(roleSet services.RoleSet) []string {
 	denied = utils.Deduplicate(denied)
 	userLogins := []string{}
 	for _, login := range allowed {
-		loginMatch, _ := services.MatchLogin(denied, login)
+		loginMatch, _ := services.MatchDeniedLogin(denied, login)
 		if !loginMatch {
 			userLogins = append(userLogins, login)
 		}
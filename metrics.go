@@ -43,8 +43,28 @@ const (
 	// MetricTrustedClusters counts trusted clusters
 	MetricTrustedClusters = "trusted_clusters"
 
+	// MetricRegisteredNodes measures nodes currently registered
+	// (heartbeating) in the cluster
+	MetricRegisteredNodes = "registered_nodes"
+
+	// MetricReverseTunnels measures reverse tunnel connections per tunnel type
+	MetricReverseTunnels = "reverse_tunnels_connected"
+
+	// MetricRemoteClusterLastHeartbeat reports the time of the last
+	// heartbeat received from a remote cluster
+	MetricRemoteClusterLastHeartbeat = "remote_cluster_last_heartbeat_timestamp_seconds"
+
+	// MetricAuditEmittedEvents counts successfully emitted audit events
+	MetricAuditEmittedEvents = "audit_emitted_events"
+
+	// MetricAuditFailedEmitEvents counts audit events that failed to emit
+	MetricAuditFailedEmitEvents = "audit_failed_emit_events"
+
 	// TagCluster is a metric tag for a cluster
 	TagCluster = "cluster"
+
+	// TagType is a metric tag for a type of the measured object
+	TagType = "type"
 )
 
 const (
@@ -135,9 +155,34 @@ const (
 	// MetricLostNetworkEvents measures the number of network events that were lost.
 	MetricLostNetworkEvents = "bpf_lost_network_events"
 
+	// MetricLostLibraryEvents measures the number of library load events that were lost.
+	MetricLostLibraryEvents = "bpf_lost_library_events"
+
+	// MetricSessionLatency measures round-trip latency of timing probes sent
+	// over session connections.
+	MetricSessionLatency = "session_latency_seconds"
+
 	// MetricState tracks the state of the teleport process.
 	MetricState = "process_state"
 
+	// MetricCacheHealth reports whether a component's cache is up to date
+	// (1) or degraded and possibly serving stale data (0)
+	MetricCacheHealth = "cache_health"
+
+	// MetricGRPCServerRequests counts requests served by the auth gRPC API
+	MetricGRPCServerRequests = "grpc_server_requests_total"
+
+	// MetricGRPCServerRequestLatency measures latency of the auth gRPC API
+	// requests
+	MetricGRPCServerRequestLatency = "grpc_server_request_seconds"
+
+	// MetricGRPCServerStreamDuration measures how long streams served by
+	// the auth gRPC API stay open
+	MetricGRPCServerStreamDuration = "grpc_server_stream_duration_seconds"
+
+	// TagMethod is a metric tag for an API method
+	TagMethod = "method"
+
 	// TagRange is a tag specifying backend requests
 	TagRange = "range"
 
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/teleport/lib/asciitable"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/service"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+)
+
+// AccessCommand implements the `tctl access` family of commands, it explains
+// why a user does or does not have access to a resource by evaluating the
+// user's role set against it, role by role.
+type AccessCommand struct {
+	config *service.Config
+
+	user     string
+	resource string
+	login    string
+
+	accessList *kingpin.CmdClause
+}
+
+// Initialize allows AccessCommand to plug itself into the CLI parser
+func (c *AccessCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	c.config = config
+	access := app.Command("access", "Explain access to resources")
+
+	c.accessList = access.Command("ls", "Explain which roles grant or deny a user access to a resource").Alias("explain")
+	c.accessList.Flag("user", "Name of the user to evaluate").Required().StringVar(&c.user)
+	c.accessList.Flag("resource", "Resource to evaluate, e.g. node/web1").Required().StringVar(&c.resource)
+	c.accessList.Flag("login", "Limit the evaluation to a single login").StringVar(&c.login)
+}
+
+// TryRun takes the CLI command as an argument (like "access ls") and executes it.
+func (c *AccessCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case c.accessList.FullCommand():
+		err = c.Explain(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// Explain evaluates the user's role set against the resource and prints a
+// per-role breakdown of the label matchers and logins that grant or deny
+// access, followed by the overall verdict per login.
+func (c *AccessCommand) Explain(client auth.ClientI) error {
+	parts := strings.SplitN(c.resource, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return trace.BadParameter("resource must be in the form kind/name, e.g. node/web1")
+	}
+	if parts[0] != services.KindNode {
+		return trace.BadParameter("unsupported resource kind %q, only %q is supported", parts[0], services.KindNode)
+	}
+	node, err := findNode(client, parts[1])
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	user, err := client.GetUser(c.user, false)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	roles, err := services.FetchRoles(user.GetRoles(), client, user.GetTraits())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	fmt.Printf("User %v, roles %v\nResource %v/%v, labels %v\n\n",
+		user.GetName(), strings.Join(user.GetRoles(), ","), services.KindNode, node.GetHostname(), node.LabelsString())
+
+	// Per-role breakdown: which matchers fire for this node.
+	t := asciitable.MakeTable([]string{"Role", "Effect", "Namespace", "Node Labels", "Logins"})
+	for _, role := range roles {
+		for _, condition := range []struct {
+			effect string
+			cond   services.RoleConditionType
+		}{
+			{"allow", services.Allow},
+			{"deny", services.Deny},
+		} {
+			labels := role.GetNodeLabels(condition.cond)
+			logins := role.GetLogins(condition.cond)
+			namespaces := role.GetNamespaces(condition.cond)
+			if len(labels) == 0 && len(logins) == 0 {
+				continue
+			}
+			matchNamespace, _ := services.MatchNamespace(namespaces, node.GetNamespace())
+			matchLabels, _, err := services.MatchLabels(labels, node.GetAllLabels())
+			if err != nil {
+				return trace.Wrap(err)
+			}
+			t.AddRow([]string{
+				role.GetName(),
+				condition.effect,
+				matchMark(matchNamespace, strings.Join(namespaces, ",")),
+				matchMark(matchLabels, labelSelectorString(labels)),
+				strings.Join(logins, ","),
+			})
+		}
+	}
+	fmt.Println(t.AsBuffer().String())
+
+	// Overall verdict, per login.
+	logins := []string{c.login}
+	if c.login == "" {
+		logins, err = roles.CheckLoginDuration(0)
+		if err != nil {
+			fmt.Printf("Verdict: DENIED for all logins: %v\n", err)
+			return nil
+		}
+	}
+	for _, login := range logins {
+		if err := roles.CheckAccessToServer(login, node); err != nil {
+			fmt.Printf("Verdict: login %q DENIED: %v\n", login, err)
+		} else {
+			fmt.Printf("Verdict: login %q ALLOWED\n", login)
+		}
+	}
+	return nil
+}
+
+// matchMark annotates a matcher value with whether it matched the resource.
+func matchMark(matched bool, value string) string {
+	if value == "" {
+		value = "(none)"
+	}
+	if matched {
+		return value + " [match]"
+	}
+	return value
+}
+
+// labelSelectorString renders a role label selector as "key=value" pairs.
+func labelSelectorString(labels services.Labels) string {
+	var pairs []string
+	for key, values := range labels {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", key, strings.Join(values, "|")))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// findNode resolves a node by hostname or host UUID.
+func findNode(client auth.ClientI, name string) (services.Server, error) {
+	nodes, err := client.GetNodes(defaults.Namespace)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, node := range nodes {
+		if node.GetHostname() == name || node.GetName() == name {
+			return node, nil
+		}
+	}
+	return nil, trace.NotFound("node %q is not registered with this cluster", name)
+}
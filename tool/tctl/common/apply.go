@@ -0,0 +1,250 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/ghodss/yaml"
+	"github.com/gravitational/trace"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Apply implements 'tctl apply -f <file-or-directory>'. It loads every
+// resource definition found under the given path, reports what would change
+// compared to the live cluster and, unless --dry-run was passed, upserts
+// them all. All files are parsed up front so a malformed definition aborts
+// the run before anything has been applied.
+func (rc *ResourceCommand) Apply(client auth.ClientI) error {
+	files, err := collectResourceFiles(rc.filename)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(files) == 0 {
+		return trace.BadParameter("no resource files found in %v", rc.filename)
+	}
+
+	// parse everything before touching the cluster
+	var raws []services.UnknownResource
+	for _, file := range files {
+		parsed, err := parseResourceFile(file)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		raws = append(raws, parsed...)
+	}
+	for _, raw := range raws {
+		if _, found := rc.CreateHandlers[ResourceKind(raw.Kind)]; !found {
+			return trace.BadParameter("applying resources of type %q is not supported", raw.Kind)
+		}
+		if raw.GetName() == "" {
+			return trace.BadParameter("resource of type %q is missing a name", raw.Kind)
+		}
+	}
+
+	// apply is always an upsert:
+	rc.force = true
+
+	var created, updated, unchanged int
+	for _, raw := range raws {
+		rc.ref = services.Ref{Kind: raw.Kind, Name: raw.GetName()}
+
+		liveYAML, err := rc.liveResourceYAML(client)
+		if err != nil && !trace.IsNotFound(err) {
+			return trace.Wrap(err)
+		}
+		exists := (err == nil)
+
+		desiredYAML, err := yaml.JSONToYAML(raw.Raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+
+		switch {
+		case !exists:
+			created++
+			fmt.Printf("%v %q will be created\n", raw.Kind, raw.GetName())
+			if rc.diff {
+				printDiff(os.Stdout, nil, desiredYAML)
+			}
+		case bytes.Equal(liveYAML, desiredYAML):
+			unchanged++
+			fmt.Printf("%v %q is unchanged\n", raw.Kind, raw.GetName())
+		default:
+			updated++
+			fmt.Printf("%v %q will be updated\n", raw.Kind, raw.GetName())
+			if rc.diff {
+				printDiff(os.Stdout, liveYAML, desiredYAML)
+			}
+		}
+
+		if rc.dryRun {
+			continue
+		}
+		creator := rc.CreateHandlers[ResourceKind(raw.Kind)]
+		if err := creator(client, raw); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+
+	verb := "applied"
+	if rc.dryRun {
+		verb = "planned (dry run)"
+	}
+	fmt.Printf("%v resources %v: %v to create, %v to update, %v unchanged\n",
+		len(raws), verb, created, updated, unchanged)
+	return nil
+}
+
+// liveResourceYAML fetches the resource addressed by rc.ref from the
+// cluster and returns its YAML representation
+func (rc *ResourceCommand) liveResourceYAML(client auth.ClientI) ([]byte, error) {
+	resource, err := rc.getResource(client)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var buf bytes.Buffer
+	if err := utils.WriteYAML(&buf, resource); err != nil {
+		return nil, trace.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// collectResourceFiles returns the resource definition files under the
+// given path: the path itself if it's a regular file, or all YAML/JSON
+// files in it (sorted, non-recursive) if it's a directory
+func collectResourceFiles(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, trace.ConvertSystemError(err)
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	var files []string
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		if info.IsDir() {
+			// non-recursive: only files directly under the given
+			// directory are collected
+			if p != path {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// parseResourceFile decodes all YAML/JSON documents in the given file
+func parseResourceFile(filename string) ([]services.UnknownResource, error) {
+	reader, err := utils.OpenFile(filename)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	defer reader.Close()
+	decoder := kyaml.NewYAMLOrJSONDecoder(reader, defaults.LookaheadBufSize)
+	var resources []services.UnknownResource
+	for {
+		var raw services.UnknownResource
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, trace.Wrap(err, "failed to parse %v", filename)
+		}
+		resources = append(resources, raw)
+	}
+	if len(resources) == 0 {
+		return nil, trace.BadParameter("no resources found in %v, empty input?", filename)
+	}
+	return resources, nil
+}
+
+// printDiff writes a simple line diff between the two YAML documents:
+// removed lines are prefixed with "-", added lines with "+"
+func printDiff(w io.Writer, before []byte, after []byte) {
+	a := splitLines(before)
+	b := splitLines(after)
+	// longest common subsequence over document lines, resources are small
+	// enough for the quadratic table to not matter
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			fmt.Fprintf(w, "  %v\n", a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			fmt.Fprintf(w, "- %v\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(w, "+ %v\n", b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		fmt.Fprintf(w, "- %v\n", a[i])
+	}
+	for ; j < len(b); j++ {
+		fmt.Fprintf(w, "+ %v\n", b[j])
+	}
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+}
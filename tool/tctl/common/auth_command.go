@@ -23,6 +23,7 @@ import (
 
 	"github.com/gravitational/kingpin"
 	"github.com/gravitational/trace"
+	"golang.org/x/crypto/ssh"
 )
 
 // AuthCommand implements `tctl auth` group of commands
@@ -48,10 +49,15 @@ type AuthCommand struct {
 	rotateManualMode  bool
 	rotateTargetPhase string
 
+	importName string
+	importType string
+	importPath string
+
 	authGenerate *kingpin.CmdClause
 	authExport   *kingpin.CmdClause
 	authSign     *kingpin.CmdClause
 	authRotate   *kingpin.CmdClause
+	authImport   *kingpin.CmdClause
 }
 
 // Initialize allows TokenCommand to plug itself into the CLI parser
@@ -91,6 +97,11 @@ func (a *AuthCommand) Initialize(app *kingpin.Application, config *service.Confi
 	a.authRotate.Flag("manual", "Activate manual rotation , set rotation phases manually").BoolVar(&a.rotateManualMode)
 	a.authRotate.Flag("type", "Certificate authority to rotate, rotates both host and user CA by default").StringVar(&a.rotateType)
 	a.authRotate.Flag("phase", fmt.Sprintf("Target rotation phase to set, used in manual rotation, one of: %v", strings.Join(services.RotatePhases, ", "))).StringVar(&a.rotateTargetPhase)
+
+	a.authImport = auth.Command("import", "Import an external SSH certificate authority so hosts it has signed are trusted")
+	a.authImport.Arg("path", "path to the CA public key file in authorized_keys format").Required().StringVar(&a.importPath)
+	a.authImport.Flag("name", "name to register the imported authority under").Required().StringVar(&a.importName)
+	a.authImport.Flag("type", "certificate authority type, only 'host' is supported").Default(string(services.HostCA)).StringVar(&a.importType)
 }
 
 // TryRun takes the CLI command as an argument (like "auth gen") and executes it
@@ -105,6 +116,8 @@ func (a *AuthCommand) TryRun(cmd string, client auth.ClientI) (match bool, err e
 		err = a.GenerateAndSignKeys(client)
 	case a.authRotate.FullCommand():
 		err = a.RotateCertAuthority(client)
+	case a.authImport.FullCommand():
+		err = a.ImportAuthority(client)
 	default:
 		return false, nil
 	}
@@ -289,6 +302,59 @@ func (a *AuthCommand) RotateCertAuthority(client auth.ClientI) error {
 	return nil
 }
 
+// ImportAuthority reads an external SSH CA public key in authorized_keys
+// format and registers it as a host certificate authority, so proxies
+// trust hosts whose certificates were issued by an existing corporate CA.
+func (a *AuthCommand) ImportAuthority(client auth.ClientI) error {
+	if services.CertAuthType(a.importType) != services.HostCA {
+		return trace.BadParameter("only host authorities can be imported, got type %q", a.importType)
+	}
+
+	data, err := ioutil.ReadFile(a.importPath)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	var checkingKeys [][]byte
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return trace.BadParameter("failed to parse public key in %v: %v", a.importPath, err)
+		}
+		checkingKeys = append(checkingKeys, ssh.MarshalAuthorizedKey(key))
+	}
+	if len(checkingKeys) == 0 {
+		return trace.BadParameter("no public keys found in %v", a.importPath)
+	}
+
+	// refuse to overwrite the CA of this cluster or a previously imported one
+	localAuthName, err := client.GetDomainName()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if a.importName == localAuthName {
+		return trace.BadParameter("%q is the name of this cluster's own authority, pick a different name", a.importName)
+	}
+	_, err = client.GetCertAuthority(services.CertAuthID{Type: services.HostCA, DomainName: a.importName}, false)
+	if err == nil {
+		return trace.AlreadyExists("authority %q already exists, remove it first with 'tctl rm cert_authority/host/%v'", a.importName, a.importName)
+	}
+	if !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+
+	ca := services.NewCertAuthority(services.HostCA, a.importName, nil, checkingKeys, nil, services.CertAuthoritySpecV2_UNKNOWN)
+	if err := client.UpsertCertAuthority(ca); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("Imported %v public key(s) as host authority %q. Hosts presenting certificates signed by it are now trusted.\n",
+		len(checkingKeys), a.importName)
+	return nil
+}
+
 func (a *AuthCommand) generateHostKeys(clusterAPI auth.ClientI) error {
 	// only format=openssh is supported
 	if a.outputFormat != identityfile.FormatOpenSSH {
@@ -0,0 +1,258 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/service"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// BackupCommand implements the `tctl backup` set of commands, dumping
+// dynamic cluster resources into an encrypted archive and restoring them
+// into a new cluster.
+type BackupCommand struct {
+	config      *service.Config
+	file        string
+	key         string
+	withSecrets bool
+
+	backupCreate  *kingpin.CmdClause
+	backupRestore *kingpin.CmdClause
+}
+
+// Initialize allows BackupCommand to plug itself into the CLI parser
+func (b *BackupCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	b.config = config
+	backup := app.Command("backup", "Backup and restore dynamic cluster resources")
+
+	b.backupCreate = backup.Command("create", "Dump users, roles, certificate authorities and other dynamic resources into an encrypted archive")
+	b.backupCreate.Flag("out", "Path to write the backup archive to").Required().StringVar(&b.file)
+	b.backupCreate.Flag("with-secrets", "Include certificate authority private keys and user secrets").BoolVar(&b.withSecrets)
+
+	b.backupRestore = backup.Command("restore", "Restore dynamic resources from a backup archive into this cluster")
+	b.backupRestore.Arg("archive", "Path to the backup archive").Required().StringVar(&b.file)
+	b.backupRestore.Flag("key", "Hex encoded encryption key printed when the backup was created").Required().StringVar(&b.key)
+}
+
+// TryRun takes the CLI command as an argument (like "backup create") and executes it.
+func (b *BackupCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case b.backupCreate.FullCommand():
+		err = b.Create(client)
+	case b.backupRestore.FullCommand():
+		err = b.Restore(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// backupKeySize is the size of the random archive encryption key.
+const backupKeySize = 32
+
+// backupNonceSize is the size of the secretbox nonce prepended to the
+// encrypted archive.
+const backupNonceSize = 24
+
+// Create dumps the dynamic cluster resources into an encrypted archive and
+// prints the generated encryption key, which is required to restore.
+func (b *BackupCommand) Create(client auth.ClientI) error {
+	resources, err := b.collectResources(client)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	var plain bytes.Buffer
+	zw := gzip.NewWriter(&plain)
+	for _, resource := range resources {
+		data, err := services.MarshalResource(resource, services.PreserveResourceID())
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if _, err := zw.Write(append(data, '\n')); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return trace.Wrap(err)
+	}
+
+	var key [backupKeySize]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return trace.Wrap(err)
+	}
+	var nonce [backupNonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return trace.Wrap(err)
+	}
+	sealed := secretbox.Seal(nonce[:], plain.Bytes(), &nonce, &key)
+
+	if err := ioutil.WriteFile(b.file, sealed, 0600); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+
+	fmt.Printf("Backed up %v resources to %v.\n", len(resources), b.file)
+	fmt.Printf("Store the following encryption key in a safe place, it is required to restore:\n%v\n",
+		hex.EncodeToString(key[:]))
+	if !b.withSecrets {
+		fmt.Println("NOTE: certificate authority private keys were not included, pass --with-secrets to include them.")
+	}
+	return nil
+}
+
+// collectResources fetches all dynamic resources to back up.
+func (b *BackupCommand) collectResources(client auth.ClientI) ([]services.Resource, error) {
+	var resources []services.Resource
+
+	for _, caType := range []services.CertAuthType{services.HostCA, services.UserCA} {
+		authorities, err := client.GetCertAuthorities(caType, b.withSecrets)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		for _, ca := range authorities {
+			resources = append(resources, ca)
+		}
+	}
+
+	roles, err := client.GetRoles()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, role := range roles {
+		resources = append(resources, role)
+	}
+
+	users, err := client.GetUsers(b.withSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, user := range users {
+		resources = append(resources, user)
+	}
+
+	trustedClusters, err := client.GetTrustedClusters()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, tc := range trustedClusters {
+		resources = append(resources, tc)
+	}
+
+	connectors, err := client.GetGithubConnectors(b.withSecrets)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, connector := range connectors {
+		resources = append(resources, connector)
+	}
+
+	return resources, nil
+}
+
+// Restore decrypts the archive and upserts every resource found in it.
+func (b *BackupCommand) Restore(client auth.ClientI) error {
+	key, err := hex.DecodeString(b.key)
+	if err != nil || len(key) != backupKeySize {
+		return trace.BadParameter("invalid encryption key, expected %v hex encoded bytes", backupKeySize)
+	}
+	var keyBytes [backupKeySize]byte
+	copy(keyBytes[:], key)
+
+	sealed, err := ioutil.ReadFile(b.file)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if len(sealed) < backupNonceSize {
+		return trace.BadParameter("archive %v is corrupted", b.file)
+	}
+	var nonce [backupNonceSize]byte
+	copy(nonce[:], sealed[:backupNonceSize])
+	plain, ok := secretbox.Open(nil, sealed[backupNonceSize:], &nonce, &keyBytes)
+	if !ok {
+		return trace.AccessDenied("failed to decrypt archive %v, check the encryption key", b.file)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer zr.Close()
+
+	decoder := json.NewDecoder(zr)
+	var restored int
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return trace.Wrap(err)
+		}
+		var header struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return trace.Wrap(err)
+		}
+		resource, err := services.UnmarshalResource(header.Kind, raw)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := b.restoreResource(client, resource); err != nil {
+			return trace.Wrap(err)
+		}
+		restored++
+	}
+
+	fmt.Printf("Restored %v resources from %v.\n", restored, b.file)
+	return nil
+}
+
+// restoreResource upserts a single resource by kind.
+func (b *BackupCommand) restoreResource(client auth.ClientI, resource services.Resource) error {
+	switch rsc := resource.(type) {
+	case services.CertAuthority:
+		return trace.Wrap(client.UpsertCertAuthority(rsc))
+	case services.Role:
+		return trace.Wrap(client.UpsertRole(context.TODO(), rsc))
+	case services.User:
+		return trace.Wrap(client.UpsertUser(rsc))
+	case services.TrustedCluster:
+		_, err := client.UpsertTrustedCluster(context.TODO(), rsc)
+		return trace.Wrap(err)
+	case services.GithubConnector:
+		return trace.Wrap(client.UpsertGithubConnector(context.TODO(), rsc))
+	default:
+		fmt.Fprintf(os.Stderr, "Skipping resource %v of unsupported kind %q.\n", resource.GetName(), resource.GetKind())
+		return nil
+	}
+}
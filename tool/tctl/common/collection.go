@@ -643,6 +643,109 @@ func (c *remoteClusterCollection) writeYAML(w io.Writer) error {
 	return utils.WriteYAML(w, c.toMarshal())
 }
 
+type clusterConfigCollection struct {
+	clusterConfig services.ClusterConfig
+}
+
+func (c *clusterConfigCollection) resources() []services.Resource {
+	return []services.Resource{c.clusterConfig}
+}
+
+func (c *clusterConfigCollection) writeText(w io.Writer) error {
+	t := asciitable.MakeTable([]string{"Keep-Alive Interval", "Keep-Alive Count Max", "Session Control Timeout"})
+	t.AddRow([]string{
+		c.clusterConfig.GetKeepAliveInterval().String(),
+		fmt.Sprintf("%v", c.clusterConfig.GetKeepAliveCountMax()),
+		c.clusterConfig.GetSessionControlTimeout().String(),
+	})
+	_, err := t.AsBuffer().WriteTo(w)
+	return trace.Wrap(err)
+}
+
+func (c *clusterConfigCollection) writeJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(c.toMarshal(), "", "    ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = w.Write(data)
+	return trace.Wrap(err)
+}
+
+func (c *clusterConfigCollection) toMarshal() interface{} {
+	return c.clusterConfig
+}
+
+func (c *clusterConfigCollection) writeYAML(w io.Writer) error {
+	return utils.WriteYAML(w, c.toMarshal())
+}
+
+type authPreferenceCollection struct {
+	authPreference services.AuthPreference
+}
+
+func (c *authPreferenceCollection) resources() []services.Resource {
+	return []services.Resource{c.authPreference}
+}
+
+func (c *authPreferenceCollection) writeText(w io.Writer) error {
+	t := asciitable.MakeTable([]string{"Type", "Second Factor"})
+	t.AddRow([]string{c.authPreference.GetType(), c.authPreference.GetSecondFactor()})
+	_, err := t.AsBuffer().WriteTo(w)
+	return trace.Wrap(err)
+}
+
+func (c *authPreferenceCollection) writeJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(c.toMarshal(), "", "    ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = w.Write(data)
+	return trace.Wrap(err)
+}
+
+func (c *authPreferenceCollection) toMarshal() interface{} {
+	return c.authPreference
+}
+
+func (c *authPreferenceCollection) writeYAML(w io.Writer) error {
+	return utils.WriteYAML(w, c.toMarshal())
+}
+
+type netRestrictionsCollection struct {
+	netRestricts services.NetworkRestrictions
+}
+
+func (c *netRestrictionsCollection) resources() []services.Resource {
+	return []services.Resource{c.netRestricts}
+}
+
+func (c *netRestrictionsCollection) writeText(w io.Writer) error {
+	t := asciitable.MakeTable([]string{"Allow", "Deny"})
+	t.AddRow([]string{
+		strings.Join(c.netRestricts.GetAllow(), ","),
+		strings.Join(c.netRestricts.GetDeny(), ","),
+	})
+	_, err := t.AsBuffer().WriteTo(w)
+	return trace.Wrap(err)
+}
+
+func (c *netRestrictionsCollection) writeJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(c.toMarshal(), "", "    ")
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	_, err = w.Write(data)
+	return trace.Wrap(err)
+}
+
+func (c *netRestrictionsCollection) toMarshal() interface{} {
+	return c.netRestricts
+}
+
+func (c *netRestrictionsCollection) writeYAML(w io.Writer) error {
+	return utils.WriteYAML(w, c.toMarshal())
+}
+
 type semaphoreCollection struct {
 	sems []services.Semaphore
 }
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/asciitable"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/service"
+	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/trace"
+)
+
+// InventoryCommand implements the `tctl inventory` family of commands, it
+// reports all agents connected to the cluster together with their versions
+// and warns about unsupported version skew.
+type InventoryCommand struct {
+	config *service.Config
+
+	// format is the output format, e.g. text or json
+	format string
+
+	inventoryList *kingpin.CmdClause
+}
+
+// Initialize allows InventoryCommand to plug itself into the CLI parser
+func (c *InventoryCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	c.config = config
+	inventory := app.Command("inventory", "Report connected agents and their versions")
+
+	c.inventoryList = inventory.Command("ls", "List connected auth servers, proxies and nodes").Alias("list")
+	c.inventoryList.Flag("format", "Output format, 'text' or 'json'").Default(teleport.Text).StringVar(&c.format)
+}
+
+// TryRun takes the CLI command as an argument (like "inventory ls") and executes it.
+func (c *InventoryCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case c.inventoryList.FullCommand():
+		err = c.List(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// inventoryEntry is a single connected agent in the inventory.
+type inventoryEntry struct {
+	// Type is the type of the agent: auth, proxy or node.
+	Type string `json:"type"`
+	// ID is the host UUID of the agent.
+	ID string `json:"id"`
+	// Hostname is the hostname reported by the agent.
+	Hostname string `json:"hostname"`
+	// Addr is the address the agent advertises.
+	Addr string `json:"addr"`
+	// Version is the teleport version the agent is running.
+	Version string `json:"version"`
+}
+
+// List prints all agents connected to the cluster.
+func (c *InventoryCommand) List(client auth.ClientI) error {
+	var inventory []inventoryEntry
+
+	collect := func(agentType string, servers []services.Server, err error) error {
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, server := range servers {
+			inventory = append(inventory, inventoryEntry{
+				Type:     agentType,
+				ID:       server.GetName(),
+				Hostname: server.GetHostname(),
+				Addr:     server.GetAddr(),
+				Version:  server.GetTeleportVersion(),
+			})
+		}
+		return nil
+	}
+
+	authServers, err := client.GetAuthServers()
+	if err := collect("auth", authServers, err); err != nil {
+		return trace.Wrap(err)
+	}
+	proxies, err := client.GetProxies()
+	if err := collect("proxy", proxies, err); err != nil {
+		return trace.Wrap(err)
+	}
+	nodes, err := client.GetNodes(defaults.Namespace)
+	if err := collect("node", nodes, err); err != nil {
+		return trace.Wrap(err)
+	}
+
+	switch c.format {
+	case teleport.JSON:
+		out, err := json.MarshalIndent(inventory, "", "  ")
+		if err != nil {
+			return trace.Wrap(err, "failed to marshal inventory")
+		}
+		fmt.Print(string(out))
+	default:
+		t := asciitable.MakeTable([]string{"Type", "Host UUID", "Hostname", "Address", "Version"})
+		for _, entry := range inventory {
+			t.AddRow([]string{entry.Type, entry.ID, entry.Hostname, entry.Addr, entry.Version})
+		}
+		fmt.Println(t.AsBuffer().String())
+	}
+
+	warnVersionSkew(inventory)
+	return nil
+}
+
+// warnVersionSkew prints a warning for every agent running a version that is
+// more than one major version behind this cluster or ahead of it. Upgrades
+// are only supported one major version at a time.
+func warnVersionSkew(inventory []inventoryEntry) {
+	current, err := semver.NewVersion(teleport.Version)
+	if err != nil {
+		return
+	}
+	for _, entry := range inventory {
+		agent, err := semver.NewVersion(entry.Version)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v %v reports unparseable version %q.\n",
+				entry.Type, entry.Hostname, entry.Version)
+			continue
+		}
+		switch {
+		case agent.Major < current.Major-1:
+			fmt.Fprintf(os.Stderr, "WARNING: %v %v is running %v which is more than one major version behind %v, upgrade it before upgrading the cluster further.\n",
+				entry.Type, entry.Hostname, entry.Version, teleport.Version)
+		case agent.Major > current.Major:
+			fmt.Fprintf(os.Stderr, "WARNING: %v %v is running %v which is newer than this cluster (%v), downgrade the agent or upgrade the cluster.\n",
+				entry.Type, entry.Hostname, entry.Version, teleport.Version)
+		}
+	}
+}
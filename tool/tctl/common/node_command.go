@@ -26,7 +26,9 @@ import (
 
 	"github.com/gravitational/kingpin"
 	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/asciitable"
 	"github.com/gravitational/teleport/lib/auth"
+	libclient "github.com/gravitational/teleport/lib/client"
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/service"
 	"github.com/gravitational/teleport/lib/services"
@@ -48,6 +50,13 @@ type NodeCommand struct {
 	// token is an optional custom token supplied by client,
 	// if not specified, is autogenerated
 	token string
+	// labels is a comma-separated list of label=value pairs to filter
+	// listed nodes by
+	labels string
+	// predicate is a query expression to filter listed nodes by
+	predicate string
+	// stale limits the listing to nodes that have missed recent heartbeats
+	stale bool
 
 	// CLI subcommands (clauses)
 	nodeAdd  *kingpin.CmdClause
@@ -69,6 +78,10 @@ func (c *NodeCommand) Initialize(app *kingpin.Application, config *service.Confi
 
 	c.nodeList = nodes.Command("ls", "List all active SSH nodes within the cluster")
 	c.nodeList.Flag("namespace", "Namespace of the nodes").Default(defaults.Namespace).StringVar(&c.namespace)
+	c.nodeList.Flag("format", "Output format, 'text' or 'json'").Default(teleport.Text).StringVar(&c.format)
+	c.nodeList.Flag("labels", "Only list nodes with matching labels, e.g. env=prod,os=linux").StringVar(&c.labels)
+	c.nodeList.Flag("query", "Only list nodes matching the query expression, e.g. 'labels.env == \"prod\"'").StringVar(&c.predicate)
+	c.nodeList.Flag("stale", "Only list nodes that have not heartbeated recently").BoolVar(&c.stale)
 	c.nodeList.Alias(ListNodesHelp)
 }
 
@@ -166,6 +179,11 @@ func (c *NodeCommand) Invite(client auth.ClientI) error {
 	return nil
 }
 
+// staleHeartbeatThreshold is the heartbeat age after which a node is
+// considered stale: it has missed several keep-alive periods but has not
+// expired from the backend yet
+var staleHeartbeatThreshold = 2 * defaults.ServerKeepAliveTTL
+
 // ListActive retreives the list of nodes who recently sent heartbeats to
 // to a cluster and prints it to stdout
 func (c *NodeCommand) ListActive(client auth.ClientI) error {
@@ -173,9 +191,94 @@ func (c *NodeCommand) ListActive(client auth.ClientI) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	coll := &serverCollection{servers: nodes}
-	if err := coll.writeText(os.Stdout); err != nil {
+	nodes, err = c.filterNodes(nodes)
+	if err != nil {
 		return trace.Wrap(err)
 	}
+
+	switch c.format {
+	case teleport.Text:
+		t := asciitable.MakeTable([]string{"Nodename", "UUID", "Address", "Labels", "Version", "Last Heartbeat"})
+		for _, n := range nodes {
+			t.AddRow([]string{
+				n.GetHostname(), n.GetName(), n.GetAddr(), n.LabelsString(),
+				n.GetTeleportVersion(), formatHeartbeatAge(lastHeartbeat(n)),
+			})
+		}
+		if _, err := t.AsBuffer().WriteTo(os.Stdout); err != nil {
+			return trace.Wrap(err)
+		}
+	case teleport.JSON:
+		coll := &serverCollection{servers: nodes}
+		if err := coll.writeJSON(os.Stdout); err != nil {
+			return trace.Wrap(err)
+		}
+	default:
+		return trace.BadParameter("unsupported format %q, supported formats: %v, %v",
+			c.format, teleport.Text, teleport.JSON)
+	}
 	return nil
 }
+
+// filterNodes applies the --labels, --query and --stale filters to the
+// list of nodes
+func (c *NodeCommand) filterNodes(nodes []services.Server) ([]services.Server, error) {
+	var labels map[string]string
+	if c.labels != "" {
+		var err error
+		labels, err = libclient.ParseLabelSpec(c.labels)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+	}
+	out := make([]services.Server, 0, len(nodes))
+	for _, node := range nodes {
+		if !matchesLabels(node, labels) {
+			continue
+		}
+		if c.predicate != "" {
+			match, err := services.MatchServerQuery(node, c.predicate)
+			if err != nil {
+				return nil, trace.Wrap(err)
+			}
+			if !match {
+				continue
+			}
+		}
+		if c.stale && time.Since(lastHeartbeat(node)) < staleHeartbeatThreshold {
+			continue
+		}
+		out = append(out, node)
+	}
+	return out, nil
+}
+
+// matchesLabels returns true if the node has all the requested labels
+func matchesLabels(node services.Server, labels map[string]string) bool {
+	nodeLabels := node.GetAllLabels()
+	for key, value := range labels {
+		if nodeLabels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// lastHeartbeat returns the time the node last announced itself, derived
+// from the resource expiry set by the heartbeat
+func lastHeartbeat(node services.Server) time.Time {
+	return node.Expiry().Add(-defaults.ServerAnnounceTTL)
+}
+
+// formatHeartbeatAge returns a human-friendly description of how long ago
+// the given heartbeat happened
+func formatHeartbeatAge(heartbeat time.Time) string {
+	if heartbeat.IsZero() {
+		return "never"
+	}
+	age := time.Since(heartbeat)
+	if age < 0 {
+		age = 0
+	}
+	return fmt.Sprintf("%v ago", age.Round(time.Second))
+}
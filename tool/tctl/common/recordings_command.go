@@ -0,0 +1,219 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/asciitable"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/service"
+	"github.com/gravitational/teleport/lib/session"
+
+	"github.com/gravitational/trace"
+)
+
+// RecordingsCommand implements the `tctl recordings` set of commands for
+// managing stored session recordings. It implements CLICommand.
+type RecordingsCommand struct {
+	config *service.Config
+
+	// namespace is the namespace to operate on
+	namespace string
+	// recordingsDir is the directory holding local recording copies
+	recordingsDir string
+	// sessionID is the ID of the recording to export or delete
+	sessionID string
+	// out is the file to export a recording to
+	out string
+	// days is how many days of recordings to list
+	days int
+
+	recordingsList   *kingpin.CmdClause
+	recordingsExport *kingpin.CmdClause
+	recordingsDelete *kingpin.CmdClause
+	recordingsSync   *kingpin.CmdClause
+}
+
+// Initialize allows RecordingsCommand to plug itself into the CLI parser
+func (c *RecordingsCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	c.config = config
+	recordings := app.Command("recordings", "Manage session recordings")
+
+	c.recordingsList = recordings.Command("ls", "Show stored session recordings")
+	c.recordingsList.Flag("days", "Number of days to search for recordings").Default("7").IntVar(&c.days)
+
+	c.recordingsExport = recordings.Command("export", "Export a session recording to a local file")
+	c.recordingsExport.Arg("session-id", "ID of the session to export").Required().StringVar(&c.sessionID)
+	c.recordingsExport.Flag("namespace", "Namespace of the recording").Default(defaults.Namespace).StringVar(&c.namespace)
+	c.recordingsExport.Flag("out", "Output file, defaults to <session-id>.tar").StringVar(&c.out)
+
+	c.recordingsDelete = recordings.Command("rm", "Delete a stored session recording").Alias("delete")
+	c.recordingsDelete.Arg("session-id", "ID of the session recording to delete").Required().StringVar(&c.sessionID)
+	c.recordingsDelete.Flag("namespace", "Namespace of the recording").Default(defaults.Namespace).StringVar(&c.namespace)
+
+	c.recordingsSync = recordings.Command("sync", "Re-upload local session recording copies to the cluster")
+	c.recordingsSync.Flag("namespace", "Namespace of the recordings").Default(defaults.Namespace).StringVar(&c.namespace)
+	c.recordingsSync.Flag("dir", "Directory with local recording copies").StringVar(&c.recordingsDir)
+}
+
+// TryRun attempts to run subcommands like "recordings sync".
+func (c *RecordingsCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case c.recordingsList.FullCommand():
+		err = c.List(client)
+	case c.recordingsExport.FullCommand():
+		err = c.Export(client)
+	case c.recordingsDelete.FullCommand():
+		err = c.Delete(client)
+	case c.recordingsSync.FullCommand():
+		err = c.Sync(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// List prints the completed session recordings found in the audit log over
+// the requested number of days. Metadata comes from the session.end events,
+// the recordings themselves live in the session storage handler.
+func (c *RecordingsCommand) List(client auth.ClientI) error {
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -c.days)
+	sessionEvents, err := client.SearchSessionEvents(from, to, defaults.EventsIterationLimit)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	table := asciitable.MakeTable([]string{"ID", "User", "Hostname", "Started", "Duration"})
+	for _, event := range sessionEvents {
+		if event.GetType() != events.SessionEndEvent {
+			continue
+		}
+		start := event.GetTime(events.SessionStartTime)
+		end := event.GetTime(events.SessionEndTime)
+		var duration string
+		if !start.IsZero() && !end.IsZero() {
+			duration = end.Sub(start).Round(time.Second).String()
+		}
+		table.AddRow([]string{
+			event.GetString(events.SessionEventID),
+			event.GetString(events.EventUser),
+			event.GetString(events.SessionServerHostname),
+			start.Format(time.RFC3339),
+			duration,
+		})
+	}
+	fmt.Println(table.AsBuffer().String())
+	return nil
+}
+
+// Export downloads the stored session recording tarball to a local file.
+func (c *RecordingsCommand) Export(client auth.ClientI) error {
+	sessionID, err := session.ParseID(c.sessionID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	out := c.out
+	if out == "" {
+		out = string(*sessionID) + ".tar"
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer f.Close()
+	if err := client.DownloadSessionRecording(c.namespace, *sessionID, f); err != nil {
+		os.Remove(out)
+		return trace.Wrap(err)
+	}
+	fmt.Printf("recording of session %v saved to %v\n", sessionID, out)
+	return nil
+}
+
+// Delete removes the stored session recording, audit events of the session
+// are not affected.
+func (c *RecordingsCommand) Delete(client auth.ClientI) error {
+	sessionID, err := session.ParseID(c.sessionID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := client.DeleteSessionRecording(c.namespace, *sessionID); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("recording of session %v has been deleted\n", sessionID)
+	return nil
+}
+
+// Sync re-uploads local session recording copies kept by the dual-write
+// recording mode to the cluster. Uploads go through the auth server and
+// land in whatever session storage it is currently configured with, so
+// recordings that failed to reach a misconfigured or unavailable external
+// backend can be reconciled after the backend has been fixed.
+func (c *RecordingsCommand) Sync(client auth.ClientI) error {
+	dir := c.recordingsDir
+	if dir == "" {
+		dir = filepath.Join(c.config.DataDir, teleport.LogsDir, events.RecordsDir)
+	}
+	df, err := os.Open(dir)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer df.Close()
+	entries, err := df.Readdir(-1)
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	var synced, failed int
+	for _, fi := range entries {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".tar" {
+			continue
+		}
+		sessionID, err := session.ParseID(strings.TrimSuffix(fi.Name(), ".tar"))
+		if err != nil {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return trace.ConvertSystemError(err)
+		}
+		err = client.UploadSessionRecording(events.SessionRecording{
+			Namespace: c.namespace,
+			SessionID: *sessionID,
+			Recording: f,
+		})
+		f.Close()
+		if err != nil {
+			fmt.Printf("failed to sync session %v: %v\n", sessionID, err)
+			failed++
+			continue
+		}
+		synced++
+	}
+	fmt.Printf("synced %v recording(s), %v failed\n", synced, failed)
+	if failed != 0 {
+		return trace.Errorf("%v recording(s) failed to sync", failed)
+	}
+	return nil
+}
@@ -17,10 +17,15 @@ limitations under the License.
 package common
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"strings"
 
 	"github.com/gravitational/teleport"
 	"github.com/gravitational/teleport/lib/auth"
@@ -54,10 +59,16 @@ type ResourceCommand struct {
 	// filename is the name of the resource, used for 'create'
 	filename string
 
+	// dryRun and diff control the behavior of 'apply'
+	dryRun bool
+	diff   bool
+
 	// CLI subcommands:
 	deleteCmd *kingpin.CmdClause
 	getCmd    *kingpin.CmdClause
 	createCmd *kingpin.CmdClause
+	editCmd   *kingpin.CmdClause
+	applyCmd  *kingpin.CmdClause
 
 	CreateHandlers map[ResourceKind]ResourceCreateHandler
 }
@@ -76,10 +87,15 @@ Same as above, but using JSON output:
 // Initialize allows ResourceCommand to plug itself into the CLI parser
 func (rc *ResourceCommand) Initialize(app *kingpin.Application, config *service.Config) {
 	rc.CreateHandlers = map[ResourceKind]ResourceCreateHandler{
-		services.KindUser:            rc.createUser,
-		services.KindTrustedCluster:  rc.createTrustedCluster,
-		services.KindGithubConnector: rc.createGithubConnector,
-		services.KindCertAuthority:   rc.createCertAuthority,
+		services.KindUser:                  rc.createUser,
+		services.KindRole:                  rc.createRole,
+		services.KindTrustedCluster:        rc.createTrustedCluster,
+		services.KindGithubConnector:       rc.createGithubConnector,
+		services.KindCertAuthority:         rc.createCertAuthority,
+		services.KindNode:                  rc.createNode,
+		services.KindClusterConfig:         rc.createClusterConfig,
+		services.KindClusterAuthPreference: rc.createAuthPreference,
+		services.KindNetworkRestrictions:   rc.createNetworkRestrictions,
 	}
 	rc.config = config
 
@@ -103,6 +119,20 @@ func (rc *ResourceCommand) Initialize(app *kingpin.Application, config *service.
 	rc.getCmd.Flag("with-secrets", "Include secrets in resources like certificate authorities or OIDC connectors").Default("false").BoolVar(&rc.withSecrets)
 
 	rc.getCmd.Alias(getHelp)
+
+	rc.editCmd = app.Command("edit", "Edit a resource in $EDITOR and apply the result")
+	rc.editCmd.Arg("resource type/resource name", `Resource to edit
+	<resource type>  Type of a resource [for example: role,user,cluster]
+	<resource name>  Resource name to edit
+
+	Examples:
+	$ tctl edit role/admin
+	$ tctl edit user/alice`).SetValue(&rc.ref)
+
+	rc.applyCmd = app.Command("apply", "Apply resource definitions from a file or directory")
+	rc.applyCmd.Flag("filename", "Resource definition file or a directory with YAML files").Short('f').Required().StringVar(&rc.filename)
+	rc.applyCmd.Flag("dry-run", "Only report what would change, do not apply anything").BoolVar(&rc.dryRun)
+	rc.applyCmd.Flag("diff", "Print a diff against the live resource for every change").BoolVar(&rc.diff)
 }
 
 // TryRun takes the CLI command as an argument (like "auth gen") and executes it
@@ -118,6 +148,12 @@ func (rc *ResourceCommand) TryRun(cmd string, client auth.ClientI) (match bool,
 		// tctl rm
 	case rc.deleteCmd.FullCommand():
 		err = rc.Delete(client)
+		// tctl edit
+	case rc.editCmd.FullCommand():
+		err = rc.Edit(client)
+		// tctl apply
+	case rc.applyCmd.FullCommand():
+		err = rc.Apply(client)
 	default:
 		return false, nil
 	}
@@ -163,9 +199,6 @@ func (rc *ResourceCommand) Get(client auth.ClientI) error {
 }
 
 func (rc *ResourceCommand) GetMany(client auth.ClientI) error {
-	if rc.format != teleport.YAML {
-		return trace.BadParameter("mixed resource types only support YAML formatting")
-	}
 	var resources []services.Resource
 	for _, ref := range rc.refs {
 		rc.ref = ref
@@ -175,8 +208,21 @@ func (rc *ResourceCommand) GetMany(client auth.ClientI) error {
 		}
 		resources = append(resources, collection.resources()...)
 	}
-	if err := utils.WriteYAML(os.Stdout, resources); err != nil {
-		return trace.Wrap(err)
+	switch rc.format {
+	case teleport.YAML:
+		if err := utils.WriteYAML(os.Stdout, resources); err != nil {
+			return trace.Wrap(err)
+		}
+	case teleport.JSON:
+		data, err := json.MarshalIndent(resources, "", "    ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if _, err := os.Stdout.Write(data); err != nil {
+			return trace.Wrap(err)
+		}
+	default:
+		return trace.BadParameter("mixed resource types only support YAML or JSON formatting")
 	}
 	return nil
 }
@@ -275,6 +321,69 @@ func (rc *ResourceCommand) createTrustedCluster(client auth.ClientI, raw service
 	return nil
 }
 
+// createNode registers a node resource, used to make agentless OpenSSH
+// hosts routable by the proxy in recording proxy mode and visible in
+// 'tsh ls' with labels for RBAC.
+func (rc *ResourceCommand) createNode(client auth.ClientI, raw services.UnknownResource) error {
+	server, err := services.GetServerMarshaler().UnmarshalServer(raw.Raw, services.KindNode)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if server.GetNamespace() == "" {
+		server.SetNamespace(defaults.Namespace)
+	}
+	if _, err := client.UpsertNode(server); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("node %q has been registered\n", server.GetName())
+	return nil
+}
+
+// createClusterConfig updates cluster level configuration, this makes
+// networking settings like keep-alives and the session control timeout
+// adjustable at runtime without editing file configuration on every node
+func (rc *ResourceCommand) createClusterConfig(client auth.ClientI, raw services.UnknownResource) error {
+	clusterConfig, err := services.GetClusterConfigMarshaler().Unmarshal(raw.Raw)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := client.SetClusterConfig(clusterConfig); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("cluster configuration has been updated\n")
+	return nil
+}
+
+// createNetworkRestrictions updates the cluster-wide network restrictions,
+// the allow and deny CIDR lists applied to all recorded sessions by the
+// nodes running enhanced session recording
+func (rc *ResourceCommand) createNetworkRestrictions(client auth.ClientI, raw services.UnknownResource) error {
+	netRestricts, err := services.GetNetworkRestrictionsMarshaler().Unmarshal(raw.Raw)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := client.SetNetworkRestrictions(netRestricts); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("network restrictions have been updated\n")
+	return nil
+}
+
+// createAuthPreference updates the cluster authentication preference,
+// this makes settings like the second factor type adjustable at runtime
+// without editing file configuration and restarting the auth servers
+func (rc *ResourceCommand) createAuthPreference(client auth.ClientI, raw services.UnknownResource) error {
+	authPreference, err := services.GetAuthPreferenceMarshaler().Unmarshal(raw.Raw)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if err := client.SetAuthPreference(authPreference); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("cluster auth preference has been updated\n")
+	return nil
+}
+
 // createCertAuthority creates certificate authority
 func (rc *ResourceCommand) createCertAuthority(client auth.ClientI, raw services.UnknownResource) error {
 	certAuthority, err := services.GetCertAuthorityMarshaler().UnmarshalCertAuthority(raw.Raw)
@@ -350,6 +459,138 @@ func (rc *ResourceCommand) createUser(client auth.ClientI, raw services.UnknownR
 	return nil
 }
 
+// Edit fetches a resource, opens it in the user's $EDITOR as YAML and
+// applies the edited version back to the cluster. The apply is rejected if
+// the resource was modified by someone else while the editor was open.
+func (rc *ResourceCommand) Edit(client auth.ClientI) error {
+	if rc.ref.Kind == "" || rc.ref.Name == "" {
+		return trace.BadParameter("provide a full resource name to edit, for example:\n$ tctl edit role/admin\n")
+	}
+	resource, err := rc.getResource(client)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// remember the resource ID to detect concurrent modifications
+	// made while the editor was open
+	originalID := resource.GetResourceID()
+
+	f, err := ioutil.TempFile("", "tctl-edit-*.yaml")
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	defer os.Remove(f.Name())
+	if err := utils.WriteYAML(f, resource); err != nil {
+		f.Close()
+		return trace.Wrap(err)
+	}
+	if err := f.Close(); err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	original, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+
+	if err := runEditor(f.Name()); err != nil {
+		return trace.Wrap(err)
+	}
+
+	edited, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return trace.ConvertSystemError(err)
+	}
+	if bytes.Equal(original, edited) {
+		fmt.Printf("edit canceled, resource %v/%v has not been modified\n", rc.ref.Kind, rc.ref.Name)
+		return nil
+	}
+
+	decoder := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(edited), defaults.LookaheadBufSize)
+	var raw services.UnknownResource
+	if err := decoder.Decode(&raw); err != nil {
+		return trace.Wrap(err)
+	}
+	if raw.Kind != rc.ref.Kind {
+		return trace.BadParameter("resource kind %q does not match expected kind %q",
+			raw.Kind, rc.ref.Kind)
+	}
+	creator, found := rc.CreateHandlers[ResourceKind(raw.Kind)]
+	if !found {
+		return trace.BadParameter("editing resources of type %q is not supported", raw.Kind)
+	}
+
+	// check that nobody has updated the resource while the editor was open
+	current, err := rc.getResource(client)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if current.GetResourceID() != originalID {
+		return trace.CompareFailed("resource %v/%v has been modified while editing, re-run 'tctl edit' and apply the change again",
+			rc.ref.Kind, rc.ref.Name)
+	}
+
+	// the user has seen (and edited) the current version of the resource,
+	// overwriting it is the whole point of the command:
+	rc.force = true
+	return trace.Wrap(creator(client, raw))
+}
+
+// getResource fetches a single resource addressed by rc.ref
+func (rc *ResourceCommand) getResource(client auth.ClientI) (services.Resource, error) {
+	collection, err := rc.getCollection(client)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	for _, resource := range collection.resources() {
+		if resource.GetName() == rc.ref.Name {
+			return resource, nil
+		}
+	}
+	return nil, trace.NotFound("%v %q is not found", rc.ref.Kind, rc.ref.Name)
+}
+
+// runEditor opens the given file in the editor set via $EDITOR (falling
+// back to vi) and waits for the editor to exit
+func runEditor(path string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	// $EDITOR may carry arguments, e.g. "code --wait"
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return trace.Wrap(err, "editor %q exited with an error", editor)
+	}
+	return nil
+}
+
+// createRole implements 'tctl create role.yaml' command.
+func (rc *ResourceCommand) createRole(client auth.ClientI, raw services.UnknownResource) error {
+	role, err := services.GetRoleMarshaler().UnmarshalRole(raw.Raw)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	roleName := role.GetName()
+	_, err = client.GetRole(roleName)
+	if err != nil && !trace.IsNotFound(err) {
+		return trace.Wrap(err)
+	}
+	exists := (err == nil)
+	if !rc.force && exists {
+		return trace.AlreadyExists("role %q already exists", roleName)
+	}
+
+	if err := client.UpsertRole(context.TODO(), role); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("role %q has been %s\n", roleName, UpsertVerb(exists, rc.force))
+	return nil
+}
+
 // Delete deletes resource by name
 func (rc *ResourceCommand) Delete(client auth.ClientI) (err error) {
 	if rc.ref.Kind == "" || rc.ref.Name == "" {
@@ -413,6 +654,11 @@ func (rc *ResourceCommand) Delete(client auth.ClientI) (err error) {
 			return trace.Wrap(err)
 		}
 		fmt.Printf("semaphore '%s/%s' has been deleted\n", rc.ref.SubKind, rc.ref.Name)
+	case services.KindNetworkRestrictions:
+		if err = client.DeleteNetworkRestrictions(); err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Printf("network restrictions have been deleted\n")
 	default:
 		return trace.BadParameter("deleting resources of type %q is not supported", rc.ref.Kind)
 	}
@@ -580,6 +826,24 @@ func (rc *ResourceCommand) getCollection(client auth.ClientI) (c ResourceCollect
 			return nil, trace.Wrap(err)
 		}
 		return &semaphoreCollection{sems: sems}, nil
+	case services.KindClusterConfig:
+		clusterConfig, err := client.GetClusterConfig()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &clusterConfigCollection{clusterConfig: clusterConfig}, nil
+	case services.KindClusterAuthPreference:
+		authPreference, err := client.GetAuthPreference()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &authPreferenceCollection{authPreference: authPreference}, nil
+	case services.KindNetworkRestrictions:
+		netRestricts, err := client.GetNetworkRestrictions()
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		return &netRestrictionsCollection{netRestricts: netRestricts}, nil
 	}
 	return nil, trace.BadParameter("'%v' is not supported", rc.ref.Kind)
 }
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/teleport/lib/asciitable"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/service"
+	"github.com/gravitational/teleport/lib/session"
+
+	"github.com/gravitational/trace"
+)
+
+// SessionsCommand implements the `tctl sessions` set of commands for
+// inspecting and terminating active sessions. It implements CLICommand.
+type SessionsCommand struct {
+	config *service.Config
+
+	// namespace is the namespace to operate on
+	namespace string
+	// sessionID is the ID of the session to terminate
+	sessionID string
+
+	sessionsList *kingpin.CmdClause
+	sessionsKill *kingpin.CmdClause
+}
+
+// Initialize allows SessionsCommand to plug itself into the CLI parser
+func (c *SessionsCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	c.config = config
+	sessions := app.Command("sessions", "Manage active sessions")
+
+	c.sessionsList = sessions.Command("ls", "Show active sessions")
+	c.sessionsList.Flag("namespace", "Namespace of the sessions").Default(defaults.Namespace).StringVar(&c.namespace)
+
+	c.sessionsKill = sessions.Command("kill", "Forcibly terminate an active session")
+	c.sessionsKill.Arg("session-id", "ID of the session to terminate").Required().StringVar(&c.sessionID)
+	c.sessionsKill.Flag("namespace", "Namespace of the session").Default(defaults.Namespace).StringVar(&c.namespace)
+}
+
+// TryRun attempts to run subcommands like "sessions ls".
+func (c *SessionsCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case c.sessionsList.FullCommand():
+		err = c.List(client)
+	case c.sessionsKill.FullCommand():
+		err = c.Kill(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// List prints the active sessions in the namespace.
+func (c *SessionsCommand) List(client auth.ClientI) error {
+	sessions, err := client.GetSessions(c.namespace)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	table := asciitable.MakeTable([]string{"ID", "Server", "Login", "Parties", "Created"})
+	for _, s := range sessions {
+		var parties []string
+		for _, p := range s.Parties {
+			parties = append(parties, p.User)
+		}
+		table.AddRow([]string{
+			s.ID.String(),
+			s.ServerHostname,
+			s.Login,
+			strings.Join(parties, ","),
+			s.Created.Format(time.RFC3339),
+		})
+	}
+	fmt.Println(table.AsBuffer().String())
+	return nil
+}
+
+// Kill terminates an active session: the session record is removed from
+// the backend and the server hosting the session tears it down and
+// disconnects the parties on its next sync.
+func (c *SessionsCommand) Kill(client auth.ClientI) error {
+	id, err := session.ParseID(c.sessionID)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// make sure the session exists to give a sensible error, DeleteSession
+	// on some backends succeeds for missing records
+	if _, err := client.GetSession(c.namespace, *id); err != nil {
+		return trace.Wrap(err)
+	}
+	if err := client.DeleteSession(c.namespace, *id); err != nil {
+		return trace.Wrap(err)
+	}
+	fmt.Printf("session %v has been terminated, parties will be disconnected within %v\n",
+		id, defaults.SessionRefreshPeriod)
+	return nil
+}
@@ -74,6 +74,15 @@ type CLICommand interface {
 	TryRun(selectedCommand string, c auth.ClientI) (match bool, err error)
 }
 
+// LocalCLICommand is implemented by commands that evaluate everything
+// locally and must be runnable without a connection to the auth server.
+type LocalCLICommand interface {
+	// TryRunLocal is executed before connecting to the auth server. The
+	// command must determine if selectedCommand belongs to it and return
+	// match=true
+	TryRunLocal(selectedCommand string) (match bool, err error)
+}
+
 // Run() is the same as 'make'. It helps to share the code between different
 // "distributions" like OSS or Enterprise
 //
@@ -128,6 +137,22 @@ func Run(commands []CLICommand) {
 		return
 	}
 
+	// commands that evaluate everything locally run before configuration is
+	// loaded and the auth server connection is established:
+	for _, c := range commands {
+		local, ok := c.(LocalCLICommand)
+		if !ok {
+			continue
+		}
+		match, err := local.TryRunLocal(selectedCmd)
+		if err != nil {
+			utils.FatalError(err)
+		}
+		if match {
+			return
+		}
+	}
+
 	// configure all commands with Teleport configuration (they share 'cfg')
 	clientConfig, err := applyConfig(&ccf, cfg)
 	if err != nil {
@@ -167,11 +167,19 @@ func (c *TopCommand) render(ctx context.Context, re Report, eventID string) erro
 	t1.RowSeparator = false
 	t1.Rows = [][]string{
 		[]string{"Interactive Sessions", humanize.FormatFloat("", re.Cluster.InteractiveSessions)},
+		[]string{"Registered Nodes", humanize.FormatFloat("", re.Cluster.RegisteredNodes)},
 		[]string{"Cert Gen Active Requests", humanize.FormatFloat("", re.Cluster.GenerateRequests)},
 		[]string{"Cert Gen Requests/sec", humanize.FormatFloat("", re.Cluster.GenerateRequestsCount.GetFreq())},
 		[]string{"Cert Gen Throttled Requests/sec", humanize.FormatFloat("", re.Cluster.GenerateRequestsThrottledCount.GetFreq())},
+		[]string{"Audit Events/sec", humanize.FormatFloat("", re.Cluster.AuditEvents.GetFreq())},
+		[]string{"Audit Failed Events", humanize.FormatFloat("", float64(re.Cluster.AuditFailedEvents.Count))},
 		[]string{"Auth Watcher Queue Size", humanize.FormatFloat("", re.Cache.QueueSize)},
 	}
+	for _, tunnel := range re.Cluster.ReverseTunnels {
+		t1.Rows = append(t1.Rows, []string{
+			fmt.Sprintf("Tunnels (%v)", tunnel.Type), humanize.FormatFloat("", tunnel.Count),
+		})
+	}
 	for _, rc := range re.Cluster.RemoteClusters {
 		t1.Rows = append(t1.Rows, []string{
 			fmt.Sprintf("Cluster %v", rc.Name), rc.IsConnected(),
@@ -404,6 +412,14 @@ func (b *BackendStats) SortedTopRequests() []Request {
 type ClusterStats struct {
 	// InteractiveSessions is a number of active sessions.
 	InteractiveSessions float64
+	// RegisteredNodes is a number of nodes registered in the cluster.
+	RegisteredNodes float64
+	// ReverseTunnels is a list of reverse tunnel counts per tunnel type.
+	ReverseTunnels []TunnelCount
+	// AuditEvents is a total number of emitted audit events.
+	AuditEvents Counter
+	// AuditFailedEvents is a total number of audit events that failed to emit.
+	AuditFailedEvents Counter
 	// RemoteClusters is a list of remote clusters and their status.
 	RemoteClusters []RemoteCluster
 	// GenerateRequests is a number of active generate requests
@@ -417,6 +433,14 @@ type ClusterStats struct {
 	GenerateRequestsHistogram Histogram
 }
 
+// TunnelCount is a number of reverse tunnel connections of a given type
+type TunnelCount struct {
+	// Type is a tunnel type, e.g. "node" or "proxy"
+	Type string
+	// Count is a number of established tunnels of this type
+	Count float64
+}
+
 // RemoteCluster is a remote cluster (or local cluster)
 // connected to this cluster
 type RemoteCluster struct {
@@ -612,6 +636,10 @@ func generateReport(metrics map[string]*dto.MetricFamily, prev *Report, period t
 
 	re.Cluster = ClusterStats{
 		InteractiveSessions:            getGaugeValue(metrics[teleport.MetricServerInteractiveSessions]),
+		RegisteredNodes:                getGaugeValue(metrics[teleport.MetricRegisteredNodes]),
+		ReverseTunnels:                 getTunnelCounts(metrics[teleport.MetricReverseTunnels]),
+		AuditEvents:                    Counter{Count: getCounterValue(metrics[teleport.MetricAuditEmittedEvents])},
+		AuditFailedEvents:              Counter{Count: getCounterValue(metrics[teleport.MetricAuditFailedEmitEvents])},
 		RemoteClusters:                 getRemoteClusters(metrics[teleport.MetricRemoteClusters]),
 		GenerateRequests:               getGaugeValue(metrics[teleport.MetricGenerateRequestsCurrent]),
 		GenerateRequestsCount:          Counter{Count: getCounterValue(metrics[teleport.MetricGenerateRequests])},
@@ -622,6 +650,8 @@ func generateReport(metrics map[string]*dto.MetricFamily, prev *Report, period t
 	if prev != nil {
 		re.Cluster.GenerateRequestsCount.SetFreq(prev.Cluster.GenerateRequestsCount, period)
 		re.Cluster.GenerateRequestsThrottledCount.SetFreq(prev.Cluster.GenerateRequestsThrottledCount, period)
+		re.Cluster.AuditEvents.SetFreq(prev.Cluster.AuditEvents, period)
+		re.Cluster.AuditFailedEvents.SetFreq(prev.Cluster.AuditFailedEvents, period)
 	}
 
 	return &re, nil
@@ -663,6 +693,26 @@ func getRequests(component string, metric *dto.MetricFamily) []Request {
 	return out
 }
 
+func getTunnelCounts(metric *dto.MetricFamily) []TunnelCount {
+	if metric == nil || metric.GetType() != dto.MetricType_GAUGE || len(metric.Metric) == 0 {
+		return nil
+	}
+	out := make([]TunnelCount, 0, len(metric.Metric))
+	for _, gauge := range metric.Metric {
+		tunnel := TunnelCount{
+			Count: gauge.Gauge.GetValue(),
+		}
+		for _, label := range gauge.Label {
+			if label.GetName() == teleport.TagType {
+				tunnel.Type = label.GetValue()
+			}
+		}
+		out = append(out, tunnel)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Type < out[j].Type })
+	return out
+}
+
 func getRemoteClusters(metric *dto.MetricFamily) []RemoteCluster {
 	if metric == nil || metric.GetType() != dto.MetricType_GAUGE || len(metric.Metric) == 0 {
 		return nil
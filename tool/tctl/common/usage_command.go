@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/gravitational/kingpin"
+	"github.com/gravitational/teleport"
+	"github.com/gravitational/teleport/lib/asciitable"
+	"github.com/gravitational/teleport/lib/auth"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/events"
+	"github.com/gravitational/teleport/lib/service"
+
+	"github.com/gravitational/trace"
+)
+
+// UsageCommand implements the `tctl usage` command, it aggregates
+// cluster usage over a time window for capacity planning and license
+// reconciliation.
+type UsageCommand struct {
+	config *service.Config
+
+	since  time.Duration
+	format string
+
+	usage *kingpin.CmdClause
+}
+
+// UsageReport aggregates cluster usage over a time window.
+type UsageReport struct {
+	// From is the start of the reporting window.
+	From time.Time `json:"from"`
+	// To is the end of the reporting window.
+	To time.Time `json:"to"`
+	// RegisteredNodes is the number of nodes currently registered.
+	RegisteredNodes int `json:"registered_nodes"`
+	// DistinctUsers is the number of distinct users that started
+	// sessions within the window.
+	DistinctUsers int `json:"distinct_users"`
+	// SessionsByProtocol counts started sessions by protocol.
+	SessionsByProtocol map[string]int `json:"sessions_by_protocol"`
+	// TotalSessions is the total number of sessions started within
+	// the window.
+	TotalSessions int `json:"total_sessions"`
+}
+
+// Initialize allows UsageCommand to plug itself into the CLI parser.
+func (c *UsageCommand) Initialize(app *kingpin.Application, config *service.Config) {
+	c.config = config
+	c.usage = app.Command("usage", "Report cluster usage: sessions, users and nodes")
+	c.usage.Flag("since", "Reporting window, e.g. 720h for 30 days").Default("720h").DurationVar(&c.since)
+	c.usage.Flag("format", "Output format, 'text' or 'json'").Default(teleport.Text).StringVar(&c.format)
+}
+
+// TryRun takes the CLI command as an argument (like "usage") and executes it.
+func (c *UsageCommand) TryRun(cmd string, client auth.ClientI) (match bool, err error) {
+	switch cmd {
+	case c.usage.FullCommand():
+		err = c.Usage(client)
+	default:
+		return false, nil
+	}
+	return true, trace.Wrap(err)
+}
+
+// Usage is called to execute "usage" CLI command.
+func (c *UsageCommand) Usage(client auth.ClientI) error {
+	report, err := generateUsageReport(client, time.Now().UTC(), c.since)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	switch c.format {
+	case teleport.Text:
+		table := asciitable.MakeTable([]string{"Metric", "Value"})
+		table.AddRow([]string{"Window", fmt.Sprintf("%v - %v", report.From.Format(time.RFC3339), report.To.Format(time.RFC3339))})
+		table.AddRow([]string{"Registered Nodes", fmt.Sprintf("%v", report.RegisteredNodes)})
+		table.AddRow([]string{"Distinct Users", fmt.Sprintf("%v", report.DistinctUsers)})
+		table.AddRow([]string{"Total Sessions", fmt.Sprintf("%v", report.TotalSessions)})
+		for protocol, count := range report.SessionsByProtocol {
+			table.AddRow([]string{fmt.Sprintf("Sessions (%v)", protocol), fmt.Sprintf("%v", count)})
+		}
+		_, err = table.AsBuffer().WriteTo(os.Stdout)
+		return trace.Wrap(err)
+	case teleport.JSON:
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(out))
+		return nil
+	default:
+		return trace.BadParameter("unsupported format %q, supported formats are 'text' and 'json'", c.format)
+	}
+}
+
+// generateUsageReport aggregates session start events and the node
+// inventory into a usage report covering [now-since, now].
+func generateUsageReport(client auth.ClientI, now time.Time, since time.Duration) (*UsageReport, error) {
+	if since <= 0 {
+		return nil, trace.BadParameter("--since must be a positive duration")
+	}
+	from := now.Add(-since)
+
+	nodes, err := client.GetNodes(defaults.Namespace)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	query := url.Values{events.EventType: []string{events.SessionStartEvent}}
+	sessionEvents, err := client.SearchEvents(from, now, query.Encode(), defaults.EventsMaxIterationLimit)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+
+	report := &UsageReport{
+		From:               from,
+		To:                 now,
+		RegisteredNodes:    len(nodes),
+		SessionsByProtocol: make(map[string]int),
+	}
+	users := make(map[string]struct{})
+	for _, event := range sessionEvents {
+		if user := event.GetString(events.EventUser); user != "" {
+			users[user] = struct{}{}
+		}
+		protocol := event.GetString(events.EventProtocol)
+		if protocol == "" {
+			// events recorded before the protocol field was introduced
+			// are SSH sessions
+			protocol = events.EventProtocolSSH
+		}
+		report.SessionsByProtocol[protocol]++
+		report.TotalSessions++
+	}
+	report.DistinctUsers = len(users)
+	return report, nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/gravitational/teleport/lib/defaults"
 	"github.com/gravitational/teleport/lib/service"
 	"github.com/gravitational/teleport/lib/services"
+	"github.com/gravitational/teleport/lib/utils"
 	"github.com/gravitational/trace"
 )
 
@@ -43,6 +44,8 @@ type UserCommand struct {
 	kubeUsers     string
 	kubeGroups    string
 	roles         string
+	addRoles      string
+	removeRoles   string
 	ttl           time.Duration
 
 	// format is the output format, e.g. text or json
@@ -70,16 +73,28 @@ func (u *UserCommand) Initialize(app *kingpin.Application, config *service.Confi
 		Default("").StringVar(&u.kubeUsers)
 	u.userAdd.Flag("k8s-groups", "Kubernetes groups to assign to a user.").
 		Default("").StringVar(&u.kubeGroups)
+	u.userAdd.Flag("roles", fmt.Sprintf("Roles to assign to the user, defaults to %q", teleport.AdminRoleName)).
+		Default("").StringVar(&u.roles)
 	u.userAdd.Flag("ttl", fmt.Sprintf("Set expiration time for token, default is %v, maximum is %v",
 		defaults.SignupTokenTTL, defaults.MaxSignupTokenTTL)).
 		Default(fmt.Sprintf("%v", defaults.SignupTokenTTL)).DurationVar(&u.ttl)
 	u.userAdd.Flag("format", "Output format, 'text' or 'json'").Hidden().Default(teleport.Text).StringVar(&u.format)
 	u.userAdd.Alias(AddUserHelp)
 
-	u.userUpdate = users.Command("update", "Update properties for existing user").Hidden()
+	u.userUpdate = users.Command("update", "Update properties for existing user")
 	u.userUpdate.Arg("login", "Teleport user login").Required().StringVar(&u.login)
-	u.userUpdate.Flag("set-roles", "Roles to assign to this user").
+	u.userUpdate.Flag("set-roles", "Replace the roles assigned to this user").
 		Default("").StringVar(&u.roles)
+	u.userUpdate.Flag("add-roles", "Add roles to this user, keeping the existing ones").
+		Default("").StringVar(&u.addRoles)
+	u.userUpdate.Flag("remove-roles", "Remove roles from this user").
+		Default("").StringVar(&u.removeRoles)
+	u.userUpdate.Flag("set-logins", "Replace the local UNIX logins this user can log in as").
+		Default("").StringVar(&u.allowedLogins)
+	u.userUpdate.Flag("set-k8s-users", "Replace the Kubernetes users assigned to this user").
+		Default("").StringVar(&u.kubeUsers)
+	u.userUpdate.Flag("set-k8s-groups", "Replace the Kubernetes groups assigned to this user").
+		Default("").StringVar(&u.kubeGroups)
 
 	u.userList = users.Command("ls", "List all user accounts "+helpPrefix)
 	u.userList.Flag("format", "Output format, 'text' or 'json'").Hidden().Default(teleport.Text).StringVar(&u.format)
@@ -207,7 +222,17 @@ func (u *UserCommand) Add(client auth.ClientI) error {
 	}
 
 	user.SetTraits(traits)
-	user.AddRole(teleport.AdminRoleName)
+	if u.roles == "" {
+		user.AddRole(teleport.AdminRoleName)
+	} else {
+		roles := strings.Split(u.roles, ",")
+		for _, role := range roles {
+			if _, err := client.GetRole(role); err != nil {
+				return trace.Wrap(err)
+			}
+		}
+		user.SetRoles(roles)
+	}
 	err = client.CreateUser(context.TODO(), user)
 	if err != nil {
 		return trace.Wrap(err)
@@ -257,13 +282,57 @@ func (u *UserCommand) Update(client auth.ClientI) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	roles := strings.Split(u.roles, ",")
-	for _, role := range roles {
-		if _, err := client.GetRole(role); err != nil {
+
+	var updated bool
+	if u.roles != "" {
+		roles := strings.Split(u.roles, ",")
+		if err := checkRolesExist(client, roles); err != nil {
+			return trace.Wrap(err)
+		}
+		user.SetRoles(roles)
+		updated = true
+	}
+	if u.addRoles != "" {
+		roles := strings.Split(u.addRoles, ",")
+		if err := checkRolesExist(client, roles); err != nil {
 			return trace.Wrap(err)
 		}
+		for _, role := range roles {
+			user.AddRole(role)
+		}
+		updated = true
+	}
+	if u.removeRoles != "" {
+		removed := strings.Split(u.removeRoles, ",")
+		var roles []string
+		for _, role := range user.GetRoles() {
+			if !utils.SliceContainsStr(removed, role) {
+				roles = append(roles, role)
+			}
+		}
+		user.SetRoles(roles)
+		updated = true
+	}
+
+	setTrait := func(trait string, value string) {
+		if value == "" {
+			return
+		}
+		traits := user.GetTraits()
+		if traits == nil {
+			traits = map[string][]string{}
+		}
+		traits[trait] = strings.Split(value, ",")
+		user.SetTraits(traits)
+		updated = true
+	}
+	setTrait(teleport.TraitLogins, u.allowedLogins)
+	setTrait(teleport.TraitKubeUsers, u.kubeUsers)
+	setTrait(teleport.TraitKubeGroups, u.kubeGroups)
+
+	if !updated {
+		return trace.BadParameter("please specify at least one property to update")
 	}
-	user.SetRoles(roles)
 	if err := client.UpsertUser(user); err != nil {
 		return trace.Wrap(err)
 	}
@@ -271,6 +340,16 @@ func (u *UserCommand) Update(client auth.ClientI) error {
 	return nil
 }
 
+// checkRolesExist makes sure all roles in the list exist on the auth server.
+func checkRolesExist(client auth.ClientI, roles []string) error {
+	for _, role := range roles {
+		if _, err := client.GetRole(role); err != nil {
+			return trace.Wrap(err)
+		}
+	}
+	return nil
+}
+
 // List prints all existing user accounts
 func (u *UserCommand) List(client auth.ClientI) error {
 	users, err := client.GetUsers(false)
@@ -282,10 +361,10 @@ func (u *UserCommand) List(client auth.ClientI) error {
 			fmt.Println("No users found")
 			return nil
 		}
-		t := asciitable.MakeTable([]string{"User", "Allowed logins"})
+		t := asciitable.MakeTable([]string{"User", "Origin", "Roles", "Allowed logins"})
 		for _, u := range users {
 			logins := u.GetTraits()[teleport.TraitLogins]
-			t.AddRow([]string{u.GetName(), strings.Join(logins, ",")})
+			t.AddRow([]string{u.GetName(), userOrigin(u), strings.Join(u.GetRoles(), ","), strings.Join(logins, ",")})
 		}
 		fmt.Println(t.AsBuffer().String())
 	} else {
@@ -298,6 +377,15 @@ func (u *UserCommand) List(client auth.ClientI) error {
 	return nil
 }
 
+// userOrigin describes where the user account came from, either "local" or
+// the SSO connector that created it, for example "github/acme".
+func userOrigin(user services.User) string {
+	if connector := user.GetCreatedBy().Connector; connector != nil {
+		return fmt.Sprintf("%v/%v", connector.Type, connector.ID)
+	}
+	return "local"
+}
+
 // Delete deletes teleport user(s). User IDs are passed as a comma-separated
 // list in UserCommand.login
 func (u *UserCommand) Delete(client auth.ClientI) error {
@@ -29,7 +29,14 @@ func main() {
 		&common.ResourceCommand{},
 		&common.StatusCommand{},
 		&common.TopCommand{},
+		&common.UsageCommand{},
+		&common.SessionsCommand{},
+		&common.RecordingsCommand{},
 		&common.AccessRequestCommand{},
+		&common.BackupCommand{},
+		&common.InventoryCommand{},
+		&common.AccessCommand{},
+		&common.RBACCommand{},
 	}
 	common.Run(commands)
 }
@@ -101,7 +101,7 @@ func Run(options Options) (executedCommand string, conf *service.Config) {
 		"Invitation token to register with an auth server [none]").
 		StringVar(&ccf.AuthToken)
 	start.Flag("ca-pin",
-		"CA pin to validate the Auth Server").
+		"Comma-separated list of CA pins to validate the Auth Server").
 		StringVar(&ccf.CAPin)
 	start.Flag("nodename",
 		"Name of this node, defaults to hostname").
@@ -110,7 +110,7 @@ func Run(options Options) (executedCommand string, conf *service.Config) {
 		fmt.Sprintf("Path to a configuration file [%v]", defaults.ConfigFilePath)).
 		Short('c').ExistingFileVar(&ccf.ConfigFile)
 	start.Flag("bootstrap",
-		"Path to bootstrap file (ignored if already initialized)").ExistingFileVar(&ccf.BootstrapFile)
+		"Path to a bootstrap file or a directory of resource files (ignored if already initialized)").ExistingFileOrDirVar(&ccf.BootstrapFile)
 	start.Flag("config-string",
 		"Base64 encoded configuration string").Hidden().Envar(defaults.ConfigEnvar).
 		StringVar(&ccf.ConfigString)
@@ -136,6 +136,7 @@ func Run(options Options) (executedCommand string, conf *service.Config) {
 	scpc.Flag("v", "verbose mode").Default("false").Short('v').BoolVar(&scpFlags.Verbose)
 	scpc.Flag("r", "recursive mode").Default("false").Short('r').BoolVar(&scpFlags.Recursive)
 	scpc.Flag("d", "directory mode").Short('d').Hidden().BoolVar(&scpFlags.DirectoryMode)
+	scpc.Flag("preserve", "preserve access and modification times").Short('p').BoolVar(&scpFlags.PreserveAttrs)
 	scpc.Flag("remote-addr", "address of the remote client").StringVar(&scpFlags.RemoteAddr)
 	scpc.Flag("local-addr", "local address which accepted the request").StringVar(&scpFlags.LocalAddr)
 	scpc.Arg("target", "").StringsVar(&scpFlags.Target)
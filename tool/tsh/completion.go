@@ -0,0 +1,81 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// completionShellBash generates completions for the bash shell.
+const completionShellBash = "bash"
+
+// completionShellZsh generates completions for the zsh shell.
+const completionShellZsh = "zsh"
+
+// bashCompletionScript completes tsh subcommands and, for commands taking a
+// host, node names fetched with `tsh ls`. Node name lookups only work while
+// logged in and are best-effort: on any error the host position falls back
+// to regular file completion.
+const bashCompletionScript = `_tsh_completions() {
+    local cur prev commands
+    COMPREPLY=()
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    commands="version ssh join play scp ls clusters sessions login logout status config proxy completion"
+
+    if [ "${COMP_CWORD}" -eq 1 ]; then
+        COMPREPLY=( $(compgen -W "${commands}" -- "${cur}") )
+        return 0
+    fi
+
+    case "${COMP_WORDS[1]}" in
+        ssh|scp)
+            local hosts
+            hosts=$(tsh ls --format=names 2>/dev/null)
+            if [ -n "${hosts}" ]; then
+                COMPREPLY=( $(compgen -W "${hosts}" -- "${cur#*@}") )
+                return 0
+            fi
+            ;;
+    esac
+    return 0
+}
+complete -F _tsh_completions tsh
+`
+
+// zshCompletionScript enables bash completion emulation in zsh and reuses
+// the bash script.
+const zshCompletionScript = `autoload -U +X bashcompinit && bashcompinit
+` + bashCompletionScript
+
+// onCompletion handles `tsh completion <shell>` and prints a completion
+// script suitable for eval'ing or sourcing from the shell's rc file.
+func onCompletion(cf *CLIConf) {
+	switch cf.CompletionShell {
+	case completionShellBash:
+		fmt.Print(bashCompletionScript)
+	case completionShellZsh:
+		fmt.Print(zshCompletionScript)
+	default:
+		utils.FatalError(trace.BadParameter("unsupported shell %q, supported shells: %v, %v",
+			cf.CompletionShell, completionShellBash, completionShellZsh))
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"text/template"
+
+	"github.com/gravitational/teleport/lib/client"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// sshConfigTemplate is a template of an OpenSSH client configuration emitted
+// by `tsh config`. Nodes are reached via the proxy "proxy:%h:%p@cluster"
+// subsystem, which is what the ProxyCommand below requests, so that plain
+// `ssh user@node.cluster-name` (and scp, rsync, ansible, etc. on top of it)
+// works through the Teleport proxy.
+var sshConfigTemplate = template.Must(template.New("ssh-config").Parse(
+	`# Begin generated Teleport configuration for {{ .ProxyHost }} by tsh
+
+# Common flags for all {{ .ClusterName }} hosts
+Host *.{{ .ClusterName }} {{ .ProxyHost }}
+    UserKnownHostsFile "{{ .KnownHostsPath }}"
+    IdentityFile "{{ .IdentityFilePath }}"
+    CertificateFile "{{ .CertificateFilePath }}"
+
+# Flags for all {{ .ClusterName }} hosts except the proxy
+Host *.{{ .ClusterName }} !{{ .ProxyHost }}
+    Port 3022
+    ProxyCommand ssh -p {{ .ProxyPort }} {{ .ProxyUser }}@{{ .ProxyHost }} -s proxy:%h:%p@{{ .ClusterName }}
+
+# End generated Teleport configuration
+`))
+
+// hostConfigParameters is the set of values interpolated into
+// sshConfigTemplate.
+type hostConfigParameters struct {
+	ClusterName         string
+	KnownHostsPath      string
+	IdentityFilePath    string
+	CertificateFilePath string
+	ProxyHost           string
+	ProxyPort           string
+	ProxyUser           string
+}
+
+// writeSSHConfig generates an OpenSSH config block for the given cluster.
+func writeSSHConfig(w io.Writer, params hostConfigParameters) error {
+	if err := sshConfigTemplate.Execute(w, params); err != nil {
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// onConfig handles `tsh config`, generating OpenSSH configuration blocks for
+// the root cluster and all leaf clusters reachable through the current proxy.
+func onConfig(cf *CLIConf) {
+	tc, err := makeClient(cf, true)
+	if err != nil {
+		utils.FatalError(err)
+	}
+
+	// Get the status of the active profile, the generated configuration
+	// points at the keys and certificates stored for it on disk.
+	profile, _, err := client.Status("", cf.Proxy)
+	if err != nil {
+		utils.FatalError(err)
+	}
+	if profile == nil {
+		utils.FatalError(trace.NotFound("not logged in, run 'tsh login' first"))
+	}
+
+	// Collect the names of the root cluster and any leaf clusters, a
+	// configuration block is emitted for each of them.
+	var clusterNames []string
+	err = client.RetryWithRelogin(cf.Context, tc, func() error {
+		proxyClient, err := tc.ConnectToProxy(cf.Context)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		defer proxyClient.Close()
+
+		sites, err := proxyClient.GetSites()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		for _, site := range sites {
+			clusterNames = append(clusterNames, site.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.FatalError(err)
+	}
+
+	proxyHost, proxyPort := tc.SSHProxyHostPort()
+	for _, clusterName := range clusterNames {
+		err = writeSSHConfig(os.Stdout, hostConfigParameters{
+			ClusterName:         clusterName,
+			KnownHostsPath:      profile.KnownHostsPath(),
+			IdentityFilePath:    profile.KeyPath(),
+			CertificateFilePath: profile.CertPath(),
+			ProxyHost:           proxyHost,
+			ProxyPort:           strconv.Itoa(proxyPort),
+			ProxyUser:           tc.Username,
+		})
+		if err != nil {
+			utils.FatalError(err)
+		}
+	}
+}
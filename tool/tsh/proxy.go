@@ -0,0 +1,118 @@
+/*
+Copyright 2020 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/gravitational/teleport/lib/client"
+	"github.com/gravitational/teleport/lib/defaults"
+	"github.com/gravitational/teleport/lib/utils"
+
+	"github.com/gravitational/trace"
+)
+
+// onProxyCommandSSH handles `tsh proxy ssh`. It opens an authenticated
+// connection to the target node through the Teleport proxy and pipes it to
+// stdin/stdout, which makes the command usable as an OpenSSH ProxyCommand:
+//
+//	ProxyCommand tsh proxy ssh %r@%h:%p
+//
+// Third-party tools built on top of ssh (scp, rsync, ansible) then work
+// through the Teleport proxy without any tsh wrappers.
+func onProxyCommandSSH(cf *CLIConf) {
+	// When invoked as a ProxyCommand with "%r@%h:%p" tokens, the node port is
+	// glued to the hostname, split it off before building the client.
+	if host, port, err := net.SplitHostPort(cf.UserHost); err == nil {
+		cf.UserHost = host
+		nodePort, err := strconv.Atoi(port)
+		if err != nil {
+			utils.FatalError(trace.BadParameter("invalid port %q: %v", port, err))
+		}
+		cf.NodePort = int32(nodePort)
+	}
+
+	tc, err := makeClient(cf, false)
+	if err != nil {
+		utils.FatalError(err)
+	}
+	err = client.RetryWithRelogin(cf.Context, tc, func() error {
+		return sshProxy(cf.Context, tc)
+	})
+	if err != nil {
+		utils.FatalError(err)
+	}
+}
+
+// sshProxy opens the "proxy" subsystem to the target node on the Teleport
+// proxy and wires the resulting connection to stdin/stdout of this process.
+func sshProxy(ctx context.Context, tc *client.TeleportClient) error {
+	proxyClient, err := tc.ConnectToProxy(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxyClient.Close()
+
+	proxySession, err := proxyClient.Client.NewSession()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer proxySession.Close()
+
+	proxyWriter, err := proxySession.StdinPipe()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	proxyReader, err := proxySession.StdoutPipe()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+
+	nodePort := tc.HostPort
+	if nodePort == 0 {
+		nodePort = defaults.SSHServerListenPort
+	}
+	nodeAddress := net.JoinHostPort(tc.Host, strconv.Itoa(nodePort))
+	if tc.SiteName != "" {
+		nodeAddress = nodeAddress + "@" + tc.SiteName
+	}
+	if err := proxySession.RequestSubsystem("proxy:" + nodeAddress); err != nil {
+		return trace.Wrap(err)
+	}
+
+	// Pump the connection both ways until one of the sides is closed: either
+	// the ssh client on top of us went away, or the node hung up.
+	errCh := make(chan error, 2)
+	go func() {
+		_, err := io.Copy(proxyWriter, os.Stdin)
+		errCh <- err
+	}()
+	go func() {
+		_, err := io.Copy(os.Stdout, proxyReader)
+		errCh <- err
+	}()
+	select {
+	case err := <-errCh:
+		return trace.Wrap(err)
+	case <-ctx.Done():
+		return trace.Wrap(ctx.Err())
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"path"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -45,12 +46,14 @@ import (
 	"github.com/gravitational/teleport/lib/services"
 	"github.com/gravitational/teleport/lib/session"
 	"github.com/gravitational/teleport/lib/sshutils"
+	"github.com/gravitational/teleport/lib/sshutils/scp"
 	"github.com/gravitational/teleport/lib/utils"
 	"github.com/gravitational/teleport/tool/tsh/common"
 
 	"github.com/gravitational/kingpin"
 	"github.com/gravitational/trace"
 
+	"github.com/docker/docker/pkg/term"
 	gops "github.com/google/gops/agent"
 	"github.com/jonboulle/clockwork"
 	"github.com/sirupsen/logrus"
@@ -86,6 +89,10 @@ type CLIConf struct {
 	CopySpec []string
 	// -r flag for scp
 	RecursiveCopy bool
+	// -p flag for scp
+	PreserveAttrs bool
+	// -l flag for scp, bandwidth limit in Kbit/s
+	BandwidthLimit int64
 	// -L flag for ssh. Local port forwarding like 'ssh -L 80:remote.host:80 -L 443:remote.host:443'
 	LocalForwardPorts []string
 	// DynamicForwardedPorts is port forwarding using SOCKS5. It is similar to
@@ -118,6 +125,10 @@ type CLIConf struct {
 	BenchRate int
 	// BenchInteractive indicates that we should create interactive session
 	BenchInteractive bool
+	// BenchExport exports the latency profile
+	BenchExport bool
+	// BenchExportPath saves the latency profile in provided path
+	BenchExportPath string
 	// Context is a context to control execution
 	Context context.Context
 	// Gops starts gops agent on a specified address
@@ -147,6 +158,13 @@ type CLIConf struct {
 	// SkipVersionCheck skips version checking for client and server
 	SkipVersionCheck bool
 
+	// AutoRenew refreshes credentials before long-running commands start
+	// when the current certificate is close to expiry.
+	AutoRenew bool
+
+	// ListAll makes `tsh ls` list every resource kind, not just nodes.
+	ListAll bool
+
 	// Options is a list of OpenSSH options in the format used in the
 	// configuration file.
 	Options []string
@@ -154,6 +172,30 @@ type CLIConf struct {
 	// Verbose is used to print extra output.
 	Verbose bool
 
+	// SearchKeywords is a list of comma separated keywords used for fuzzy
+	// matching during node listings.
+	SearchKeywords string
+
+	// PredicateExpression defines boolean conditions that will be matched
+	// against the node listing.
+	PredicateExpression string
+
+	// ParallelJobs is the maximum number of nodes a command matched by
+	// labels runs on concurrently.
+	ParallelJobs int
+
+	// JoinMode is the mode of joining an active session, peer or observer.
+	JoinMode string
+
+	// PlaySpeed is the speed of session playback, e.g. "2x".
+	PlaySpeed string
+
+	// CompletionShell is the shell to print a completion script for.
+	CompletionShell string
+
+	// ProfileName is the name of a locally stored profile to operate on.
+	ProfileName string
+
 	// Format is used to change the format of output
 	Format string
 
@@ -172,6 +214,10 @@ type CLIConf struct {
 	// connect to the local ssh-agent (or similar) socket at $SSH_AUTH_SOCK.
 	UseLocalSSHAgent bool
 
+	// MemKeystore set to true will keep keys and certificates in memory
+	// instead of ~/.tsh, reusing them until they expire.
+	MemKeystore bool
+
 	// EnableEscapeSequences will scan stdin for SSH escape sequences during
 	// command/shell execution. This also requires stdin to be an interactive
 	// terminal.
@@ -202,6 +248,7 @@ const (
 	authEnvVar             = "TELEPORT_AUTH"
 	browserHelp            = "Set to 'none' to suppress browser opening on login"
 	useLocalSSHAgentEnvVar = "TELEPORT_USE_LOCAL_SSH_AGENT"
+	memKeystoreEnvVar      = "TELEPORT_MEM_KEYSTORE"
 )
 
 // Run executes TSH client. same as main() but easier to test
@@ -230,6 +277,7 @@ func Run(args []string) {
 	app.Flag("gops", "Start gops endpoint on a given address").Hidden().BoolVar(&cf.Gops)
 	app.Flag("gops-addr", "Specify gops addr to listen on").Hidden().StringVar(&cf.GopsAddr)
 	app.Flag("skip-version-check", "Skip version checking between server and client.").BoolVar(&cf.SkipVersionCheck)
+	app.Flag("auto-renew", "Re-login before starting long-running commands if credentials are close to expiry").Envar("TELEPORT_AUTO_RENEW").BoolVar(&cf.AutoRenew)
 	app.Flag("debug", "Verbose logging to stdout").Short('d').BoolVar(&cf.Debug)
 	app.Flag("use-local-ssh-agent", "Load generated SSH certificates into the local ssh-agent (specified via $SSH_AUTH_SOCK). You can also set TELEPORT_USE_LOCAL_SSH_AGENT environment variable. Default is true.").
 		Envar(useLocalSSHAgentEnvVar).
@@ -238,12 +286,15 @@ func Run(args []string) {
 	app.Flag("enable-escape-sequences", "Enable support for SSH escape sequences. Type '~?' during an SSH session to list supported sequences. Default is enabled.").
 		Default("true").
 		BoolVar(&cf.EnableEscapeSequences)
+	app.Flag("mem-keystore", "Keep keys and certificates in memory instead of ~/.tsh, reusing them until they expire. You can also set TELEPORT_MEM_KEYSTORE environment variable.").
+		Envar(memKeystoreEnvVar).
+		BoolVar(&cf.MemKeystore)
 	app.Flag("bind-addr", "Override host:port used when opening a browser for cluster logins").Envar(bindAddrEnvVar).StringVar(&cf.BindAddr)
 	app.HelpFlag.Short('h')
 	ver := app.Command("version", "Print the version")
 	// ssh
 	ssh := app.Command("ssh", "Run shell or execute a command on a remote SSH node")
-	ssh.Arg("[user@]host", "Remote hostname and the login to use").Required().StringVar(&cf.UserHost)
+	ssh.Arg("[user@]host", "Remote hostname and the login to use, prompts for a host when not specified").StringVar(&cf.UserHost)
 	ssh.Arg("command", "Command to execute on a remote host").StringsVar(&cf.RemoteCommand)
 	app.Flag("jumphost", "SSH jumphost").Short('J').StringVar(&cf.ProxyJump)
 	ssh.Flag("port", "SSH port on a remote host").Short('p').Int32Var(&cf.NodePort)
@@ -255,20 +306,31 @@ func Run(args []string) {
 	ssh.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
 	ssh.Flag("option", "OpenSSH options in the format used in the configuration file").Short('o').AllowDuplicate().StringsVar(&cf.Options)
 	ssh.Flag("no-remote-exec", "Don't execute remote command, useful for port forwarding").Short('N').BoolVar(&cf.NoRemoteExec)
+	ssh.Flag("parallel", "Maximum number of nodes a command matched by labels runs on concurrently").Default("10").IntVar(&cf.ParallelJobs)
 
 	// join
 	join := app.Command("join", "Join the active SSH session")
 	join.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
+	join.Flag("mode", "Mode of joining the session, 'peer' to participate or 'observer' to watch").Default(teleport.SSHSessionJoinModePeer).EnumVar(&cf.JoinMode, teleport.SSHSessionJoinModePeer, teleport.SSHSessionJoinModeObserver)
 	join.Arg("session-id", "ID of the session to join").Required().StringVar(&cf.SessionID)
 	// play
 	play := app.Command("play", "Replay the recorded SSH session")
 	play.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
+	play.Flag("format", "Format output (text, json)").Short('f').Default(teleport.Text).StringVar(&cf.Format)
+	play.Flag("speed", "Playback speed, e.g. 0.5x, 2x or 4x").Default("1x").StringVar(&cf.PlaySpeed)
 	play.Arg("session-id", "ID of the session to play").Required().StringVar(&cf.SessionID)
+	// sessions
+	sessions := app.Command("sessions", "Operate on active sessions")
+	sessionsLs := sessions.Command("ls", "List active sessions and their participants")
+	sessionsLs.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
+	sessionsLs.Flag("format", "Format output (text, json, yaml)").Short('f').Default(teleport.Text).StringVar(&cf.Format)
 	// scp
 	scp := app.Command("scp", "Secure file copy")
 	scp.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
 	scp.Arg("from, to", "Source and destination to copy").Required().StringsVar(&cf.CopySpec)
 	scp.Flag("recursive", "Recursive copy of subdirectories").Short('r').BoolVar(&cf.RecursiveCopy)
+	scp.Flag("preserve", "Preserve access and modification times from the original file").Short('p').BoolVar(&cf.PreserveAttrs)
+	scp.Flag("limit", "Limit the used bandwidth, in Kbit/s").Short('l').Int64Var(&cf.BandwidthLimit)
 	scp.Flag("port", "Port to connect to on the remote host").Short('P').Int32Var(&cf.NodePort)
 	scp.Flag("quiet", "Quiet mode").Short('q').BoolVar(&cf.Quiet)
 	// ls
@@ -276,18 +338,23 @@ func Run(args []string) {
 	ls.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
 	ls.Arg("labels", "List of labels to filter node list").StringVar(&cf.UserHost)
 	ls.Flag("verbose", "One-line output (for text format), including node UUIDs").Short('v').BoolVar(&cf.Verbose)
-	ls.Flag("format", "Format output (text, json, names)").Short('f').Default(teleport.Text).StringVar(&cf.Format)
+	ls.Flag("format", "Format output (text, json, yaml, names)").Short('f').Default(teleport.Text).StringVar(&cf.Format)
+	ls.Flag("all", "List all resource kinds, not just SSH nodes").Short('R').BoolVar(&cf.ListAll)
+	ls.Flag("search", "List of comma separated search keywords or phrases enclosed in quotations (e.g. --search=foo,bar)").StringVar(&cf.SearchKeywords)
+	ls.Flag("query", `Query by predicate language enclosed in single quotes. Supports ==, !=, &&, and || (e.g. --query='labels.key1 == "value1" && labels.key2 != "value2"')`).StringVar(&cf.PredicateExpression)
 	// clusters
 	clusters := app.Command("clusters", "List available Teleport clusters")
+	clusters.Flag("format", "Format output (text, json, yaml, names)").Short('f').Default(teleport.Text).StringVar(&cf.Format)
 	clusters.Flag("quiet", "Quiet mode").Short('q').BoolVar(&cf.Quiet)
 
 	// login logs in with remote proxy and obtains a "session certificate" which gets
 	// stored in ~/.tsh directory
 	login := app.Command("login", "Log in to a cluster and retrieve the session certificate")
 	login.Flag("out", "Identity output").Short('o').AllowDuplicate().StringVar(&cf.IdentityFileOut)
-	login.Flag("format", fmt.Sprintf("Identity format: %s, %s (for OpenSSH compatibility) or %s (for kubeconfig)",
+	login.Flag("format", fmt.Sprintf("Identity format: %s, %s (for OpenSSH compatibility), %s (for TLS clients) or %s (for kubeconfig)",
 		identityfile.DefaultFormat,
 		identityfile.FormatOpenSSH,
+		identityfile.FormatTLS,
 		identityfile.FormatKubernetes,
 	)).Default(string(identityfile.DefaultFormat)).StringVar((*string)(&cf.IdentityFormat))
 	login.Flag("request-roles", "Request one or more extra roles").StringVar(&cf.DesiredRoles)
@@ -301,6 +368,10 @@ func Run(args []string) {
 	// logout deletes obtained session certificates in ~/.tsh
 	logout := app.Command("logout", "Delete a cluster certificate")
 
+	// recover exchanges a one-time recovery code for a password reset link,
+	// for users locked out of their password or second factor
+	recoverCmd := app.Command("recover", "Recover account access using a one-time recovery code")
+
 	// bench
 	bench := app.Command("bench", "Run shell or execute a command on a remote SSH node").Hidden()
 	bench.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
@@ -311,6 +382,8 @@ func Run(args []string) {
 	bench.Flag("duration", "Test duration").Default("1s").DurationVar(&cf.BenchDuration)
 	bench.Flag("rate", "Requests per second rate").Default("10").IntVar(&cf.BenchRate)
 	bench.Flag("interactive", "Create interactive SSH session").BoolVar(&cf.BenchInteractive)
+	bench.Flag("export", "Export the latency profile").BoolVar(&cf.BenchExport)
+	bench.Flag("path", "Directory to save the latency profile to, default path is the current directory").Default(".").StringVar(&cf.BenchExportPath)
 
 	// show key
 	show := app.Command("show", "Read an identity from file and print to stdout").Hidden()
@@ -319,6 +392,32 @@ func Run(args []string) {
 	// The status command shows which proxy the user is logged into and metadata
 	// about the certificate.
 	status := app.Command("status", "Display the list of proxy servers and retrieved certificates")
+	status.Flag("verbose", "Show certificate details: fingerprint, validity window, critical options and traits").Short('v').BoolVar(&cf.Verbose)
+	status.Flag("format", "Format output (text, json, yaml)").Short('f').Default(teleport.Text).StringVar(&cf.Format)
+
+	// The proxy command is a family of commands opening authenticated tunnels
+	// to the cluster for tools that can't speak the Teleport handshake
+	// directly.
+	proxy := app.Command("proxy", "Run local proxies to Teleport services")
+	proxySSH := proxy.Command("ssh", "Open an authenticated tunnel to a remote SSH node, for use as an OpenSSH ProxyCommand")
+	proxySSH.Arg("[user@]host", "Remote hostname and the login to use").Required().StringVar(&cf.UserHost)
+	proxySSH.Flag("cluster", clusterHelp).Envar(clusterEnvVar).StringVar(&cf.SiteName)
+
+	// The config command dumps the OpenSSH config blocks needed to connect
+	// through the proxy with plain ssh/scp.
+	config := app.Command("config", "Print OpenSSH configuration details")
+
+	// The profile command manages the locally stored profiles, allowing a
+	// user logged into several clusters to switch between them without
+	// going through the login flow again.
+	profileCmd := app.Command("profile", "Manage locally stored cluster profiles")
+	profileLs := profileCmd.Command("ls", "List locally stored cluster profiles")
+	profileUse := profileCmd.Command("use", "Make the given profile the active one")
+	profileUse.Arg("proxy", "Proxy host of the profile to activate").Required().StringVar(&cf.ProfileName)
+
+	// The completion command prints shell completion scripts.
+	completion := app.Command("completion", "Print shell completion script for tsh")
+	completion.Arg("shell", "Shell to generate the script for (bash or zsh)").Required().StringVar(&cf.CompletionShell)
 
 	// On Windows, hide the "ssh", "join", "play", "scp", and "bench" commands
 	// because they all use a terminal.
@@ -373,6 +472,8 @@ func Run(args []string) {
 		onSCP(&cf)
 	case play.FullCommand():
 		onPlay(&cf)
+	case sessionsLs.FullCommand():
+		onListSessions(&cf)
 	case ls.FullCommand():
 		onListNodes(&cf)
 	case clusters.FullCommand():
@@ -382,10 +483,22 @@ func Run(args []string) {
 	case logout.FullCommand():
 		refuseArgs(logout.FullCommand(), args)
 		onLogout(&cf)
+	case recoverCmd.FullCommand():
+		onRecover(&cf)
 	case show.FullCommand():
 		onShow(&cf)
 	case status.FullCommand():
 		onStatus(&cf)
+	case config.FullCommand():
+		onConfig(&cf)
+	case proxySSH.FullCommand():
+		onProxyCommandSSH(&cf)
+	case completion.FullCommand():
+		onCompletion(&cf)
+	case profileLs.FullCommand():
+		onProfileLs(&cf)
+	case profileUse.FullCommand():
+		onProfileUse(&cf)
 	}
 }
 
@@ -395,9 +508,90 @@ func onPlay(cf *CLIConf) {
 	if err != nil {
 		utils.FatalError(err)
 	}
-	if err := tc.Play(context.TODO(), cf.Namespace, cf.SessionID); err != nil {
+	switch strings.ToLower(cf.Format) {
+	case teleport.Text:
+		speed, err := parsePlaybackSpeed(cf.PlaySpeed)
+		if err != nil {
+			utils.FatalError(err)
+		}
+		if err := tc.Play(context.TODO(), cf.Namespace, cf.SessionID, speed); err != nil {
+			utils.FatalError(err)
+		}
+	case teleport.JSON:
+		// instead of replaying in the terminal, dump the structured session
+		// events for consumption by external tools
+		sessionEvents, _, err := tc.GetSessionRecording(cf.Context, cf.Namespace, cf.SessionID)
+		if err != nil {
+			utils.FatalError(err)
+		}
+		out, err := json.MarshalIndent(sessionEvents, "", "  ")
+		if err != nil {
+			utils.FatalError(err)
+		}
+		fmt.Println(string(out))
+	default:
+		utils.FatalError(trace.BadParameter("unsupported format. try 'json' or 'text'"))
+	}
+}
+
+// parsePlaybackSpeed parses a playback speed multiplier like "2x" or "0.5x".
+func parsePlaybackSpeed(spec string) (float64, error) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(spec, "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, trace.BadParameter("invalid playback speed %q, expected e.g. 0.5x, 2x or 4x", spec)
+	}
+	return speed, nil
+}
+
+// onListSessions executes 'tsh sessions ls' command, listing active sessions
+// in the cluster along with their participants.
+func onListSessions(cf *CLIConf) {
+	tc, err := makeClient(cf, true)
+	if err != nil {
+		utils.FatalError(err)
+	}
+	var sessions []session.Session
+	err = client.RetryWithRelogin(cf.Context, tc, func() error {
+		sessions, err = tc.ListActiveSessions(cf.Context)
+		return err
+	})
+	if err != nil {
 		utils.FatalError(err)
 	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Created.Before(sessions[j].Created)
+	})
+
+	switch strings.ToLower(cf.Format) {
+	case teleport.Text:
+		t := asciitable.MakeTable([]string{"ID", "Node", "Login", "Participants", "Created"})
+		for _, s := range sessions {
+			participants := make([]string, 0, len(s.Parties))
+			for _, party := range s.Parties {
+				participants = append(participants, party.User)
+			}
+			t.AddRow([]string{
+				string(s.ID),
+				s.ServerHostname,
+				s.Login,
+				strings.Join(participants, ", "),
+				s.Created.Format(time.RFC3339),
+			})
+		}
+		fmt.Println(t.AsBuffer().String())
+	case teleport.JSON:
+		out, err := json.MarshalIndent(sessions, "", "  ")
+		if err != nil {
+			utils.FatalError(err)
+		}
+		fmt.Println(string(out))
+	case teleport.YAML:
+		if err := utils.WriteYAML(os.Stdout, sessions); err != nil {
+			utils.FatalError(err)
+		}
+	default:
+		utils.FatalError(trace.BadParameter("unsupported format. try 'json', 'yaml' or 'text'"))
+	}
 }
 
 // onLogin logs in with remote proxy and gets signed certificates
@@ -420,7 +614,7 @@ func onLogin(cf *CLIConf) {
 	}
 
 	switch cf.IdentityFormat {
-	case identityfile.FormatFile, identityfile.FormatOpenSSH, identityfile.FormatKubernetes:
+	case identityfile.FormatFile, identityfile.FormatOpenSSH, identityfile.FormatTLS, identityfile.FormatKubernetes:
 	default:
 		utils.FatalError(trace.BadParameter("invalid identity format: %s", cf.IdentityFormat))
 	}
@@ -445,11 +639,11 @@ func onLogin(cf *CLIConf) {
 		switch {
 		// in case if nothing is specified, print current status
 		case cf.Proxy == "" && cf.SiteName == "" && cf.DesiredRoles == "" && cf.IdentityFileOut == "":
-			printProfiles(cf.Debug, profile, profiles)
+			printProfiles(cf.Debug, cf.Verbose, profile, profiles)
 			return
 		// in case if parameters match, print current status
 		case host(cf.Proxy) == host(profile.ProxyURL.Host) && cf.SiteName == profile.Cluster && cf.DesiredRoles == "":
-			printProfiles(cf.Debug, profile, profiles)
+			printProfiles(cf.Debug, cf.Verbose, profile, profiles)
 			return
 		// proxy is unspecified or the same as the currently provided proxy,
 		// but cluster is specified, treat this as selecting a new cluster
@@ -714,6 +908,33 @@ func onLogout(cf *CLIConf) {
 	}
 }
 
+// onRecover executes 'tsh recover' command. It exchanges a one-time
+// recovery code for a password reset link which the user opens in a
+// browser to set a new password and second factor.
+func onRecover(cf *CLIConf) {
+	tc, err := makeClient(cf, true)
+	if err != nil {
+		utils.FatalError(err)
+	}
+	if tc.Config.Username == "" {
+		utils.FatalError(trace.BadParameter("please specify the user to recover with --user"))
+	}
+
+	code, err := tc.AskRecoveryCode()
+	if err != nil {
+		utils.FatalError(err)
+	}
+
+	response, err := tc.StartAccountRecovery(cf.Context, tc.Config.Username, code)
+	if err != nil {
+		utils.FatalError(err)
+	}
+
+	ttl := response.Expiry.Sub(time.Now().UTC()).Round(time.Second)
+	fmt.Printf("Open the following URL to set a new password and second factor, link is valid for %v:\nhttps://%v/web/reset/%v\n",
+		ttl, tc.WebProxyAddr, response.TokenID)
+}
+
 // onListNodes executes 'tsh ls' command.
 func onListNodes(cf *CLIConf) {
 	tc, err := makeClient(cf, true)
@@ -721,6 +942,11 @@ func onListNodes(cf *CLIConf) {
 		utils.FatalError(err)
 	}
 
+	if cf.ListAll {
+		onListResources(cf, tc)
+		return
+	}
+
 	// Get list of all nodes in backend and sort by "Node Name".
 	var nodes []services.Server
 	err = client.RetryWithRelogin(cf.Context, tc, func() error {
@@ -740,6 +966,52 @@ func onListNodes(cf *CLIConf) {
 
 }
 
+// onListResources executes 'tsh ls --all', listing every resource kind the
+// user can access in the current cluster in one table.
+func onListResources(cf *CLIConf, tc *client.TeleportClient) {
+	var resources []services.UnifiedResource
+	err := client.RetryWithRelogin(cf.Context, tc, func() error {
+		var err error
+		resources, err = tc.ListUnifiedResources(cf.Context)
+		return err
+	})
+	if err != nil {
+		utils.FatalError(err)
+	}
+
+	switch strings.ToLower(cf.Format) {
+	case teleport.Text:
+		t := asciitable.MakeTable([]string{"Kind", "Name", "Hostname", "Address", "Labels", "Health"})
+		for _, r := range resources {
+			labels := make([]string, 0, len(r.Labels))
+			for key, value := range r.Labels {
+				labels = append(labels, fmt.Sprintf("%v=%v", key, value))
+			}
+			sort.Strings(labels)
+			t.AddRow([]string{
+				r.Kind, r.Name, r.Hostname, r.Addr, strings.Join(labels, ","), r.Health,
+			})
+		}
+		fmt.Println(t.AsBuffer().String())
+	case teleport.JSON:
+		out, err := json.MarshalIndent(resources, "", "  ")
+		if err != nil {
+			utils.FatalError(err)
+		}
+		fmt.Println(string(out))
+	case teleport.YAML:
+		if err := utils.WriteYAML(os.Stdout, resources); err != nil {
+			utils.FatalError(err)
+		}
+	case teleport.Names:
+		for _, r := range resources {
+			fmt.Println(r.Name)
+		}
+	default:
+		utils.FatalError(trace.BadParameter("unsupported format. try 'json', 'yaml', 'text', or 'names'"))
+	}
+}
+
 func executeAccessRequest(cf *CLIConf) {
 	if cf.DesiredRoles == "" {
 		utils.FatalError(trace.BadParameter("one or more roles must be specified"))
@@ -777,12 +1049,16 @@ func printNodes(nodes []services.Server, format string, verbose bool) error {
 			return trace.Wrap(err)
 		}
 		fmt.Println(string(out))
+	case teleport.YAML:
+		if err := utils.WriteYAML(os.Stdout, nodes); err != nil {
+			return trace.Wrap(err)
+		}
 	case teleport.Names:
 		for _, n := range nodes {
 			fmt.Println(n.GetHostname())
 		}
 	default:
-		return trace.BadParameter("unsupported format. try 'json', 'text', or 'names'")
+		return trace.BadParameter("unsupported format. try 'json', 'yaml', 'text', or 'names'")
 	}
 
 	return nil
@@ -868,28 +1144,80 @@ func onListClusters(cf *CLIConf) {
 	if err != nil {
 		utils.FatalError(err)
 	}
-	var t asciitable.Table
-	if cf.Quiet {
-		t = asciitable.MakeHeadlessTable(2)
-	} else {
-		t = asciitable.MakeTable([]string{"Cluster Name", "Status"})
+	if err := printClusters(sites, cf.Format, cf.Quiet); err != nil {
+		utils.FatalError(err)
+	}
+}
+
+func printClusters(sites []services.Site, format string, quiet bool) error {
+	switch strings.ToLower(format) {
+	case teleport.Text:
+		var t asciitable.Table
+		if quiet {
+			t = asciitable.MakeHeadlessTable(2)
+		} else {
+			t = asciitable.MakeTable([]string{"Cluster Name", "Status"})
+		}
+		if len(sites) == 0 {
+			return nil
+		}
+		for _, site := range sites {
+			t.AddRow([]string{site.Name, site.Status})
+		}
+		fmt.Println(t.AsBuffer().String())
+	case teleport.JSON:
+		out, err := json.MarshalIndent(sites, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(out))
+	case teleport.YAML:
+		if err := utils.WriteYAML(os.Stdout, sites); err != nil {
+			return trace.Wrap(err)
+		}
+	case teleport.Names:
+		for _, site := range sites {
+			fmt.Println(site.Name)
+		}
+	default:
+		return trace.BadParameter("unsupported format. try 'json', 'yaml', 'text', or 'names'")
 	}
-	if len(sites) == 0 {
+	return nil
+}
+
+// onSSH executes 'tsh ssh' command
+// autoRenewThreshold is how close to expiry credentials have to be before
+// --auto-renew triggers a fresh login.
+const autoRenewThreshold = 10 * time.Minute
+
+// maybeAutoRenew re-runs the login sequence before a long-running command
+// starts when --auto-renew is set and the current certificate is close to
+// expiry, so the session does not get interrupted mid-command.
+func maybeAutoRenew(cf *CLIConf, tc *client.TeleportClient) {
+	if !cf.AutoRenew {
 		return
 	}
-	for _, site := range sites {
-		t.AddRow([]string{site.Name, site.Status})
+	if err := tc.ReloginIfExpiring(cf.Context, autoRenewThreshold); err != nil {
+		utils.FatalError(err)
 	}
-	fmt.Println(t.AsBuffer().String())
 }
 
-// onSSH executes 'tsh ssh' command
 func onSSH(cf *CLIConf) {
+	// when no host is given, offer an interactive picker over the nodes the
+	// user can see, as long as we are attached to a terminal
+	if cf.UserHost == "" {
+		if err := pickNodeInteractively(cf); err != nil {
+			utils.FatalError(err)
+		}
+	}
+
 	tc, err := makeClient(cf, false)
 	if err != nil {
 		utils.FatalError(err)
 	}
 
+	maybeAutoRenew(cf, tc)
+
 	tc.Stdin = os.Stdin
 	err = client.RetryWithRelogin(cf.Context, tc, func() error {
 		return tc.SSH(cf.Context, cf.RemoteCommand, cf.LocalExec)
@@ -923,6 +1251,104 @@ func onSSH(cf *CLIConf) {
 	}
 }
 
+// onProfileLs executes 'tsh profile ls', listing the locally stored cluster
+// profiles and marking the active one.
+func onProfileLs(cf *CLIConf) {
+	profileDir := client.FullProfilePath("")
+	names, err := client.ListProfileNames(profileDir)
+	if err != nil {
+		if trace.IsNotFound(err) || os.IsNotExist(trace.Unwrap(err)) {
+			fmt.Printf("No profiles found, log in with 'tsh login' first.\n")
+			return
+		}
+		utils.FatalError(err)
+	}
+	currentName, err := client.GetCurrentProfileName(profileDir)
+	if err != nil && !trace.IsNotFound(err) {
+		utils.FatalError(err)
+	}
+	sort.Strings(names)
+
+	t := asciitable.MakeTable([]string{"Proxy", "User", "Cluster"})
+	for _, name := range names {
+		profile, err := client.ProfileFromDir(profileDir, name)
+		if err != nil {
+			log.Debugf("Failed to read profile %q: %v.", name, err)
+			continue
+		}
+		prefix := "  "
+		if name == currentName {
+			prefix = "> "
+		}
+		t.AddRow([]string{prefix + name, profile.Username, profile.SiteName})
+	}
+	fmt.Println(t.AsBuffer().String())
+}
+
+// onProfileUse executes 'tsh profile use', switching the active profile to
+// the given one without going through login.
+func onProfileUse(cf *CLIConf) {
+	profileDir := client.FullProfilePath("")
+	profileName := host(cf.ProfileName)
+	// make sure the requested profile actually exists before switching
+	if _, err := client.ProfileFromDir(profileDir, profileName); err != nil {
+		if trace.IsNotFound(err) || os.IsNotExist(trace.Unwrap(err)) {
+			utils.FatalError(trace.NotFound("profile %q not found, log in with 'tsh login --proxy=%v' first", profileName, profileName))
+		}
+		utils.FatalError(err)
+	}
+	if err := client.SetCurrentProfileName(profileDir, profileName); err != nil {
+		utils.FatalError(err)
+	}
+	fmt.Printf("Switched to profile %v.\n", profileName)
+}
+
+// pickNodeInteractively lists the nodes the user can see and prompts for
+// one of them, storing the selection in cf.UserHost.
+func pickNodeInteractively(cf *CLIConf) error {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return trace.BadParameter("no host specified, see 'tsh ssh --help'")
+	}
+	tc, err := makeClient(cf, true)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	var nodes []services.Server
+	err = client.RetryWithRelogin(cf.Context, tc, func() error {
+		nodes, err = tc.ListNodes(cf.Context)
+		return err
+	})
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(nodes) == 0 {
+		return trace.NotFound("no nodes are available")
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].GetHostname() < nodes[j].GetHostname()
+	})
+
+	t := asciitable.MakeTable([]string{"#", "Node Name", "Address", "Labels"})
+	for i, n := range nodes {
+		t.AddRow([]string{
+			strconv.Itoa(i + 1), n.GetHostname(), n.GetAddr(), n.LabelsString(),
+		})
+	}
+	fmt.Println(t.AsBuffer().String())
+	fmt.Printf("Select a host [1-%v]: ", len(nodes))
+
+	var answer string
+	if _, err := fmt.Fscanln(os.Stdin, &answer); err != nil {
+		return trace.Wrap(err)
+	}
+	index, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || index < 1 || index > len(nodes) {
+		return trace.BadParameter("invalid selection: %v", answer)
+	}
+	cf.UserHost = nodes[index-1].GetHostname()
+	return nil
+}
+
 // onBenchmark executes benchmark
 func onBenchmark(cf *CLIConf) {
 	tc, err := makeClient(cf, false)
@@ -942,6 +1368,7 @@ func onBenchmark(cf *CLIConf) {
 	fmt.Printf("\n")
 	fmt.Printf("* Requests originated: %v\n", result.RequestsOriginated)
 	fmt.Printf("* Requests failed: %v\n", result.RequestsFailed)
+	fmt.Printf("* Requests/second: %.1f\n", float64(result.RequestsOriginated)/cf.BenchDuration.Seconds())
 	if result.LastError != nil {
 		fmt.Printf("* Last error: %v\n", result.LastError)
 	}
@@ -956,6 +1383,14 @@ func onBenchmark(cf *CLIConf) {
 		utils.FatalError(err)
 	}
 	fmt.Printf("\n")
+	if cf.BenchExport {
+		path, err := client.ExportLatencyProfile(cf.BenchExportPath, result.Histogram)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to export latency profile: %v\n", utils.UserMessageFromError(err))
+		} else {
+			fmt.Printf("latency profile saved: %v\n", path)
+		}
+	}
 }
 
 // onJoin executes 'ssh join' command
@@ -968,8 +1403,15 @@ func onJoin(cf *CLIConf) {
 	if err != nil {
 		utils.FatalError(fmt.Errorf("'%v' is not a valid session ID (must be GUID)", cf.SessionID))
 	}
+	// In observer mode substitute stdin with a reader that never produces
+	// any input, the session output is still streamed to the terminal.
+	var input io.Reader
+	if cf.JoinMode == teleport.SSHSessionJoinModeObserver {
+		input, _ = io.Pipe()
+	}
+	maybeAutoRenew(cf, tc)
 	err = client.RetryWithRelogin(cf.Context, tc, func() error {
-		return tc.Join(context.TODO(), cf.Namespace, *sid, nil)
+		return tc.Join(context.TODO(), cf.Namespace, *sid, input)
 	})
 	if err != nil {
 		utils.FatalError(err)
@@ -982,8 +1424,13 @@ func onSCP(cf *CLIConf) {
 	if err != nil {
 		utils.FatalError(err)
 	}
+	maybeAutoRenew(cf, tc)
 	err = client.RetryWithRelogin(cf.Context, tc, func() error {
-		return tc.SCP(context.TODO(), cf.CopySpec, int(cf.NodePort), cf.RecursiveCopy, cf.Quiet)
+		return tc.SCP(context.TODO(), cf.CopySpec, int(cf.NodePort), scp.Flags{
+			Recursive:     cf.RecursiveCopy,
+			PreserveAttrs: cf.PreserveAttrs,
+			LimitKbits:    cf.BandwidthLimit,
+		}, cf.Quiet)
 	})
 	if err != nil {
 		// exit with the same exit status as the failed command:
@@ -1157,6 +1604,11 @@ func makeClient(cf *CLIConf, useProfileLogin bool) (*client.TeleportClient, erro
 	c.Host = cf.UserHost
 	c.HostPort = int(cf.NodePort)
 	c.Labels = labels
+	c.PredicateExpression = cf.PredicateExpression
+	c.CommandConcurrency = cf.ParallelJobs
+	if cf.SearchKeywords != "" {
+		c.SearchKeywords = client.ParseSearchKeywords(cf.SearchKeywords, ',')
+	}
 	c.KeyTTL = time.Minute * time.Duration(cf.MinsToLive)
 	c.InsecureSkipVerify = cf.InsecureSkipVerify
 
@@ -1210,6 +1662,10 @@ func makeClient(cf *CLIConf, useProfileLogin bool) (*client.TeleportClient, erro
 	// certificates (https://dev.gnupg.org/T1756)
 	c.UseLocalSSHAgent = cf.UseLocalSSHAgent
 
+	// Keep keys and certificates in memory if requested, reusing them until
+	// they expire.
+	c.UseMemoryKeyStore = cf.MemKeystore
+
 	c.EnableEscapeSequences = cf.EnableEscapeSequences
 
 	tc, err := client.NewClient(c)
@@ -1300,7 +1756,7 @@ func onShow(cf *CLIConf) {
 }
 
 // printStatus prints the status of the profile.
-func printStatus(debug bool, p *client.ProfileStatus, isActive bool) {
+func printStatus(debug bool, verbose bool, p *client.ProfileStatus, isActive bool) {
 	var count int
 	var prefix string
 	if isActive {
@@ -1320,7 +1776,7 @@ func printStatus(debug bool, p *client.ProfileStatus, isActive bool) {
 		fmt.Printf("  Cluster:      %v\n", p.Cluster)
 	}
 	fmt.Printf("  Roles:        %v*\n", strings.Join(p.Roles, ", "))
-	if debug {
+	if debug || verbose {
 		for k, v := range p.Traits {
 			if count == 0 {
 				fmt.Printf("  Traits:       %v: %v\n", k, v)
@@ -1334,6 +1790,26 @@ func printStatus(debug bool, p *client.ProfileStatus, isActive bool) {
 	fmt.Printf("  Valid until:  %v [%v]\n", p.ValidUntil, humanDuration)
 	fmt.Printf("  Extensions:   %v\n", strings.Join(p.Extensions, ", "))
 
+	// In verbose mode decode the rest of the certificate, this replaces
+	// inspecting the certificate manually with "ssh-keygen -L".
+	if verbose {
+		if p.Fingerprint != "" {
+			fmt.Printf("  Fingerprint:  %v\n", p.Fingerprint)
+		}
+		if !p.ValidAfter.IsZero() {
+			fmt.Printf("  Valid after:  %v\n", p.ValidAfter)
+		}
+		for option, value := range p.CriticalOptions {
+			fmt.Printf("  Critical opt: %v=%v\n", option, value)
+		}
+		if !p.ActiveRequests.IsEmpty() {
+			fmt.Printf("  Requests:     %v\n", strings.Join(p.ActiveRequests.AccessRequests, ", "))
+		}
+		if !p.TLSValidUntil.IsZero() {
+			fmt.Printf("  TLS valid until: %v\n", p.TLSValidUntil)
+		}
+	}
+
 	fmt.Printf("\n")
 }
 
@@ -1350,18 +1826,91 @@ func onStatus(cf *CLIConf) {
 		}
 		utils.FatalError(err)
 	}
-	printProfiles(cf.Debug, profile, profiles)
+	switch strings.ToLower(cf.Format) {
+	case teleport.JSON, teleport.YAML:
+		if err := printProfilesWithFormat(profile, profiles, cf.Format); err != nil {
+			utils.FatalError(err)
+		}
+	default:
+		printProfiles(cf.Debug, cf.Verbose, profile, profiles)
+	}
+}
+
+// profileInfo is the JSON/YAML serializable form of a profile printed by
+// "tsh status --format=json". The schema is part of the interface scripts
+// rely on, treat field renames as breaking changes.
+type profileInfo struct {
+	ProxyURL        string              `json:"profile_url"`
+	Username        string              `json:"username"`
+	Cluster         string              `json:"cluster,omitempty"`
+	Roles           []string            `json:"roles,omitempty"`
+	Traits          map[string][]string `json:"traits,omitempty"`
+	Logins          []string            `json:"logins,omitempty"`
+	ValidUntil      time.Time           `json:"valid_until"`
+	Extensions      []string            `json:"extensions,omitempty"`
+	Active          bool                `json:"active"`
+	Fingerprint     string              `json:"fingerprint,omitempty"`
+	ValidAfter      time.Time           `json:"valid_after,omitempty"`
+	CriticalOptions map[string]string   `json:"critical_options,omitempty"`
+	ActiveRequests  []string            `json:"active_requests,omitempty"`
+	TLSValidUntil   time.Time           `json:"tls_valid_until,omitempty"`
+}
+
+func makeProfileInfo(p *client.ProfileStatus, isActive bool) profileInfo {
+	return profileInfo{
+		ProxyURL:        p.ProxyURL.String(),
+		Username:        p.Username,
+		Cluster:         p.Cluster,
+		Roles:           p.Roles,
+		Traits:          p.Traits,
+		Logins:          p.Logins,
+		ValidUntil:      p.ValidUntil,
+		Extensions:      p.Extensions,
+		Active:          isActive,
+		Fingerprint:     p.Fingerprint,
+		ValidAfter:      p.ValidAfter,
+		CriticalOptions: p.CriticalOptions,
+		ActiveRequests:  p.ActiveRequests.AccessRequests,
+		TLSValidUntil:   p.TLSValidUntil,
+	}
+}
+
+// printProfilesWithFormat serializes the active and available profiles as
+// JSON or YAML.
+func printProfilesWithFormat(profile *client.ProfileStatus, profiles []*client.ProfileStatus, format string) error {
+	var infos []profileInfo
+	if profile != nil {
+		infos = append(infos, makeProfileInfo(profile, true))
+	}
+	for _, p := range profiles {
+		infos = append(infos, makeProfileInfo(p, false))
+	}
+	switch strings.ToLower(format) {
+	case teleport.JSON:
+		out, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		fmt.Println(string(out))
+	case teleport.YAML:
+		if err := utils.WriteYAML(os.Stdout, infos); err != nil {
+			return trace.Wrap(err)
+		}
+	default:
+		return trace.BadParameter("unsupported format. try 'json' or 'yaml'")
+	}
+	return nil
 }
 
-func printProfiles(debug bool, profile *client.ProfileStatus, profiles []*client.ProfileStatus) {
+func printProfiles(debug bool, verbose bool, profile *client.ProfileStatus, profiles []*client.ProfileStatus) {
 	// Print the active profile.
 	if profile != nil {
-		printStatus(debug, profile, true)
+		printStatus(debug, verbose, profile, true)
 	}
 
 	// Print all other profiles.
 	for _, p := range profiles {
-		printStatus(debug, p, false)
+		printStatus(debug, verbose, p, false)
 	}
 
 	// If we are printing profile, add a note that even though roles are listed